@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,11 +17,13 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/api"
+	"github.com/cragr/openshift-baremetal-insights/internal/baseline"
 	"github.com/cragr/openshift-baremetal-insights/internal/catalog"
 	"github.com/cragr/openshift-baremetal-insights/internal/discovery"
 	"github.com/cragr/openshift-baremetal-insights/internal/poller"
 	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
 	"github.com/cragr/openshift-baremetal-insights/internal/store"
+	"github.com/cragr/openshift-baremetal-insights/internal/updates"
 )
 
 func main() {
@@ -30,8 +34,28 @@ func main() {
 	namespace := getEnv("WATCH_NAMESPACE", "openshift-machine-api")
 	watchAllNamespaces := getEnvBool("WATCH_ALL_NAMESPACES", true) // Default to true for ACM hub clusters
 	pollInterval := getEnvDuration("POLL_INTERVAL", 30*time.Minute)
+	refreshInterval := getEnvDuration("REFRESH_MIN_INTERVAL", poller.DefaultRefreshInterval)
+	eventRetention := getEnvInt("EVENT_RETENTION", 1000)
+	eventNodeRetention := getEnvInt("EVENT_NODE_RETENTION", store.DefaultNodeQuota)
 	catalogURL := getEnv("CATALOG_URL", "https://downloads.dell.com/catalog/Catalog.xml.gz")
+	catalogCAFile := getEnv("CATALOG_CA_FILE", "")
+	catalogFile := getEnv("CATALOG_FILE", "")
+	catalogConfigMap := getEnv("CATALOG_CONFIGMAP", "")
+	catalogSecret := getEnv("CATALOG_SECRET", "")
+	catalogObjectKey := getEnv("CATALOG_OBJECT_KEY", "Catalog.xml.gz")
+	catalogPublicKeyFile := getEnv("CATALOG_PUBLIC_KEY_FILE", "")
+	catalogSHA256 := getEnv("CATALOG_SHA256", "")
+	catalogDownloadBaseURL := getEnv("CATALOG_DOWNLOAD_BASE_URL", "")
+	catalogRequireVerification := getEnvBool("CATALOG_REQUIRE_VERIFICATION", false)
+	podNamespace := getEnv("POD_NAMESPACE", namespace)
 	catalogTTL := getEnvDuration("CATALOG_TTL", 24*time.Hour)
+	catalogCacheDir := getEnv("CATALOG_CACHE_DIR", "")
+	baselineConfigMap := getEnv("BASELINE_CONFIGMAP", "")
+	baselineStateDir := getEnv("BASELINE_STATE_DIR", "")
+	bmcCAConfigMap := getEnv("BMC_CA_CONFIGMAP", "")
+	bmcNamespaceCAConfigMap := getEnv("BMC_NAMESPACE_CA_CONFIGMAP", "")
+	taskPollInterval := getEnvDuration("TASK_POLL_INTERVAL", time.Minute)
+	taskRetention := getEnvDuration("TASK_RETENTION", 24*time.Hour)
 	tlsCertFile := getEnv("TLS_CERT_FILE", "")
 	tlsKeyFile := getEnv("TLS_KEY_FILE", "")
 
@@ -53,17 +77,88 @@ func main() {
 
 	// Create components
 	dataStore := store.New()
-	eventStore := store.NewEventStore(1000)
+	eventStore := store.NewEventStore(eventRetention)
+	eventStore.SetNodeQuota(eventNodeRetention)
+	changeStore := store.NewChangeStore(1000)
 	taskStore := store.NewTaskStore()
 	redfishClient := redfish.NewClient()
 	discoverer := discovery.NewDiscoverer(dynamicClient, kubeClient, namespace, watchAllNamespaces)
-	catalogSvc := catalog.NewService(catalogURL, catalogTTL)
+	// BMC certificates are verified against the system roots plus these CA bundles,
+	// unless a BareMetalHost sets spec.bmc.disableCertificateVerification
+	if bmcCAConfigMap != "" {
+		ns, name := splitNamespacedName(bmcCAConfigMap, podNamespace)
+		discoverer.SetCABundle(ns, name)
+	}
+	discoverer.SetNamespaceCABundle(bmcNamespaceCAConfigMap)
+
+	// Catalog sources in priority order: mounted file, ConfigMap, Secret, then URL
+	var catalogSources []catalog.Source
+	if catalogFile != "" {
+		catalogSources = append(catalogSources, catalog.NewFileSource(catalogFile))
+	}
+	if catalogConfigMap != "" {
+		ns, name := splitNamespacedName(catalogConfigMap, podNamespace)
+		catalogSources = append(catalogSources, catalog.NewConfigMapSource(kubeClient, ns, name, catalogObjectKey))
+	}
+	if catalogSecret != "" {
+		ns, name := splitNamespacedName(catalogSecret, podNamespace)
+		catalogSources = append(catalogSources, catalog.NewSecretSource(kubeClient, ns, name, catalogObjectKey))
+	}
+	httpSource, err := catalog.NewHTTPSource(catalogURL, catalogCAFile)
+	if err != nil {
+		log.Fatalf("Failed to configure catalog URL source: %v", err)
+	}
+	catalogSources = append(catalogSources, httpSource)
+
+	catalogVerifier, err := catalog.NewVerifier(catalogPublicKeyFile, catalogSHA256, catalogRequireVerification)
+	if err != nil {
+		log.Fatalf("Failed to configure catalog verification: %v", err)
+	}
+	catalogSvc := catalog.NewServiceWithSources(catalogSources, catalogVerifier, catalogTTL)
+	catalogSvc.SetCacheDir(catalogCacheDir)
+	// Point firmware download URLs at a repository mirror, e.g. for air-gapped sites
+	catalogSvc.SetDownloadBaseURL(catalogDownloadBaseURL)
+	// Only keep catalog entries for the server models actually in the inventory
+	catalogSvc.SetInventory(dataStore.ListNodes)
+	// Serve the last persisted catalog until the first sync completes
+	if err := catalogSvc.Load(); err != nil {
+		log.Printf("Failed to load persisted catalog: %v", err)
+	}
+
+	baselines := baseline.NewManager()
+	if baselineConfigMap != "" {
+		ns, name := splitNamespacedName(baselineConfigMap, podNamespace)
+		baselines.SetConfigMap(kubeClient, ns, name)
+	}
+	baselines.SetStateDir(baselineStateDir)
+	if err := baselines.Load(); err != nil {
+		log.Printf("Failed to load persisted baselines: %v", err)
+	}
+
 	poll := poller.New(discoverer, redfishClient, dataStore, eventStore, catalogSvc, pollInterval)
+	poll.SetBaselines(baselines)
+	poll.SetRefreshInterval(refreshInterval)
+	poll.SetChangeStore(changeStore)
+	scheduler := updates.NewScheduler(dataStore, taskStore, catalogSvc, redfishClient, discoverer)
+	taskMonitor := updates.NewMonitor(taskStore, redfishClient, discoverer, taskPollInterval, taskRetention)
 	server := api.NewServerWithTasks(dataStore, eventStore, taskStore, addr, tlsCertFile, tlsKeyFile)
+	server.SetUpdateScheduler(scheduler)
+	server.SetCatalog(catalogSvc)
+	server.SetBaselines(baselines)
+	server.SetPoller(poll)
+	server.SetChangeStore(changeStore)
 
-	// Start poller in background
 	ctx, cancel := context.WithCancel(context.Background())
+
+	// Watch BareMetalHosts so new, changed and deleted hosts are handled without
+	// waiting for the next poll
+	if err := discoverer.Watch(ctx, poll); err != nil {
+		log.Printf("Failed to watch BareMetalHosts, relying on periodic discovery: %v", err)
+	}
+
+	// Start poller in background
 	go poll.Start(ctx)
+	go taskMonitor.Start(ctx)
 
 	// Start API server in background
 	serverErr := make(chan error, 1)
@@ -93,6 +188,7 @@ func main() {
 	log.Println("Shutting down...")
 	cancel()
 	poll.Stop()
+	taskMonitor.Stop()
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
@@ -117,6 +213,14 @@ func getKubeConfig() (*rest.Config, error) {
 	return clientcmd.BuildConfigFromFlags("", kubeconfig)
 }
 
+// splitNamespacedName splits "namespace/name", using defaultNamespace when no namespace is given
+func splitNamespacedName(value, defaultNamespace string) (string, string) {
+	if ns, name, ok := strings.Cut(value, "/"); ok {
+		return ns, name
+	}
+	return defaultNamespace, value
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -134,6 +238,16 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		switch value {
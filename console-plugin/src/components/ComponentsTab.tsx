@@ -49,12 +49,12 @@ export const ComponentsTab: React.FC<ComponentsTabProps> = ({
   const allSelected =
     selectableComponents.length > 0 &&
     selectableComponents.every((entry) =>
-      selectedComponents.includes(`${entry.node}:${entry.firmware.id}`)
+      selectedComponents.includes(`${entry.namespace}/${entry.node}:${entry.firmware.id}`)
     );
 
   const handleSelectAll = () => {
     const visibleComponentKeys = selectableComponents.map(
-      (entry) => `${entry.node}:${entry.firmware.id}`
+      (entry) => `${entry.namespace}/${entry.node}:${entry.firmware.id}`
     );
     if (allSelected) {
       onSelectionChange(
@@ -118,7 +118,7 @@ export const ComponentsTab: React.FC<ComponentsTabProps> = ({
           </Tr>
         ) : (
           filteredFirmware.map((entry, index) => {
-            const key = `${entry.node}:${entry.firmware.id}`;
+            const key = `${entry.namespace}/${entry.node}:${entry.firmware.id}`;
             const hasUpdate = !!entry.firmware.availableVersion;
             return (
               <Tr
@@ -138,7 +138,7 @@ export const ComponentsTab: React.FC<ComponentsTabProps> = ({
                   }}
                 />
                 <Td dataLabel="Node Name">
-                  <Link to={`/baremetal-insights/nodes/${entry.node}`}>
+                  <Link to={`/baremetal-insights/nodes/${entry.namespace}/${entry.node}`}>
                     {entry.node}
                   </Link>
                 </Td>
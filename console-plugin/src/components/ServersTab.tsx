@@ -4,6 +4,9 @@ import { Label } from '@patternfly/react-core';
 import { Table, Thead, Tr, Th, Tbody, Td } from '@patternfly/react-table';
 import { Node, Severity } from '../types';
 
+// Nodes are selected by namespace/name, which the schedule API accepts
+export const nodeKey = (node: Node): string => `${node.namespace}/${node.name}`;
+
 interface ServersTabProps {
   nodes: Node[];
   searchValue: string;
@@ -43,10 +46,10 @@ export const ServersTab: React.FC<ServersTabProps> = ({
   const selectableNodes = filteredNodes.filter((n) => n.updatesAvailable > 0);
   const allSelected =
     selectableNodes.length > 0 &&
-    selectableNodes.every((n) => selectedNodes.includes(n.name));
+    selectableNodes.every((n) => selectedNodes.includes(nodeKey(n)));
 
   const handleSelectAll = () => {
-    const visibleNodeNames = selectableNodes.map((n) => n.name);
+    const visibleNodeNames = selectableNodes.map(nodeKey);
     if (allSelected) {
       // Only remove visible nodes from selection
       onSelectionChange(selectedNodes.filter(name => !visibleNodeNames.includes(name)));
@@ -120,7 +123,7 @@ export const ServersTab: React.FC<ServersTabProps> = ({
             const severity = getHighestSeverity(node);
             return (
               <Tr
-                key={node.name}
+                key={nodeKey(node)}
                 style={{
                   backgroundColor: hasUpdates
                     ? 'var(--pf-v5-global--warning-color--200)'
@@ -130,8 +133,8 @@ export const ServersTab: React.FC<ServersTabProps> = ({
                 <Td
                   select={{
                     rowIndex: rowIndex,
-                    onSelect: () => handleSelectNode(node.name),
-                    isSelected: selectedNodes.includes(node.name),
+                    onSelect: () => handleSelectNode(nodeKey(node)),
+                    isSelected: selectedNodes.includes(nodeKey(node)),
                     isDisabled: !hasUpdates,
                   }}
                 />
@@ -17,7 +17,8 @@ const mockDashboardData = {
   healthSummary: { healthy: 18, warning: 4, critical: 2 },
   powerSummary: { on: 22, off: 2 },
   updatesSummary: { total: 12, critical: 3, recommended: 5, optional: 4, nodesWithUpdates: 5 },
-  jobsSummary: { pending: 0, inProgress: 0, completed: 0 },
+  complianceSummary: { compliant: 6, behind: 2, ahead: 0, unknown: 0, noBaseline: 16 },
+  jobsSummary: { pending: 0, inProgress: 0, completed: 0, failed: 0 },
   lastRefresh: new Date().toISOString(),
   nextRefresh: new Date(Date.now() + 60000).toISOString(),
 };
@@ -70,6 +71,17 @@ describe('Dashboard', () => {
     });
   });
 
+  it('displays baseline compliance', async () => {
+    render(
+      <MemoryRouter>
+        <Dashboard />
+      </MemoryRouter>
+    );
+    await waitFor(() => {
+      expect(screen.getByText('6 of 8 compliant with baseline')).toBeInTheDocument();
+    });
+  });
+
   it('does not render refresh countdown', async () => {
     render(
       <MemoryRouter>
@@ -118,6 +118,10 @@ export const Dashboard: React.FC = () => {
       ]
     : [];
 
+  const baselinedNodes = stats?.complianceSummary
+    ? stats.totalNodes - stats.complianceSummary.noBaseline
+    : 0;
+
   const powerData = stats
     ? [
         { x: 'On', y: stats.powerSummary.on },
@@ -176,6 +180,11 @@ export const Dashboard: React.FC = () => {
                       {stats.updatesSummary.critical} critical updates
                     </span>
                   )}
+                  {stats.complianceSummary && baselinedNodes > 0 && (
+                    <span className="dashboard-card__subtitle">
+                      {stats.complianceSummary.compliant} of {baselinedNodes} compliant with baseline
+                    </span>
+                  )}
                 </CardBody>
               </Card>
             </GridItem>
@@ -30,7 +30,7 @@ import {
 import { FirmwareResponse, Node } from '../types';
 import { getFirmware, getNodes, scheduleUpdates } from '../services/api';
 import { NamespaceDropdown } from '../components/NamespaceDropdown';
-import { ServersTab } from '../components/ServersTab';
+import { ServersTab, nodeKey } from '../components/ServersTab';
 import { ComponentsTab } from '../components/ComponentsTab';
 import { FirmwareDetailDrawer } from '../components/FirmwareDetailDrawer';
 import { ScheduleUpdateModal } from '../components/ScheduleUpdateModal';
@@ -94,7 +94,7 @@ export const Firmware: React.FC = () => {
   const getUpdateCount = () => {
     if (activeTab === 'servers') {
       return nodes
-        .filter((n) => selectedNodes.includes(n.name))
+        .filter((n) => selectedNodes.includes(nodeKey(n)))
         .reduce((sum, n) => sum + n.updatesAvailable, 0);
     } else {
       return selectedComponents.length;
@@ -138,7 +138,7 @@ export const Firmware: React.FC = () => {
         ...prev,
         {
           key: alertKey,
-          title: `Successfully scheduled ${getUpdateCount()} updates on ${nodeNames.length} servers`,
+          title: `Submitting ${getUpdateCount()} updates to ${nodeNames.length} servers; each update's task shows when its BMC accepts it`,
           variant: AlertVariant.success,
         },
       ]);
@@ -20,17 +20,28 @@ import {
   GridItem,
 } from '@patternfly/react-core';
 import { Table, Thead, Tbody, Tr, Th, Td } from '@patternfly/react-table';
-import { Node } from '../types';
+import { ErrorKind, Node } from '../types';
 import { getNodes } from '../services/api';
 import { HealthStatusIcon } from '../components/HealthStatusIcon';
 import { EventsTab } from './tabs/EventsTab';
 
+const pollErrorTitles: Record<ErrorKind, string> = {
+  auth: 'BMC rejected the credentials',
+  unreachable: 'BMC unreachable',
+  tls: 'BMC TLS error',
+  timeout: 'BMC timed out',
+  protocol: 'BMC Redfish error',
+  discovery: 'BareMetalHost cannot be polled',
+};
+
 export const NodeDetail: React.FC = () => {
   const location = useLocation();
-  // Extract node name from URL path: /baremetal-insights/nodes/:name
-  const name = useMemo(() => {
-    const match = location.pathname.match(/\/baremetal-insights\/nodes\/([^/]+)/);
-    return match ? decodeURIComponent(match[1]) : undefined;
+  // Extract node from URL path: /baremetal-insights/nodes/:namespace/:name or /baremetal-insights/nodes/:name
+  const { namespace, name } = useMemo(() => {
+    const match = location.pathname.match(/\/baremetal-insights\/nodes\/([^/]+)(?:\/([^/]+))?/);
+    if (!match) return { namespace: undefined, name: undefined };
+    if (match[2]) return { namespace: decodeURIComponent(match[1]), name: decodeURIComponent(match[2]) };
+    return { namespace: undefined, name: decodeURIComponent(match[1]) };
   }, [location.pathname]);
   const [node, setNode] = useState<Node | null>(null);
   const [loading, setLoading] = useState(true);
@@ -45,7 +56,9 @@ export const NodeDetail: React.FC = () => {
       }
       try {
         const nodesData = await getNodes();
-        const foundNode = nodesData.find((n) => n.name === name);
+        const foundNode = nodesData.find(
+          (n) => n.name === name && (!namespace || n.namespace === namespace)
+        );
         if (!foundNode) {
           setError(`Node ${name} not found`);
         } else {
@@ -59,7 +72,7 @@ export const NodeDetail: React.FC = () => {
       }
     };
     fetchData();
-  }, [name]);
+  }, [namespace, name]);
 
   if (loading) {
     return (
@@ -106,6 +119,43 @@ export const NodeDetail: React.FC = () => {
         <Title headingLevel="h1" style={{ marginTop: '1rem' }}>{node.name}</Title>
       </PageSection>
 
+      {(node.hostError || node.inventorySource === 'baremetalhost') && (
+        <PageSection>
+          <Alert
+            variant="warning"
+            isInline
+            title={node.hostError ? 'BareMetalHost reports an error' : 'BMC unreachable'}
+          >
+            {node.inventorySource === 'baremetalhost' &&
+              'Hardware details are from the last BareMetalHost inspection. '}
+            {node.hostError}
+          </Alert>
+        </PageSection>
+      )}
+
+      {node.pollError && (
+        <PageSection>
+          <Alert
+            variant="danger"
+            isInline
+            title={`${pollErrorTitles[node.pollError.kind]} since ${new Date(node.pollError.firstSeen).toLocaleString()}`}
+          >
+            {node.pollError.message}
+          </Alert>
+        </PageSection>
+      )}
+
+      {node.stale && Object.keys(node.stale).length > 0 && (
+        <PageSection>
+          <Alert variant="info" isInline title="Showing data from an earlier poll">
+            {Object.entries(node.stale)
+              .sort(([a], [b]) => a.localeCompare(b))
+              .map(([subsystem, data]) => `${subsystem}: collected ${new Date(data.collectedAt).toLocaleString()}`)
+              .join('; ')}
+          </Alert>
+        </PageSection>
+      )}
+
       {/* Overview and Power Cards - Side by Side */}
       <PageSection>
         <Grid hasGutter>
@@ -140,6 +190,39 @@ export const NodeDetail: React.FC = () => {
                       <a href={`https://${redfishIP}`} target="_blank" rel="noopener noreferrer">{redfishIP}</a>
                     </DescriptionListDescription>
                   </DescriptionListGroup>
+                  {node.bmcCertificate && (
+                    <DescriptionListGroup>
+                      <DescriptionListTerm>BMC Certificate</DescriptionListTerm>
+                      <DescriptionListDescription>
+                        {node.bmcCertificate.status}
+                        {node.bmcCertificate.selfSigned && ' (self-signed)'}
+                        {node.bmcCertificate.verificationDisabled && ', verification disabled'}
+                        {node.bmcCertificate.error && ` - ${node.bmcCertificate.error}`}
+                      </DescriptionListDescription>
+                    </DescriptionListGroup>
+                  )}
+                  {node.kubeNode && (
+                    <DescriptionListGroup>
+                      <DescriptionListTerm>OpenShift Node</DescriptionListTerm>
+                      <DescriptionListDescription>
+                        {node.kubeNode.name}
+                        {node.kubeNode.roles.length > 0 && ` (${node.kubeNode.roles.join(', ')})`}
+                        {' - '}
+                        {node.kubeNode.ready ? 'Ready' : 'NotReady'}
+                        {node.kubeNode.unschedulable && ', SchedulingDisabled'}
+                        {node.kubeNode.kubeletVersion && `, ${node.kubeNode.kubeletVersion}`}
+                      </DescriptionListDescription>
+                    </DescriptionListGroup>
+                  )}
+                  {node.provisioningState && (
+                    <DescriptionListGroup>
+                      <DescriptionListTerm>Provisioning State</DescriptionListTerm>
+                      <DescriptionListDescription>
+                        {node.provisioningState}
+                        {node.operationalStatus && ` (${node.operationalStatus})`}
+                      </DescriptionListDescription>
+                    </DescriptionListGroup>
+                  )}
                   <DescriptionListGroup>
                     <DescriptionListTerm>Last Scanned</DescriptionListTerm>
                     <DescriptionListDescription>{new Date(node.lastScanned).toLocaleString()}</DescriptionListDescription>
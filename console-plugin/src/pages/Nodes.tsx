@@ -220,16 +220,16 @@ export const Nodes: React.FC = () => {
           <Tbody>
             {filteredNodes.map((node) => (
               <Tr
-                key={node.name}
+                key={`${node.namespace}/${node.name}`}
                 isClickable
-                onRowClick={() => history.push(`/baremetal-insights/nodes/${node.name}`)}
+                onRowClick={() => history.push(`/baremetal-insights/nodes/${node.namespace}/${node.name}`)}
               >
                 <Td dataLabel="Name">
                   <a
                     href="#"
                     onClick={(e) => {
                       e.preventDefault();
-                      history.push(`/baremetal-insights/nodes/${node.name}`);
+                      history.push(`/baremetal-insights/nodes/${node.namespace}/${node.name}`);
                     }}
                     style={{ color: 'var(--pf-v5-global--link--Color)', textDecoration: 'none' }}
                   >
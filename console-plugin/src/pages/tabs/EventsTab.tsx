@@ -18,14 +18,26 @@ export const EventsTab: React.FC<EventsTabProps> = ({ events }) => {
         <Tr>
           <Th>Severity</Th>
           <Th>Event</Th>
+          <Th>Source</Th>
           <Th>Timestamp</Th>
         </Tr>
       </Thead>
       <Tbody>
         {events.map((event) => (
-          <Tr key={event.id}>
+          <Tr key={`${event.logService}/${event.id}/${event.timestamp}`}>
             <Td><HealthStatusIcon status={event.severity} showLabel /></Td>
-            <Td>{event.message}</Td>
+            <Td>
+              {event.messageId ? `${event.messageId}: ${event.message}` : event.message}
+              {event.resolution && (
+                <>
+                  <br />
+                  <small style={{ color: 'var(--pf-v5-global--Color--200)' }}>
+                    Recommended action: {event.resolution}
+                  </small>
+                </>
+              )}
+            </Td>
+            <Td>{[event.logService, event.category, event.component].filter(Boolean).join(' / ') || '-'}</Td>
             <Td>{new Date(event.timestamp).toLocaleString()}</Td>
           </Tr>
         ))}
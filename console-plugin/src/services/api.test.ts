@@ -2,6 +2,10 @@ import { consoleFetchJSON } from '@openshift-console/dynamic-plugin-sdk';
 import {
   getNodes,
   getNodeFirmware,
+  getNodePollStatus,
+  refreshNode,
+  getChanges,
+  getEventPage,
   getUpdates,
   getDashboard,
   getNamespaces,
@@ -34,6 +38,22 @@ describe('API Service', () => {
     );
   });
 
+  it('getNodes filters by namespace and role', async () => {
+    mockFetch.mockResolvedValue({ nodes: [] });
+    await getNodes('cluster-a', 'master');
+    expect(mockFetch).toHaveBeenCalledWith(
+      expect.stringContaining('/api/v1/nodes?namespace=cluster-a&role=master')
+    );
+  });
+
+  it('getNodeFirmware uses the namespaced route when a namespace is given', async () => {
+    mockFetch.mockResolvedValue({ firmware: [] });
+    await getNodeFirmware('master-0', 'cluster-a');
+    expect(mockFetch).toHaveBeenCalledWith(
+      expect.stringContaining('/api/v1/namespaces/cluster-a/nodes/master-0/firmware')
+    );
+  });
+
   it('getUpdates calls correct endpoint', async () => {
     mockFetch.mockResolvedValue({ updates: [] });
     await getUpdates();
@@ -62,6 +82,42 @@ describe('API Service', () => {
     expect(result).toEqual([]);
   });
 
+  it('getNodePollStatus calls the namespaced pollstatus endpoint', async () => {
+    mockFetch.mockResolvedValue({ pollStatus: null });
+    const result = await getNodePollStatus('worker-0', 'cluster-a');
+    expect(mockFetch).toHaveBeenCalledWith(
+      expect.stringContaining('/api/v1/namespaces/cluster-a/nodes/worker-0/pollstatus')
+    );
+    expect(result).toBeNull();
+  });
+
+  it('refreshNode posts the selected subsystems', async () => {
+    mockFetch.mockResolvedValue({ id: 'abc', state: 'queued', nodes: [] });
+    await refreshNode('worker-0', 'cluster-a', ['firmware']);
+    expect(mockFetch).toHaveBeenCalledWith(
+      expect.stringContaining('/api/v1/namespaces/cluster-a/nodes/worker-0/refresh'),
+      expect.objectContaining({ method: 'POST', body: JSON.stringify({ subsystems: ['firmware'] }) })
+    );
+  });
+
+  it('getChanges passes filters and handles a missing changes array', async () => {
+    mockFetch.mockResolvedValue({});
+    const result = await getChanges(10, 'cluster-a', 'health-changed');
+    expect(mockFetch).toHaveBeenCalledWith(
+      expect.stringContaining('/api/v1/changes?limit=10&namespace=cluster-a&type=health-changed')
+    );
+    expect(result).toEqual([]);
+  });
+
+  it('getEventPage passes filters and the cursor', async () => {
+    mockFetch.mockResolvedValue({ nextCursor: 'abc' });
+    const result = await getEventPage({ node: 'worker-0', since: '2026-01-01T00:00:00Z', severity: ['Critical', 'Warning'], cursor: 'xyz' });
+    expect(mockFetch).toHaveBeenCalledWith(
+      expect.stringContaining('/api/v1/events?node=worker-0&since=2026-01-01T00%3A00%3A00Z&severity=Critical%2CWarning&cursor=xyz')
+    );
+    expect(result).toEqual({ events: [], nextCursor: 'abc' });
+  });
+
   it('getUpdates handles missing updates array', async () => {
     mockFetch.mockResolvedValue({});
     const result = await getUpdates();
@@ -80,7 +136,7 @@ describe('Dashboard API', () => {
       healthSummary: { healthy: 8, warning: 1, critical: 1 },
       powerSummary: { on: 9, off: 1 },
       updatesSummary: { total: 5, critical: 1, recommended: 2, optional: 2, nodesWithUpdates: 3 },
-      jobsSummary: { pending: 0, inProgress: 0, completed: 0 },
+      jobsSummary: { pending: 0, inProgress: 0, completed: 0, failed: 0 },
       lastRefresh: '2025-12-22T20:00:00Z',
       nextRefresh: '2025-12-22T20:30:00Z',
     };
@@ -96,7 +152,7 @@ describe('Dashboard API', () => {
       healthSummary: { healthy: 5, warning: 0, critical: 0 },
       powerSummary: { on: 5, off: 0 },
       updatesSummary: { total: 2, critical: 0, recommended: 1, optional: 1, nodesWithUpdates: 2 },
-      jobsSummary: { pending: 0, inProgress: 0, completed: 0 },
+      jobsSummary: { pending: 0, inProgress: 0, completed: 0, failed: 0 },
       lastRefresh: '2025-12-22T20:00:00Z',
       nextRefresh: '2025-12-22T20:30:00Z',
     };
@@ -9,6 +9,7 @@ import {
   PowerSummary,
   HealthEvent,
   EventsResponse,
+  EventQuery,
   DashboardStats,
   Task,
   TasksResponse,
@@ -16,51 +17,126 @@ import {
   NamespacesResponse,
   ScheduleUpdateRequest,
   ScheduleUpdateResponse,
+  CatalogStatus,
+  Baseline,
+  BaselinesResponse,
+  PollReport,
+  Refresh,
+  ChangeEvent,
+  ChangeType,
 } from '../types';
 
 const API_BASE = '/api/proxy/plugin/openshift-baremetal-insights-plugin/baremetal-insights';
 
-export const getNodes = async (namespace?: string): Promise<Node[]> => {
-  const params = namespace ? `?namespace=${encodeURIComponent(namespace)}` : '';
-  const response = (await consoleFetchJSON(`${API_BASE}/api/v1/nodes${params}`)) as NodesResponse;
+export const getNodes = async (namespace?: string, role?: string): Promise<Node[]> => {
+  const params = new URLSearchParams();
+  if (namespace) params.set('namespace', namespace);
+  if (role) params.set('role', role);
+  const query = params.toString() ? `?${params}` : '';
+  const response = (await consoleFetchJSON(`${API_BASE}/api/v1/nodes${query}`)) as NodesResponse;
   return response.nodes || [];
 };
 
-export const getNodeFirmware = async (name: string): Promise<FirmwareComponent[]> => {
-  const response = (await consoleFetchJSON(
-    `${API_BASE}/api/v1/nodes/${encodeURIComponent(name)}/firmware`
-  )) as { firmware: FirmwareComponent[] };
+// Node routes are namespace-qualified when the namespace is known; bare names
+// only resolve while they are unique across namespaces
+const nodePath = (name: string, namespace?: string): string =>
+  namespace
+    ? `${API_BASE}/api/v1/namespaces/${encodeURIComponent(namespace)}/nodes/${encodeURIComponent(name)}`
+    : `${API_BASE}/api/v1/nodes/${encodeURIComponent(name)}`;
+
+export const getNodeFirmware = async (name: string, namespace?: string): Promise<FirmwareComponent[]> => {
+  const response = (await consoleFetchJSON(`${nodePath(name, namespace)}/firmware`)) as {
+    firmware: FirmwareComponent[];
+  };
   return response.firmware || [];
 };
 
-export const getNodeHealth = async (name: string): Promise<{ health: string; healthRollup: HealthRollup }> => {
-  return consoleFetchJSON(`${API_BASE}/api/v1/nodes/${encodeURIComponent(name)}/health`);
+export const getNodeHealth = async (name: string, namespace?: string): Promise<{ health: string; healthRollup: HealthRollup }> => {
+  return consoleFetchJSON(`${nodePath(name, namespace)}/health`);
+};
+
+export const getNodeThermal = async (name: string, namespace?: string): Promise<{ thermal: ThermalSummary }> => {
+  return consoleFetchJSON(`${nodePath(name, namespace)}/thermal`);
 };
 
-export const getNodeThermal = async (name: string): Promise<{ thermal: ThermalSummary }> => {
-  return consoleFetchJSON(`${API_BASE}/api/v1/nodes/${encodeURIComponent(name)}/thermal`);
+export const getNodePower = async (name: string, namespace?: string): Promise<{ power: PowerSummary }> => {
+  return consoleFetchJSON(`${nodePath(name, namespace)}/power`);
 };
 
-export const getNodePower = async (name: string): Promise<{ power: PowerSummary }> => {
-  return consoleFetchJSON(`${API_BASE}/api/v1/nodes/${encodeURIComponent(name)}/power`);
+export const getNodePollStatus = async (name: string, namespace?: string): Promise<PollReport | null> => {
+  const response = (await consoleFetchJSON(`${nodePath(name, namespace)}/pollstatus`)) as {
+    pollStatus: PollReport | null;
+  };
+  return response.pollStatus ?? null;
+};
+
+// Subsystems may be limited to e.g. ['firmware'] or ['health']; all are polled by default
+export const refreshNode = async (name: string, namespace?: string, subsystems?: string[]): Promise<Refresh> => {
+  return consoleFetchJSON(`${nodePath(name, namespace)}/refresh`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ subsystems }),
+  });
 };
 
-export const getNodeEvents = async (name: string): Promise<HealthEvent[]> => {
-  const response = (await consoleFetchJSON(
-    `${API_BASE}/api/v1/nodes/${encodeURIComponent(name)}/events`
-  )) as EventsResponse;
+export const refreshAll = async (subsystems?: string[]): Promise<Refresh> => {
+  return consoleFetchJSON(`${API_BASE}/api/v1/refresh`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ subsystems }),
+  });
+};
+
+export const getRefresh = async (id: string): Promise<Refresh> => {
+  return consoleFetchJSON(`${API_BASE}/api/v1/refresh/${encodeURIComponent(id)}`);
+};
+
+export const getNodeEvents = async (name: string, namespace?: string): Promise<HealthEvent[]> => {
+  const response = (await consoleFetchJSON(`${nodePath(name, namespace)}/events`)) as EventsResponse;
   return response.events || [];
 };
 
-export const getEvents = async (limit?: number, node?: string): Promise<HealthEvent[]> => {
+export const getNodeChanges = async (name: string, namespace?: string, type?: ChangeType): Promise<ChangeEvent[]> => {
+  const query = type ? `?type=${encodeURIComponent(type)}` : '';
+  const response = (await consoleFetchJSON(`${nodePath(name, namespace)}/changes${query}`)) as { changes: ChangeEvent[] };
+  return response.changes || [];
+};
+
+export const getChanges = async (limit?: number, namespace?: string, type?: ChangeType): Promise<ChangeEvent[]> => {
+  const params = new URLSearchParams();
+  if (limit) params.set('limit', String(limit));
+  if (namespace) params.set('namespace', namespace);
+  if (type) params.set('type', type);
+  const query = params.toString() ? `?${params}` : '';
+  const response = (await consoleFetchJSON(`${API_BASE}/api/v1/changes${query}`)) as { changes: ChangeEvent[] };
+  return response.changes || [];
+};
+
+export const getEvents = async (limit?: number, node?: string, namespace?: string): Promise<HealthEvent[]> => {
   const params = new URLSearchParams();
   if (limit) params.set('limit', String(limit));
   if (node) params.set('node', node);
+  if (namespace) params.set('namespace', namespace);
   const query = params.toString() ? `?${params}` : '';
   const response = (await consoleFetchJSON(`${API_BASE}/api/v1/events${query}`)) as EventsResponse;
   return response.events || [];
 };
 
+// getEventPage returns one page of events; pass nextCursor back as cursor for the next
+export const getEventPage = async (q: EventQuery = {}): Promise<EventsResponse> => {
+  const params = new URLSearchParams();
+  if (q.limit) params.set('limit', String(q.limit));
+  if (q.node) params.set('node', q.node);
+  if (q.namespace) params.set('namespace', q.namespace);
+  if (q.since) params.set('since', q.since);
+  if (q.until) params.set('until', q.until);
+  if (q.severity?.length) params.set('severity', q.severity.join(','));
+  if (q.cursor) params.set('cursor', q.cursor);
+  const query = params.toString() ? `?${params}` : '';
+  const response = (await consoleFetchJSON(`${API_BASE}/api/v1/events${query}`)) as EventsResponse;
+  return { ...response, events: response.events || [] };
+};
+
 export const getUpdates = async (): Promise<UpdatesResponse> => {
   const response = (await consoleFetchJSON(`${API_BASE}/api/v1/updates`)) as UpdatesResponse;
   return { updates: response.updates || [] };
@@ -87,6 +163,29 @@ export const getFirmware = async (namespace?: string): Promise<FirmwareResponse>
   return consoleFetchJSON(`${API_BASE}/api/v1/firmware${params}`);
 };
 
+export const getCatalogStatus = async (): Promise<CatalogStatus> => {
+  return consoleFetchJSON(`${API_BASE}/api/v1/catalog`);
+};
+
+export const getBaselines = async (): Promise<Baseline[]> => {
+  const response = (await consoleFetchJSON(`${API_BASE}/api/v1/baselines`)) as BaselinesResponse;
+  return response.baselines || [];
+};
+
+export const putBaseline = async (baseline: Baseline): Promise<Baseline> => {
+  return consoleFetchJSON(`${API_BASE}/api/v1/baselines/${encodeURIComponent(baseline.name)}`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(baseline),
+  });
+};
+
+export const deleteBaseline = async (name: string): Promise<void> => {
+  await consoleFetchJSON(`${API_BASE}/api/v1/baselines/${encodeURIComponent(name)}`, {
+    method: 'DELETE',
+  });
+};
+
 export const scheduleUpdates = async (request: ScheduleUpdateRequest): Promise<ScheduleUpdateResponse> => {
   return consoleFetchJSON(`${API_BASE}/api/v1/updates/schedule`, {
     method: 'POST',
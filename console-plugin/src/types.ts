@@ -3,6 +3,8 @@ export type HealthStatus = 'OK' | 'Warning' | 'Critical' | 'Unknown';
 export type PowerState = 'On' | 'Off' | 'Unknown';
 export type Severity = 'Critical' | 'Recommended' | 'Optional';
 export type TaskState = 'Pending' | 'Running' | 'Completed' | 'Exception';
+export type VersionStatus = 'up-to-date' | 'update-available' | 'newer' | 'unknown';
+export type ComplianceStatus = 'compliant' | 'behind' | 'ahead' | 'unknown';
 
 export interface FirmwareComponent {
   id: string;
@@ -12,6 +14,18 @@ export interface FirmwareComponent {
   updateable: boolean;
   componentType: string;
   severity?: Severity;
+  versionStatus?: VersionStatus;
+  identity?: DeviceIdentity;
+  targetVersion?: string;
+  compliance?: ComplianceStatus;
+}
+
+export interface DeviceIdentity {
+  componentId?: string;
+  vendorId?: string;
+  deviceId?: string;
+  subVendorId?: string;
+  subDeviceId?: string;
 }
 
 export interface HealthRollup {
@@ -76,18 +90,163 @@ export interface Node {
   model: string;
   manufacturer: string;
   serviceTag: string;
+  systemId?: string;
   powerState: PowerState;
   lastScanned: string;
   status: NodeStatus;
   firmwareCount: number;
   updatesAvailable: number;
   firmware?: FirmwareComponent[];
+  baseline?: string;
+  compliance?: ComplianceStatus;
   health: HealthStatus;
   healthRollup?: HealthRollup;
   thermalSummary?: ThermalSummary;
   powerSummary?: PowerSummary;
   networkAdapters?: NetworkAdapter[];
   storage?: StorageDetail;
+  provisioningState?: string;
+  operationalStatus?: string;
+  hostError?: string;
+  hardware?: HostHardware;
+  inventorySource?: InventorySource;
+  kubeNode?: KubeNode;
+  bmcCertificate?: BMCCertificate;
+  pollError?: PollError;
+  pollSummary?: PollSummary;
+  stale?: Record<string, StaleData>;
+}
+
+// Data kept from an earlier poll because the last poll of its subsystem failed;
+// ageSeconds is as of when the response was served
+export interface StaleData {
+  collectedAt: string;
+  ageSeconds: number;
+}
+
+// Outcome of polling one subsystem; lastSuccess is absent if it never succeeded
+export interface SubsystemStatus {
+  ok: boolean;
+  error?: string;
+  consecutiveFailures: number;
+  lastSuccess?: string;
+}
+
+// Report of a node's last poll, from /nodes/{name}/pollstatus
+export interface PollReport {
+  startedAt: string;
+  finishedAt: string;
+  durationSeconds: number;
+  ok: boolean;
+  consecutiveFailures: number;
+  lastSuccess?: string;
+  subsystems: Record<string, SubsystemStatus>;
+}
+
+// Summary of the last poll, listed with every node
+export interface PollSummary {
+  lastPoll: string;
+  ok: boolean;
+  failedSubsystems?: string[];
+  consecutiveFailures: number;
+  lastSuccess?: string;
+}
+
+export type ChangeType =
+  | 'firmware-changed'
+  | 'health-changed'
+  | 'power-state-changed'
+  | 'psu-redundancy-lost'
+  | 'fan-failed'
+  | 'disk-offline'
+  | 'nic-link-down'
+  | 'update-available';
+
+// Change detected between two polls of a node, from /changes
+export interface ChangeEvent {
+  id: string;
+  timestamp: string;
+  type: ChangeType;
+  severity: HealthStatus;
+  component?: string;
+  previous?: string;
+  current?: string;
+  message: string;
+  nodeName: string;
+  namespace: string;
+}
+
+export type RefreshState = 'queued' | 'running' | 'done' | 'failed' | 'skipped' | 'partial';
+
+// On-demand poll of one node or the whole fleet, from /refresh/{id}
+export interface Refresh {
+  id: string;
+  subsystems?: string[];
+  state: RefreshState;
+  requestedAt: string;
+  finishedAt?: string;
+  nodes: RefreshNode[];
+}
+
+export interface RefreshNode {
+  namespace: string;
+  name: string;
+  state: RefreshState;
+  error?: string;
+}
+
+export type ErrorKind = 'auth' | 'unreachable' | 'tls' | 'timeout' | 'protocol' | 'discovery';
+
+// Why the last poll of a node failed; firstSeen is kept while the same kind repeats
+export interface PollError {
+  kind: ErrorKind;
+  message: string;
+  firstSeen: string;
+  lastSeen: string;
+}
+
+export type CertificateStatus = 'verified' | 'untrusted' | 'rejected';
+
+// How the BMC's TLS certificate validated
+export interface BMCCertificate {
+  status: CertificateStatus;
+  verificationDisabled: boolean;
+  selfSigned: boolean;
+  subject: string;
+  issuer: string;
+  notAfter: string;
+  error?: string;
+}
+
+// Kubernetes Node running on the host
+export interface KubeNode {
+  name: string;
+  machine?: string;
+  roles: string[];
+  ready: boolean;
+  unschedulable: boolean;
+  kubeletVersion?: string;
+}
+
+export type InventorySource = 'bmc' | 'baremetalhost';
+
+// Hardware inventory recorded by Metal3 in the BareMetalHost status
+export interface HostHardware {
+  hostname?: string;
+  systemVendor?: { manufacturer?: string; productName?: string; serialNumber?: string };
+  firmware?: { bios?: { vendor?: string; version?: string; date?: string } };
+  cpu?: { arch?: string; model?: string; clockMegahertz?: number; count?: number };
+  ramMebibytes?: number;
+  nics?: { name?: string; model?: string; mac?: string; ip?: string; speedGbps?: number }[];
+  storage?: {
+    name?: string;
+    type?: string;
+    vendor?: string;
+    model?: string;
+    serialNumber?: string;
+    sizeBytes?: number;
+    rotational?: boolean;
+  }[];
 }
 
 export interface HealthEvent {
@@ -95,13 +254,23 @@ export interface HealthEvent {
   timestamp: string;
   severity: HealthStatus;
   message: string;
+  messageId?: string;
+  category?: string;
+  resolution?: string;
+  component?: string;
   nodeName: string;
+  namespace?: string;
+  bmc?: string;
+  logService?: string;
 }
 
+// Update pending on a group of nodes; affectedNodeRefs qualifies the names in
+// affectedNodes as namespace/name, as names can repeat across namespaces
 export interface UpdateSummary {
   componentType: string;
   availableVersion: string;
   affectedNodes: string[];
+  affectedNodeRefs: string[];
   nodeCount: number;
 }
 
@@ -109,10 +278,12 @@ export interface Task {
   node: string;
   namespace: string;
   taskId: string;
+  bmcTaskId?: string;
   taskType: string;
   taskState: TaskState;
   percentComplete: number;
   startTime: string;
+  endTime?: string;
   message: string;
 }
 
@@ -139,6 +310,15 @@ export interface JobsSummary {
   pending: number;
   inProgress: number;
   completed: number;
+  failed: number;
+}
+
+export interface ComplianceSummary {
+  compliant: number;
+  behind: number;
+  ahead: number;
+  unknown: number;
+  noBaseline: number;
 }
 
 export interface DashboardStats {
@@ -146,6 +326,7 @@ export interface DashboardStats {
   healthSummary: HealthSummary;
   powerSummary: PowerStateSummary;
   updatesSummary: UpdatesSummary;
+  complianceSummary: ComplianceSummary;
   jobsSummary: JobsSummary;
   lastRefresh: string;
   nextRefresh: string;
@@ -154,6 +335,7 @@ export interface DashboardStats {
 export interface FirmwareEntry {
   node: string;
   namespace: string;
+  baseline?: string;
   firmware: FirmwareComponent;
 }
 
@@ -163,6 +345,9 @@ export interface FirmwareSummary {
   critical: number;
   recommended: number;
   optional: number;
+  compliant: number;
+  behind: number;
+  ahead: number;
 }
 
 export interface FirmwareResponse {
@@ -188,6 +373,18 @@ export interface UpdatesResponse {
 
 export interface EventsResponse {
   events: HealthEvent[];
+  nextCursor?: string;
+}
+
+// Filters of /api/v1/events; since and until are RFC 3339 times
+export interface EventQuery {
+  namespace?: string;
+  node?: string;
+  since?: string;
+  until?: string;
+  severity?: HealthStatus[];
+  limit?: number;
+  cursor?: string;
 }
 
 export interface ScheduleUpdateRequest {
@@ -198,5 +395,42 @@ export interface ScheduleUpdateRequest {
 
 export interface ScheduleUpdateResponse {
   taskIds: string[];
-  message: string;
+}
+
+export interface CatalogVerification {
+  method: string;
+  verified: boolean;
+  detail?: string;
+}
+
+export interface CatalogStatus {
+  source?: string;
+  verification: CatalogVerification;
+  lastSynced?: string;
+  ageSeconds: number;
+  stale: boolean;
+  entries: number;
+  lastError?: string;
+}
+
+export interface BaselineTarget {
+  model?: string;
+  componentType?: string;
+  name?: string;
+  componentId?: string;
+  version: string;
+}
+
+export interface Baseline {
+  name: string;
+  description?: string;
+  release?: string;
+  nodes?: string[];
+  namespaces?: string[];
+  targets: BaselineTarget[];
+  source?: 'configmap' | 'api';
+}
+
+export interface BaselinesResponse {
+  baselines: Baseline[];
 }
@@ -2,19 +2,55 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/baseline"
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/poller"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+	"github.com/cragr/openshift-baremetal-insights/internal/updates"
 )
 
 func (s *Server) listNodes(w http.ResponseWriter, r *http.Request) {
 	namespace := r.URL.Query().Get("namespace")
 	nodes := s.store.ListNodesByNamespace(namespace)
 
+	// Filter by the role of the Kubernetes Node running on the host, e.g. master
+	if role := r.URL.Query().Get("role"); role != "" {
+		filtered := make([]models.Node, 0, len(nodes))
+		for _, node := range nodes {
+			if node.HasRole(role) {
+				filtered = append(filtered, node)
+			}
+		}
+		nodes = filtered
+	}
+
+	// Filter by BMC certificate status, e.g. untrusted to find self-signed BMCs
+	if certificate := r.URL.Query().Get("certificate"); certificate != "" {
+		filtered := make([]models.Node, 0, len(nodes))
+		for _, node := range nodes {
+			if node.BMCCertificate != nil && string(node.BMCCertificate.Status) == certificate {
+				filtered = append(filtered, node)
+			}
+		}
+		nodes = filtered
+	}
+
+	now := time.Now()
+	for i := range nodes {
+		nodes[i].SetStaleAges(now)
+	}
+
 	response := map[string]interface{}{
 		"nodes": nodes,
 	}
@@ -27,18 +63,39 @@ func (s *Server) listNodes(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) getNodeFirmware(w http.ResponseWriter, r *http.Request) {
+// lookupNode finds the node named in the URL, qualified by the namespace in the
+// URL if present. Bare names must be unique across namespaces. On failure it
+// writes the error response and returns false.
+func (s *Server) lookupNode(w http.ResponseWriter, r *http.Request) (models.Node, bool) {
 	name := chi.URLParam(r, "name")
+	if namespace := chi.URLParam(r, "namespace"); namespace != "" {
+		name = namespace + "/" + name
+	}
 
-	node, ok := s.store.GetNode(name)
-	if !ok {
+	node, err := s.store.ResolveNode(name)
+	if err != nil {
+		status := http.StatusNotFound
+		message := "node not found"
+		if errors.Is(err, store.ErrAmbiguousNode) {
+			status = http.StatusConflict
+			message = err.Error()
+		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "node not found"}); err != nil {
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
 			log.Printf("Failed to encode error response: %v", err)
 		}
+		return models.Node{}, false
+	}
+	return node, true
+}
+
+func (s *Server) getNodeFirmware(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.lookupNode(w, r)
+	if !ok {
 		return
 	}
+	node.SetStaleAges(time.Now())
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(node); err != nil {
@@ -57,11 +114,14 @@ func (s *Server) health(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// UpdateSummary groups updates by component type
+// UpdateSummary groups updates by component type. AffectedNodes holds node
+// names, which can repeat across namespaces on a hub; AffectedNodeRefs holds the
+// same nodes as namespace/name keys.
 type UpdateSummary struct {
 	ComponentType    string   `json:"componentType"`
 	AvailableVersion string   `json:"availableVersion"`
 	AffectedNodes    []string `json:"affectedNodes"`
+	AffectedNodeRefs []string `json:"affectedNodeRefs"`
 	NodeCount        int      `json:"nodeCount"`
 }
 
@@ -73,19 +133,27 @@ func (s *Server) listUpdates(w http.ResponseWriter, r *http.Request) {
 
 	for _, node := range nodes {
 		for _, fw := range node.Firmware {
-			if !fw.NeedsUpdate() {
+			if !node.NeedsUpdate(&fw) {
 				continue
 			}
 
-			key := fw.ComponentType + "|" + fw.AvailableVersion
+			// Nodes with a baseline are brought to its target, not the latest version
+			target := fw.AvailableVersion
+			if node.Baseline != "" {
+				target = fw.TargetVersion
+			}
+
+			key := fw.ComponentType + "|" + target
 			if summary, ok := updateMap[key]; ok {
 				summary.AffectedNodes = append(summary.AffectedNodes, node.Name)
+				summary.AffectedNodeRefs = append(summary.AffectedNodeRefs, node.Key())
 				summary.NodeCount++
 			} else {
 				updateMap[key] = &UpdateSummary{
 					ComponentType:    fw.ComponentType,
-					AvailableVersion: fw.AvailableVersion,
+					AvailableVersion: target,
 					AffectedNodes:    []string{node.Name},
+					AffectedNodeRefs: []string{node.Key()},
 					NodeCount:        1,
 				}
 			}
@@ -111,13 +179,8 @@ func (s *Server) listUpdates(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) getNodeHealth(w http.ResponseWriter, r *http.Request) {
-	name := chi.URLParam(r, "name")
-
-	node, ok := s.store.GetNode(name)
+	node, ok := s.lookupNode(w, r)
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "node not found"})
 		return
 	}
 
@@ -131,13 +194,8 @@ func (s *Server) getNodeHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) getNodeThermal(w http.ResponseWriter, r *http.Request) {
-	name := chi.URLParam(r, "name")
-
-	node, ok := s.store.GetNode(name)
+	node, ok := s.lookupNode(w, r)
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "node not found"})
 		return
 	}
 
@@ -150,13 +208,8 @@ func (s *Server) getNodeThermal(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) getNodePower(w http.ResponseWriter, r *http.Request) {
-	name := chi.URLParam(r, "name")
-
-	node, ok := s.store.GetNode(name)
+	node, ok := s.lookupNode(w, r)
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "node not found"})
 		return
 	}
 
@@ -168,51 +221,136 @@ func (s *Server) getNodePower(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func (s *Server) getNodeEvents(w http.ResponseWriter, r *http.Request) {
-	name := chi.URLParam(r, "name")
-
-	if s.eventStore == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{"events": []interface{}{}})
+// getNodePollStatus returns the report of the node's last poll, subsystem by subsystem
+func (s *Server) getNodePollStatus(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.lookupNode(w, r)
+	if !ok {
 		return
 	}
 
-	events := s.eventStore.ListEvents(50, name)
-
 	response := map[string]interface{}{
-		"events": events,
+		"pollStatus": node.PollReport,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+func (s *Server) getNodeEvents(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.lookupNode(w, r)
+	if !ok {
+		return
+	}
+
+	s.queryEvents(w, r, 50, node.Namespace, node.Name)
+}
+
 func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	s.queryEvents(w, r, 100, r.URL.Query().Get("namespace"), r.URL.Query().Get("node"))
+}
+
+// queryEvents writes a page of events filtered by the since, until, severity,
+// limit and cursor query parameters
+func (s *Server) queryEvents(w http.ResponseWriter, r *http.Request, limit int, namespace, nodeName string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query, err := parseEventQuery(r, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	query.Namespace = namespace
+	query.NodeName = nodeName
+
 	if s.eventStore == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{"events": []interface{}{}})
+		json.NewEncoder(w).Encode(store.EventPage{Events: []models.HealthEvent{}})
 		return
 	}
 
-	// Parse query params
-	limitStr := r.URL.Query().Get("limit")
-	limit := 100
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
+	page, err := s.eventStore.QueryEvents(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseEventQuery reads the event filters of a request
+func parseEventQuery(r *http.Request, limit int) (store.EventQuery, error) {
+	params := r.URL.Query()
+	query := store.EventQuery{Limit: limit, Cursor: params.Get("cursor")}
+
+	if l, err := strconv.Atoi(params.Get("limit")); err == nil && l > 0 {
+		query.Limit = l
+	}
+	for name, t := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if v := params.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s %q: expected an RFC 3339 time", name, v)
+			}
+			*t = parsed
+		}
+	}
+	for _, v := range strings.Split(params.Get("severity"), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		severity, ok := parseSeverity(v)
+		if !ok {
+			return query, fmt.Errorf("invalid severity %q", v)
 		}
+		query.Severities = append(query.Severities, severity)
 	}
+	return query, nil
+}
 
-	nodeName := r.URL.Query().Get("node")
+func parseSeverity(s string) (models.HealthStatus, bool) {
+	for _, h := range []models.HealthStatus{models.HealthOK, models.HealthWarning, models.HealthCritical, models.HealthUnknown} {
+		if strings.EqualFold(s, string(h)) {
+			return h, true
+		}
+	}
+	return "", false
+}
 
-	events := s.eventStore.ListEvents(limit, nodeName)
+// listChanges returns change events across the fleet, newest first
+func (s *Server) listChanges(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
 
-	response := map[string]interface{}{
-		"events": events,
+	changes := []models.ChangeEvent{}
+	if s.changes != nil {
+		changes = s.changes.ListChanges(limit, r.URL.Query().Get("namespace"), r.URL.Query().Get("node"),
+			models.ChangeType(r.URL.Query().Get("type")))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes": changes,
+	})
+}
+
+// getNodeChanges returns the change events of one node, newest first
+func (s *Server) getNodeChanges(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.lookupNode(w, r)
+	if !ok {
+		return
+	}
+
+	changes := []models.ChangeEvent{}
+	if s.changes != nil {
+		changes = s.changes.ListChanges(100, node.Namespace, node.Name, models.ChangeType(r.URL.Query().Get("type")))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes": changes,
+	})
 }
 
 func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
@@ -247,13 +385,14 @@ func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Updates summary
+	// Updates summary, against each node's baseline where it has one
 	nodesWithUpdates := make(map[string]bool)
 	for _, node := range nodes {
+		stats.ComplianceSummary.Add(&node)
 		for _, fw := range node.Firmware {
-			if fw.NeedsUpdate() {
+			if node.NeedsUpdate(&fw) {
 				stats.UpdatesSummary.Total++
-				nodesWithUpdates[node.Name] = true
+				nodesWithUpdates[node.Key()] = true
 				switch fw.Severity {
 				case models.SeverityCritical:
 					stats.UpdatesSummary.Critical++
@@ -267,8 +406,10 @@ func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
 	}
 	stats.UpdatesSummary.NodesWithUpdates = len(nodesWithUpdates)
 
-	// Jobs summary (placeholder - will be populated from TaskStore later)
-	stats.JobsSummary = models.JobsSummary{}
+	// Jobs summary
+	if s.taskStore != nil {
+		stats.JobsSummary = s.taskStore.Summary(namespace)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
@@ -302,6 +443,7 @@ func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
 type FirmwareEntry struct {
 	Node      string                   `json:"node"`
 	Namespace string                   `json:"namespace"`
+	Baseline  string                   `json:"baseline,omitempty"`
 	Firmware  models.FirmwareComponent `json:"firmware"`
 }
 
@@ -316,6 +458,9 @@ func (s *Server) listFirmware(w http.ResponseWriter, r *http.Request) {
 		Critical         int `json:"critical"`
 		Recommended      int `json:"recommended"`
 		Optional         int `json:"optional"`
+		Compliant        int `json:"compliant"`
+		Behind           int `json:"behind"`
+		Ahead            int `json:"ahead"`
 	}{}
 
 	for _, node := range nodes {
@@ -323,10 +468,19 @@ func (s *Server) listFirmware(w http.ResponseWriter, r *http.Request) {
 			entries = append(entries, FirmwareEntry{
 				Node:      node.Name,
 				Namespace: node.Namespace,
+				Baseline:  node.Baseline,
 				Firmware:  fw,
 			})
 			summary.Total++
-			if fw.NeedsUpdate() {
+			switch fw.Compliance {
+			case models.ComplianceCompliant:
+				summary.Compliant++
+			case models.ComplianceBehind:
+				summary.Behind++
+			case models.ComplianceAhead:
+				summary.Ahead++
+			}
+			if node.NeedsUpdate(&fw) {
 				summary.UpdatesAvailable++
 				switch fw.Severity {
 				case models.SeverityCritical:
@@ -346,3 +500,236 @@ func (s *Server) listFirmware(w http.ResponseWriter, r *http.Request) {
 		"firmware": entries,
 	})
 }
+
+func (s *Server) scheduleUpdates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.updater == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "update scheduling not available"})
+		return
+	}
+
+	var req updates.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	result, err := s.updater.Schedule(r.Context(), req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, updates.ErrInvalidRequest), errors.Is(err, updates.ErrNoUpdates):
+			status = http.StatusBadRequest
+		case errors.Is(err, updates.ErrCatalogUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	// The updates are submitted to the BMCs in the background; their tasks
+	// show when each was accepted
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(result)
+}
+
+// getCatalogStatus reports which catalog source is active and how it was verified
+func (s *Server) getCatalogStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.catalog == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "catalog not configured"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(s.catalog.Status())
+}
+
+func (s *Server) listBaselines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	baselines := []baseline.Baseline{}
+	if s.baselines != nil {
+		baselines = s.baselines.List()
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"baselines": baselines,
+	})
+}
+
+func (s *Server) getBaseline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.baselines == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "baseline not found"})
+		return
+	}
+
+	b, ok := s.baselines.Get(chi.URLParam(r, "name"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "baseline not found"})
+		return
+	}
+	json.NewEncoder(w).Encode(b)
+}
+
+// putBaseline creates or replaces an API-defined baseline and re-evaluates all nodes
+func (s *Server) putBaseline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.baselines == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "baselines not available"})
+		return
+	}
+
+	var b baseline.Baseline
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+	name := chi.URLParam(r, "name")
+	if b.Name != "" && b.Name != name {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "baseline name does not match URL"})
+		return
+	}
+	b.Name = name
+
+	if err := s.baselines.Put(b); err != nil {
+		writeBaselineError(w, err)
+		return
+	}
+	s.baselines.ApplyAll(s.store)
+
+	stored, _ := s.baselines.Get(name)
+	json.NewEncoder(w).Encode(stored)
+}
+
+// deleteBaseline removes an API-defined baseline and re-evaluates all nodes
+func (s *Server) deleteBaseline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.baselines == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "baseline not found"})
+		return
+	}
+
+	if err := s.baselines.Delete(chi.URLParam(r, "name")); err != nil {
+		writeBaselineError(w, err)
+		return
+	}
+	s.baselines.ApplyAll(s.store)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeBaselineError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, baseline.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, baseline.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, baseline.ErrReadOnly):
+		status = http.StatusConflict
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// refreshRequest selects the subsystems to refresh; an empty body refreshes all
+type refreshRequest struct {
+	Subsystems []string `json:"subsystems"`
+}
+
+// decodeRefreshRequest reads a refresh request, writing an error response if it is invalid
+func (s *Server) decodeRefreshRequest(w http.ResponseWriter, r *http.Request) (refreshRequest, bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req refreshRequest
+	if s.poller == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "refresh not available"})
+		return req, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return req, false
+	}
+	return req, true
+}
+
+// writeRefresh responds to a refresh request with the queued refresh or the
+// reason it was refused
+func writeRefresh(w http.ResponseWriter, refresh *models.Refresh, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, poller.ErrInvalidSubsystem):
+			status = http.StatusBadRequest
+		case errors.Is(err, poller.ErrRefreshRateLimited):
+			status = http.StatusTooManyRequests
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(refresh)
+}
+
+// refreshNode queues an immediate poll of one node
+func (s *Server) refreshNode(w http.ResponseWriter, r *http.Request) {
+	node, ok := s.lookupNode(w, r)
+	if !ok {
+		return
+	}
+	req, ok := s.decodeRefreshRequest(w, r)
+	if !ok {
+		return
+	}
+
+	refresh, err := s.poller.RefreshNode(node.Namespace, node.Name, req.Subsystems)
+	writeRefresh(w, refresh, err)
+}
+
+// refreshAll queues an immediate poll of every node
+func (s *Server) refreshAll(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.decodeRefreshRequest(w, r)
+	if !ok {
+		return
+	}
+
+	refresh, err := s.poller.RefreshAll(req.Subsystems)
+	writeRefresh(w, refresh, err)
+}
+
+// getRefresh reports the progress of a refresh
+func (s *Server) getRefresh(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var refresh *models.Refresh
+	found := false
+	if s.poller != nil {
+		refresh, found = s.poller.Refresh(chi.URLParam(r, "id"))
+	}
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "refresh not found"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(refresh)
+}
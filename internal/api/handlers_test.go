@@ -2,13 +2,21 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/cragr/openshift-baremetal-insights/internal/baseline"
+	"github.com/cragr/openshift-baremetal-insights/internal/catalog"
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/poller"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
 	"github.com/cragr/openshift-baremetal-insights/internal/store"
+	"github.com/cragr/openshift-baremetal-insights/internal/updates"
 )
 
 func TestListNodesHandler(t *testing.T) {
@@ -80,6 +88,109 @@ func TestGetNodeHandler_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetNodeHandler_Namespaced(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-a", Model: "PowerEdge R640"})
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-b", Model: "PowerEdge R650"})
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "cluster-a", Model: "PowerEdge R640"})
+
+	es := store.NewEventStore(100)
+	es.AddEvents("cluster-a", "master-0", []models.HealthEvent{{ID: "1", Message: "Fan failed"}})
+	es.AddEvents("cluster-b", "master-0", []models.HealthEvent{{ID: "1", Message: "PSU failed"}})
+
+	srv := NewServerWithEvents(s, es, ":8080", "", "")
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		model  string
+	}{
+		{"namespaced", "/api/v1/namespaces/cluster-b/nodes/master-0/firmware", http.StatusOK, "PowerEdge R650"},
+		{"unique name", "/api/v1/nodes/worker-0/firmware", http.StatusOK, "PowerEdge R640"},
+		{"ambiguous name", "/api/v1/nodes/master-0/firmware", http.StatusConflict, ""},
+		{"wrong namespace", "/api/v1/namespaces/cluster-b/nodes/worker-0/firmware", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.model == "" {
+				return
+			}
+			var node models.Node
+			if err := json.NewDecoder(w.Body).Decode(&node); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if node.Model != tt.model {
+				t.Errorf("expected model %s, got %s", tt.model, node.Model)
+			}
+		})
+	}
+
+	// Events are attributed to the node in its own namespace
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/cluster-b/nodes/master-0/events", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	var response struct {
+		Events []models.HealthEvent `json:"events"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Events) != 1 || response.Events[0].Message != "PSU failed" {
+		t.Errorf("unexpected events: %+v", response.Events)
+	}
+}
+
+func TestGetNodeHandler_StaleAge(t *testing.T) {
+	s := store.New()
+	collected := time.Now().Add(-10 * time.Minute)
+	s.SetNode(models.Node{
+		Name:        "worker-0",
+		LastScanned: time.Now().Add(-5 * time.Minute),
+		Stale:       map[string]models.StaleData{"thermal": {CollectedAt: collected}},
+	})
+	srv := NewServer(s, ":8080", "", "")
+
+	// The age runs from the collection time to the request, not to the last poll
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/nodes/worker-0/firmware", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	var node models.Node
+	if err := json.NewDecoder(w.Body).Decode(&node); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if age := node.Stale["thermal"].AgeSeconds; age < 600 || age > 660 {
+		t.Errorf("expected thermal data about 600s old, got %v", age)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/nodes", nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	var response struct {
+		Nodes []models.Node `json:"nodes"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Nodes) != 1 || response.Nodes[0].Stale["thermal"].AgeSeconds < 600 {
+		t.Errorf("expected the node list to carry the age too, got %+v", response.Nodes)
+	}
+
+	// The stored node keeps only the collection time
+	stored, _ := s.GetNode("", "worker-0")
+	if stored.Stale["thermal"].AgeSeconds != 0 {
+		t.Errorf("expected the stored age to be left unset, got %+v", stored.Stale)
+	}
+}
+
 func TestHealthHandler(t *testing.T) {
 	s := store.New()
 	srv := NewServer(s, ":8080", "", "")
@@ -126,8 +237,9 @@ func TestListUpdatesHandler(t *testing.T) {
 		},
 	})
 	s.SetNode(models.Node{
-		Name:  "worker-1",
-		Model: "PowerEdge R640",
+		Name:      "worker-1",
+		Namespace: "cluster-a",
+		Model:     "PowerEdge R640",
 		Firmware: []models.FirmwareComponent{
 			{
 				ID:               "BIOS",
@@ -155,6 +267,7 @@ func TestListUpdatesHandler(t *testing.T) {
 			ComponentType    string   `json:"componentType"`
 			AvailableVersion string   `json:"availableVersion"`
 			AffectedNodes    []string `json:"affectedNodes"`
+			AffectedNodeRefs []string `json:"affectedNodeRefs"`
 		} `json:"updates"`
 	}
 	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
@@ -164,6 +277,20 @@ func TestListUpdatesHandler(t *testing.T) {
 	if len(response.Updates) == 0 {
 		t.Error("expected at least one update")
 	}
+	for _, update := range response.Updates {
+		if update.ComponentType != "BIOS" {
+			continue
+		}
+		// Names stay bare; refs qualify them with the namespace
+		slices.Sort(update.AffectedNodes)
+		slices.Sort(update.AffectedNodeRefs)
+		if !slices.Equal(update.AffectedNodes, []string{"worker-0", "worker-1"}) {
+			t.Errorf("unexpected affected nodes: %v", update.AffectedNodes)
+		}
+		if !slices.Equal(update.AffectedNodeRefs, []string{"cluster-a/worker-1", "worker-0"}) {
+			t.Errorf("unexpected affected node refs: %v", update.AffectedNodeRefs)
+		}
+	}
 }
 
 func TestGetNodeHealthHandler(t *testing.T) {
@@ -208,6 +335,57 @@ func TestGetNodeHealthHandler(t *testing.T) {
 	}
 }
 
+func TestGetNodePollStatusHandler(t *testing.T) {
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := models.NewPollReport(nil, started)
+	report.Record("firmware", nil)
+	report.Record("thermal", errors.New("thermal data not available"))
+	report.Finish(started.Add(2 * time.Second))
+
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a", PollReport: report, PollSummary: report.Summary()})
+	srv := NewServer(s, ":8080", "", "")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/ns-a/nodes/worker-0/pollstatus", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		PollStatus models.PollReport `json:"pollStatus"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	status := response.PollStatus
+	if status.OK || status.DurationSeconds != 2 || status.ConsecutiveFailures != 1 {
+		t.Errorf("unexpected poll status: %+v", status)
+	}
+	if thermal := status.Subsystems["thermal"]; thermal.OK || thermal.Error != "thermal data not available" || thermal.LastSuccess != nil {
+		t.Errorf("unexpected thermal status: %+v", thermal)
+	}
+
+	// The node list carries only the summary
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/nodes", nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	var list struct {
+		Nodes []map[string]json.RawMessage `json:"nodes"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list.Nodes) != 1 || list.Nodes[0]["pollSummary"] == nil || list.Nodes[0]["pollReport"] != nil {
+		t.Fatalf("expected only a poll summary in the node list, got %v", list.Nodes)
+	}
+	var summary models.PollSummary
+	if err := json.Unmarshal(list.Nodes[0]["pollSummary"], &summary); err != nil || len(summary.FailedSubsystems) != 1 || summary.FailedSubsystems[0] != "thermal" {
+		t.Errorf("unexpected summary %+v: %v", summary, err)
+	}
+}
+
 func TestListEventsHandler(t *testing.T) {
 	s := store.New()
 	es := store.NewEventStore(100)
@@ -241,6 +419,64 @@ func TestListEventsHandler(t *testing.T) {
 	}
 }
 
+func TestListEventsHandler_Query(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns"})
+	es := store.NewEventStore(100)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	es.AddEvents("ns", "worker-0", []models.HealthEvent{
+		{ID: "1", Timestamp: base, Severity: models.HealthWarning},
+		{ID: "2", Timestamp: base.Add(time.Hour), Severity: models.HealthCritical},
+		{ID: "3", Timestamp: base.Add(2 * time.Hour), Severity: models.HealthCritical},
+	})
+	srv := NewServerWithEvents(s, es, ":8080", "", "")
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantIDs    []string
+		wantCursor bool
+	}{
+		{name: "since", url: "/api/v1/events?since=2026-01-01T01:00:00Z", wantStatus: http.StatusOK, wantIDs: []string{"3", "2"}},
+		{name: "until", url: "/api/v1/events?until=2026-01-01T00:30:00Z", wantStatus: http.StatusOK, wantIDs: []string{"1"}},
+		{name: "severity", url: "/api/v1/events?severity=warning", wantStatus: http.StatusOK, wantIDs: []string{"1"}},
+		{name: "limit pages", url: "/api/v1/events?limit=2", wantStatus: http.StatusOK, wantIDs: []string{"3", "2"}, wantCursor: true},
+		{name: "node", url: "/api/v1/nodes/worker-0/events?severity=Critical&limit=1", wantStatus: http.StatusOK, wantIDs: []string{"3"}, wantCursor: true},
+		{name: "bad since", url: "/api/v1/events?since=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "bad severity", url: "/api/v1/events?severity=Bad", wantStatus: http.StatusBadRequest},
+		{name: "bad cursor", url: "/api/v1/nodes/worker-0/events?cursor=bad", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var page store.EventPage
+			if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, e := range page.Events {
+				ids = append(ids, e.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("events = %v, want %v", ids, tt.wantIDs)
+			}
+			if (page.NextCursor != "") != tt.wantCursor {
+				t.Errorf("nextCursor = %q, want cursor %v", page.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
 func TestServer_Dashboard(t *testing.T) {
 	s := store.New()
 	s.SetNode(models.Node{
@@ -341,6 +577,56 @@ func TestServer_ListNodes_NamespaceFilter(t *testing.T) {
 	}
 }
 
+func TestServer_ListNodes_RoleFilter(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "master-0", Namespace: "ns-a", KubeNode: &models.KubeNode{Name: "master-0", Roles: []string{"control-plane", "master"}}})
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a", KubeNode: &models.KubeNode{Name: "worker-0", Roles: []string{"worker"}}})
+	s.SetNode(models.Node{Name: "spare-0", Namespace: "ns-a"})
+
+	srv := NewServer(s, ":8080", "", "")
+
+	for role, want := range map[string]int{"master": 1, "worker": 1, "infra": 0, "": 3} {
+		req := httptest.NewRequest("GET", "/api/v1/nodes?role="+role, nil)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+
+		var resp struct {
+			Nodes []models.Node `json:"nodes"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Nodes) != want {
+			t.Errorf("role %q: nodes count = %d, want %d", role, len(resp.Nodes), want)
+		}
+	}
+}
+
+func TestServer_ListNodes_CertificateFilter(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a", BMCCertificate: &models.BMCCertificate{Status: models.CertificateVerified}})
+	s.SetNode(models.Node{Name: "worker-1", Namespace: "ns-a", BMCCertificate: &models.BMCCertificate{Status: models.CertificateUntrusted, SelfSigned: true}})
+	s.SetNode(models.Node{Name: "worker-2", Namespace: "ns-a"})
+
+	srv := NewServer(s, ":8080", "", "")
+
+	for status, want := range map[string]int{"verified": 1, "untrusted": 1, "rejected": 0, "": 3} {
+		req := httptest.NewRequest("GET", "/api/v1/nodes?certificate="+status, nil)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+
+		var resp struct {
+			Nodes []models.Node `json:"nodes"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Nodes) != want {
+			t.Errorf("certificate %q: nodes count = %d, want %d", status, len(resp.Nodes), want)
+		}
+	}
+}
+
 func TestServer_ListFirmware(t *testing.T) {
 	s := store.New()
 	s.SetNode(models.Node{
@@ -373,3 +659,281 @@ func TestServer_ListFirmware(t *testing.T) {
 		t.Errorf("summary.updatesAvailable = %v, want 1", summary["updatesAvailable"])
 	}
 }
+
+func TestServer_ScheduleUpdates_Unavailable(t *testing.T) {
+	srv := NewServerWithTasks(store.New(), nil, store.NewTaskStore(), ":8080", "", "")
+
+	body := strings.NewReader(`{"nodes":["node-1"],"mode":"OnReboot"}`)
+	req := httptest.NewRequest("POST", "/api/v1/updates/schedule", body)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestServer_ScheduleUpdates_BadRequest(t *testing.T) {
+	s := store.New()
+	ts := store.NewTaskStore()
+	srv := NewServerWithTasks(s, nil, ts, ":8080", "", "")
+	srv.SetUpdateScheduler(updates.NewScheduler(s, ts, catalog.NewService("http://example.com", time.Hour), nil, nil))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed", `{"nodes":`},
+		{"unknown node", `{"nodes":["missing"],"mode":"OnReboot"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/updates/schedule", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestServer_Dashboard_JobsSummary(t *testing.T) {
+	s := store.New()
+	ts := store.NewTaskStore()
+	ts.SetTask(models.Task{TaskID: "JID_1", TaskState: models.TaskPending})
+	ts.SetTask(models.Task{TaskID: "JID_2", TaskState: models.TaskRunning})
+	ts.SetTask(models.Task{TaskID: "JID_3", TaskState: models.TaskCompleted})
+
+	srv := NewServerWithTasks(s, nil, ts, ":8080", "", "")
+
+	req := httptest.NewRequest("GET", "/api/v1/dashboard", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	var resp models.DashboardStats
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp.JobsSummary.Pending != 1 || resp.JobsSummary.InProgress != 1 || resp.JobsSummary.Completed != 1 {
+		t.Errorf("jobsSummary = %+v, want 1 pending, 1 in progress, 1 completed", resp.JobsSummary)
+	}
+}
+
+func TestServer_CatalogStatus(t *testing.T) {
+	srv := NewServer(store.New(), ":8080", "", "")
+
+	req := httptest.NewRequest("GET", "/api/v1/catalog", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	srv.SetCatalog(catalog.NewService("http://example.com", time.Hour))
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var status catalog.Status
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.Source != "" || status.Verification.Verified {
+		t.Errorf("expected empty status before sync, got %+v", status)
+	}
+}
+
+func TestServer_Baselines(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{
+		Name:      "node-1",
+		Namespace: "ns-a",
+		Model:     "PowerEdge R640",
+		Firmware: []models.FirmwareComponent{
+			{ID: "bios", Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.18.1", AvailableVersion: "2.20.0"},
+			{ID: "idrac", Name: "iDRAC", ComponentType: "Firmware", CurrentVersion: "7.0", AvailableVersion: "7.10"},
+		},
+	})
+
+	srv := NewServer(s, ":8080", "", "")
+	srv.SetBaselines(baseline.NewManager())
+
+	body := `{"release":"4.16","namespaces":["ns-a"],"targets":[{"model":"R640","componentType":"BIOS","version":"2.19.1"}]}`
+	req := httptest.NewRequest("PUT", "/api/v1/baselines/ocp-4.16", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("put status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	// Nodes are re-evaluated against the new baseline immediately
+	node, _ := s.GetNode("ns-a", "node-1")
+	if node.Baseline != "ocp-4.16" || node.Compliance != models.ComplianceBehind || node.UpdatesAvailable != 1 {
+		t.Errorf("unexpected evaluation: baseline=%q compliance=%q updates=%d", node.Baseline, node.Compliance, node.UpdatesAvailable)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/firmware", nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	summary := resp["summary"].(map[string]interface{})
+	if summary["behind"].(float64) != 1 || summary["updatesAvailable"].(float64) != 1 {
+		t.Errorf("summary = %v, want 1 behind and 1 update", summary)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/dashboard", nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	var stats models.DashboardStats
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if stats.ComplianceSummary.Behind != 1 || stats.UpdatesSummary.Total != 1 {
+		t.Errorf("dashboard = %+v, want 1 node behind and 1 update", stats)
+	}
+
+	req = httptest.NewRequest("PUT", "/api/v1/baselines/other", strings.NewReader(`{"name":"ocp-4.16","targets":[]}`))
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("mismatched name status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/v1/baselines/ocp-4.16", nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	node, _ = s.GetNode("ns-a", "node-1")
+	if node.Baseline != "" || node.UpdatesAvailable != 2 {
+		t.Errorf("expected catalog freshness after delete, got baseline=%q updates=%d", node.Baseline, node.UpdatesAvailable)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/baselines/ocp-4.16", nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("get deleted status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestServer_Refresh(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a"})
+	s.SetNode(models.Node{Name: "worker-1", Namespace: "ns-a"})
+	srv := NewServer(s, ":8080", "", "")
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("/api/v1/refresh", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d without a poller", w.Code, http.StatusServiceUnavailable)
+	}
+
+	// The poller is not started, so refreshes stay queued
+	srv.SetPoller(poller.New(nil, redfish.NewClient(), s, nil, nil, time.Hour))
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantNodes  int
+	}{
+		{"node", "/api/v1/nodes/worker-0/refresh", `{"subsystems":["firmware"]}`, http.StatusAccepted, 1},
+		{"node in namespace without body", "/api/v1/namespaces/ns-a/nodes/worker-0/refresh", "", http.StatusAccepted, 1},
+		{"fleet", "/api/v1/refresh", `{"subsystems":["health"]}`, http.StatusAccepted, 2},
+		{"unknown node", "/api/v1/nodes/missing/refresh", "", http.StatusNotFound, 0},
+		{"unknown subsystem", "/api/v1/refresh", `{"subsystems":["fans"]}`, http.StatusBadRequest, 0},
+		{"malformed", "/api/v1/refresh", `{"subsystems":`, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := post(tt.path, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+
+			var refresh models.Refresh
+			if err := json.NewDecoder(w.Body).Decode(&refresh); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if refresh.ID == "" || len(refresh.Nodes) != tt.wantNodes {
+				t.Fatalf("unexpected refresh: %+v", refresh)
+			}
+
+			req := httptest.NewRequest("GET", "/api/v1/refresh/"+refresh.ID, nil)
+			w = httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("get status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if err := json.NewDecoder(w.Body).Decode(&refresh); err != nil || refresh.State != models.RefreshQueued {
+				t.Errorf("expected queued refresh, got %+v (%v)", refresh, err)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/refresh/unknown", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d for unknown refresh", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestServer_Changes(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a"})
+	cs := store.NewChangeStore(100)
+	cs.AddChanges([]models.ChangeEvent{
+		{Type: models.ChangeHealth, NodeName: "worker-0", Namespace: "ns-a", Message: "Health changed from OK to Warning"},
+		{Type: models.ChangeFirmwareVersion, NodeName: "worker-0", Namespace: "ns-a", Message: "BIOS firmware changed"},
+		{Type: models.ChangeHealth, NodeName: "worker-1", Namespace: "ns-a", Message: "Health changed from OK to Critical"},
+	})
+	srv := NewServer(s, ":8080", "", "")
+	srv.SetChangeStore(cs)
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"all", "/api/v1/changes", 3},
+		{"by type", "/api/v1/changes?type=health-changed", 2},
+		{"limited", "/api/v1/changes?limit=1", 1},
+		{"node", "/api/v1/nodes/worker-0/changes", 2},
+		{"node by type", "/api/v1/namespaces/ns-a/nodes/worker-0/changes?type=firmware-changed", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var resp struct {
+				Changes []models.ChangeEvent `json:"changes"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(resp.Changes) != tt.want {
+				t.Errorf("expected %d changes, got %+v", tt.want, resp.Changes)
+			}
+		})
+	}
+}
@@ -11,7 +11,11 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/cragr/openshift-baremetal-insights/internal/baseline"
+	"github.com/cragr/openshift-baremetal-insights/internal/catalog"
+	"github.com/cragr/openshift-baremetal-insights/internal/poller"
 	"github.com/cragr/openshift-baremetal-insights/internal/store"
+	"github.com/cragr/openshift-baremetal-insights/internal/updates"
 )
 
 // Server is the REST API server
@@ -19,6 +23,11 @@ type Server struct {
 	store      *store.Store
 	eventStore *store.EventStore
 	taskStore  *store.TaskStore
+	changes    *store.ChangeStore
+	updater    *updates.Scheduler
+	catalog    *catalog.Service
+	baselines  *baseline.Manager
+	poller     *poller.Poller
 	router     *chi.Mux
 	addr       string
 	server     *http.Server
@@ -33,92 +42,100 @@ func NewServer(s *store.Store, addr, certFile, keyFile string) *Server {
 
 // NewServerWithEvents creates a new API server with event store
 func NewServerWithEvents(s *store.Store, es *store.EventStore, addr, certFile, keyFile string) *Server {
+	return NewServerWithTasks(s, es, nil, addr, certFile, keyFile)
+}
+
+// NewServerWithTasks creates a new API server with all stores
+func NewServerWithTasks(s *store.Store, es *store.EventStore, ts *store.TaskStore, addr, certFile, keyFile string) *Server {
 	srv := &Server{
 		store:      s,
 		eventStore: es,
+		taskStore:  ts,
 		addr:       addr,
 		certFile:   certFile,
 		keyFile:    keyFile,
 	}
+	srv.router = srv.routes()
+	return srv
+}
 
-	r := chi.NewRouter()
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.RequestID)
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*"},
-		AllowedMethods:   []string{"GET", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Content-Type"},
-		AllowCredentials: false,
-		MaxAge:           300,
-	}))
+// SetUpdateScheduler enables the firmware update scheduling endpoint
+func (s *Server) SetUpdateScheduler(u *updates.Scheduler) {
+	s.updater = u
+}
 
-	r.Route("/api/v1", func(r chi.Router) {
-		r.Get("/nodes", srv.listNodes)
-		r.Get("/nodes/{name}/firmware", srv.getNodeFirmware)
-		r.Get("/nodes/{name}/health", srv.getNodeHealth)
-		r.Get("/nodes/{name}/thermal", srv.getNodeThermal)
-		r.Get("/nodes/{name}/power", srv.getNodePower)
-		r.Get("/nodes/{name}/events", srv.getNodeEvents)
-		r.Get("/updates", srv.listUpdates)
-		r.Get("/events", srv.listEvents)
-		r.Get("/health", srv.health)
-		r.Get("/dashboard", srv.dashboard)
-		r.Get("/namespaces", srv.listNamespaces)
-		r.Get("/firmware", srv.listFirmware)
-	})
+// SetCatalog enables reporting of the catalog source and verification status
+func (s *Server) SetCatalog(c *catalog.Service) {
+	s.catalog = c
+}
 
-	r.Handle("/metrics", promhttp.Handler())
-	r.HandleFunc("/healthz", healthzHandler)
+// SetBaselines enables the firmware baseline endpoints
+func (s *Server) SetBaselines(m *baseline.Manager) {
+	s.baselines = m
+}
 
-	srv.router = r
-	return srv
+// SetChangeStore enables the change event endpoints
+func (s *Server) SetChangeStore(cs *store.ChangeStore) {
+	s.changes = cs
 }
 
-// NewServerWithTasks creates a new API server with all stores
-func NewServerWithTasks(s *store.Store, es *store.EventStore, ts *store.TaskStore, addr, certFile, keyFile string) *Server {
-	srv := &Server{
-		store:      s,
-		eventStore: es,
-		taskStore:  ts,
-		addr:       addr,
-		certFile:   certFile,
-		keyFile:    keyFile,
-	}
+// SetPoller enables the on-demand refresh endpoints
+func (s *Server) SetPoller(p *poller.Poller) {
+	s.poller = p
+}
 
+func (s *Server) routes() *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*"},
-		AllowedMethods:   []string{"GET", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Content-Type"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Get("/nodes", srv.listNodes)
-		r.Get("/nodes/{name}/firmware", srv.getNodeFirmware)
-		r.Get("/nodes/{name}/health", srv.getNodeHealth)
-		r.Get("/nodes/{name}/thermal", srv.getNodeThermal)
-		r.Get("/nodes/{name}/power", srv.getNodePower)
-		r.Get("/nodes/{name}/events", srv.getNodeEvents)
-		r.Get("/updates", srv.listUpdates)
-		r.Get("/events", srv.listEvents)
-		r.Get("/health", srv.health)
-		r.Get("/dashboard", srv.dashboard)
-		r.Get("/namespaces", srv.listNamespaces)
-		r.Get("/tasks", srv.listTasks)
-		r.Get("/firmware", srv.listFirmware)
+		r.Get("/nodes", s.listNodes)
+		// Name-only node routes work while the name is unique across namespaces
+		r.Route("/nodes/{name}", s.nodeRoutes)
+		r.Route("/namespaces/{namespace}/nodes/{name}", s.nodeRoutes)
+		r.Get("/updates", s.listUpdates)
+		r.Post("/updates/schedule", s.scheduleUpdates)
+		r.Get("/events", s.listEvents)
+		r.Get("/changes", s.listChanges)
+		r.Get("/health", s.health)
+		r.Get("/dashboard", s.dashboard)
+		r.Get("/namespaces", s.listNamespaces)
+		r.Get("/tasks", s.listTasks)
+		r.Get("/firmware", s.listFirmware)
+		r.Get("/catalog", s.getCatalogStatus)
+		r.Get("/baselines", s.listBaselines)
+		r.Get("/baselines/{name}", s.getBaseline)
+		r.Put("/baselines/{name}", s.putBaseline)
+		r.Delete("/baselines/{name}", s.deleteBaseline)
+		r.Post("/refresh", s.refreshAll)
+		r.Get("/refresh/{id}", s.getRefresh)
 	})
 
 	r.Handle("/metrics", promhttp.Handler())
 	r.HandleFunc("/healthz", healthzHandler)
 
-	srv.router = r
-	return srv
+	return r
+}
+
+// nodeRoutes registers the per-node endpoints
+func (s *Server) nodeRoutes(r chi.Router) {
+	r.Get("/firmware", s.getNodeFirmware)
+	r.Get("/health", s.getNodeHealth)
+	r.Get("/thermal", s.getNodeThermal)
+	r.Get("/power", s.getNodePower)
+	r.Get("/events", s.getNodeEvents)
+	r.Get("/changes", s.getNodeChanges)
+	r.Get("/pollstatus", s.getNodePollStatus)
+	r.Post("/refresh", s.refreshNode)
 }
 
 func healthzHandler(w http.ResponseWriter, r *http.Request) {
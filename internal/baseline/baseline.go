@@ -0,0 +1,188 @@
+// Package baseline evaluates nodes against certified firmware baselines: named
+// sets of target versions per hardware model and component, assigned to nodes by
+// name or namespace.
+package baseline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/version"
+)
+
+// Baseline sources reported in Baseline.Source
+const (
+	SourceConfigMap = "configmap"
+	SourceAPI       = "api"
+)
+
+// ErrInvalid is returned for baselines that fail validation
+var ErrInvalid = errors.New("invalid baseline")
+
+// Baseline pins target firmware versions for the nodes it is assigned to. A
+// baseline listing Nodes applies to those nodes, one listing Namespaces to every
+// node in them, and one listing neither is the default for all other nodes.
+type Baseline struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Release     string   `json:"release,omitempty"` // OpenShift release the baseline is certified for
+	Nodes       []string `json:"nodes,omitempty"`   // "namespace/name", or a name in any namespace
+	Namespaces  []string `json:"namespaces,omitempty"`
+	Targets     []Target `json:"targets"`
+	Source      string   `json:"source,omitempty"`
+}
+
+// Target is the certified version of a component on a hardware model. Model and
+// the component selectors are optional; the most specific matching target wins.
+type Target struct {
+	Model         string `json:"model,omitempty"`         // e.g. "PowerEdge R640" or "R640"
+	ComponentType string `json:"componentType,omitempty"` // e.g. "BIOS", "Firmware"
+	Name          string `json:"name,omitempty"`          // substring of the component name, e.g. "iDRAC"
+	ComponentID   string `json:"componentId,omitempty"`   // vendor component ID
+	Version       string `json:"version"`
+}
+
+// Validate checks that a baseline is usable
+func (b *Baseline) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if strings.ContainsAny(b.Name, "/ ") {
+		return fmt.Errorf("%w: name %q must not contain spaces or slashes", ErrInvalid, b.Name)
+	}
+	for i, t := range b.Targets {
+		if t.Version == "" {
+			return fmt.Errorf("%w: target %d has no version", ErrInvalid, i)
+		}
+		if t.ComponentType == "" && t.Name == "" && t.ComponentID == "" {
+			return fmt.Errorf("%w: target %d must select a componentType, name or componentId", ErrInvalid, i)
+		}
+	}
+	return nil
+}
+
+// specificity ranks how closely a baseline is scoped to a node: 0 means it does
+// not apply at all
+func (b *Baseline) specificity(node *models.Node) int {
+	for _, name := range b.Nodes {
+		if name == node.Key() || name == node.Name {
+			return 3
+		}
+	}
+	for _, ns := range b.Namespaces {
+		if ns == node.Namespace {
+			return 2
+		}
+	}
+	if len(b.Nodes) == 0 && len(b.Namespaces) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// target returns the most specific target for a component on the given model
+func (b *Baseline) target(model string, fw *models.FirmwareComponent) (Target, bool) {
+	var best Target
+	bestScore := 0
+	for _, t := range b.Targets {
+		score := t.score(model, fw)
+		if score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// score returns how specifically t matches a component, or 0 if it does not
+func (t Target) score(model string, fw *models.FirmwareComponent) int {
+	score := 1
+	if t.Model != "" {
+		if modelKey(t.Model) != modelKey(model) {
+			return 0
+		}
+		score += 8
+	}
+	if t.ComponentID != "" {
+		if t.ComponentID != fw.Identity.ComponentID {
+			return 0
+		}
+		score += 4
+	}
+	if t.Name != "" {
+		if !strings.Contains(strings.ToLower(fw.Name), strings.ToLower(t.Name)) {
+			return 0
+		}
+		score += 2
+	}
+	if t.ComponentType != "" {
+		if !strings.EqualFold(t.ComponentType, fw.ComponentType) {
+			return 0
+		}
+		score++
+	}
+	return score
+}
+
+// modelKey normalises a model name so "PowerEdge R640" and "r640" compare equal
+func modelKey(model string) string {
+	m := strings.ToUpper(strings.TrimSpace(model))
+	return strings.TrimPrefix(m, "POWEREDGE ")
+}
+
+// Apply evaluates a node's firmware against b, setting each targeted component's
+// TargetVersion and Compliance and the node's Baseline and Compliance rollup.
+// A nil baseline clears any previous evaluation.
+func Apply(node *models.Node, b *Baseline) {
+	node.Baseline = ""
+	node.Compliance = ""
+	for i := range node.Firmware {
+		node.Firmware[i].TargetVersion = ""
+		node.Firmware[i].Compliance = ""
+	}
+	if b == nil {
+		return
+	}
+
+	node.Baseline = b.Name
+	counts := make(map[models.ComplianceStatus]int)
+	for i := range node.Firmware {
+		fw := &node.Firmware[i]
+		t, ok := b.target(node.Model, fw)
+		if !ok {
+			continue
+		}
+		fw.TargetVersion = t.Version
+		fw.Compliance = compare(fw.CurrentVersion, t.Version)
+		counts[fw.Compliance]++
+	}
+
+	switch {
+	case counts[models.ComplianceBehind] > 0:
+		node.Compliance = models.ComplianceBehind
+	case counts[models.ComplianceUnknown] > 0:
+		node.Compliance = models.ComplianceUnknown
+	case counts[models.ComplianceAhead] > 0:
+		node.Compliance = models.ComplianceAhead
+	case counts[models.ComplianceCompliant] > 0:
+		node.Compliance = models.ComplianceCompliant
+	default:
+		// No installed component is covered by the baseline
+		node.Compliance = models.ComplianceUnknown
+	}
+}
+
+// compare classifies an installed version against a target version
+func compare(current, target string) models.ComplianceStatus {
+	switch version.Compare(current, target) {
+	case version.Equal:
+		return models.ComplianceCompliant
+	case version.Less:
+		return models.ComplianceBehind
+	case version.Greater:
+		return models.ComplianceAhead
+	default:
+		return models.ComplianceUnknown
+	}
+}
@@ -0,0 +1,131 @@
+package baseline
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func TestApply(t *testing.T) {
+	b := &Baseline{
+		Name: "ocp-4.16",
+		Targets: []Target{
+			{ComponentType: "BIOS", Version: "2.18.0"},
+			{Model: "R640", ComponentType: "BIOS", Version: "2.19.1"},
+			{Model: "PowerEdge R640", Name: "iDRAC", Version: "7.00.00.00"},
+			{ComponentType: "RAID", Version: "51.16.0"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		firmware   []models.FirmwareComponent
+		compliance models.ComplianceStatus
+		targets    []string
+		statuses   []models.ComplianceStatus
+	}{
+		{
+			name: "compliant uses most specific target",
+			firmware: []models.FirmwareComponent{
+				{Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.19.1"},
+				{Name: "Integrated Dell Remote Access Controller (iDRAC)", ComponentType: "Firmware", CurrentVersion: "7.0"},
+				{Name: "NIC", ComponentType: "NIC", CurrentVersion: "22.5.7"},
+			},
+			compliance: models.ComplianceCompliant,
+			targets:    []string{"2.19.1", "7.00.00.00", ""},
+			statuses:   []models.ComplianceStatus{models.ComplianceCompliant, models.ComplianceCompliant, ""},
+		},
+		{
+			name: "behind wins over ahead",
+			firmware: []models.FirmwareComponent{
+				{Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.20.0"},
+				{Name: "PERC H740P", ComponentType: "RAID", CurrentVersion: "51.15.0"},
+			},
+			compliance: models.ComplianceBehind,
+			targets:    []string{"2.19.1", "51.16.0"},
+			statuses:   []models.ComplianceStatus{models.ComplianceAhead, models.ComplianceBehind},
+		},
+		{
+			name: "ahead",
+			firmware: []models.FirmwareComponent{
+				{Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.20.0"},
+			},
+			compliance: models.ComplianceAhead,
+			targets:    []string{"2.19.1"},
+			statuses:   []models.ComplianceStatus{models.ComplianceAhead},
+		},
+		{
+			name: "nothing targeted",
+			firmware: []models.FirmwareComponent{
+				{Name: "NIC", ComponentType: "NIC", CurrentVersion: "22.5.7"},
+			},
+			compliance: models.ComplianceUnknown,
+			targets:    []string{""},
+			statuses:   []models.ComplianceStatus{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &models.Node{Name: "worker-0", Model: "PowerEdge R640", Firmware: tt.firmware}
+			Apply(node, b)
+
+			if node.Baseline != "ocp-4.16" {
+				t.Errorf("expected baseline ocp-4.16, got %q", node.Baseline)
+			}
+			if node.Compliance != tt.compliance {
+				t.Errorf("expected compliance %q, got %q", tt.compliance, node.Compliance)
+			}
+			for i, fw := range node.Firmware {
+				if fw.TargetVersion != tt.targets[i] || fw.Compliance != tt.statuses[i] {
+					t.Errorf("%s: expected %q/%q, got %q/%q", fw.Name, tt.targets[i], tt.statuses[i], fw.TargetVersion, fw.Compliance)
+				}
+			}
+		})
+	}
+}
+
+func TestApply_Nil(t *testing.T) {
+	node := &models.Node{
+		Baseline:   "old",
+		Compliance: models.ComplianceBehind,
+		Firmware: []models.FirmwareComponent{
+			{Name: "BIOS", TargetVersion: "2.19.1", Compliance: models.ComplianceBehind},
+		},
+	}
+	Apply(node, nil)
+
+	if node.Baseline != "" || node.Compliance != "" {
+		t.Errorf("expected baseline cleared, got %q/%q", node.Baseline, node.Compliance)
+	}
+	if node.Firmware[0].TargetVersion != "" || node.Firmware[0].Compliance != "" {
+		t.Errorf("expected component evaluation cleared, got %+v", node.Firmware[0])
+	}
+}
+
+func TestBaseline_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline Baseline
+		valid    bool
+	}{
+		{"valid", Baseline{Name: "a", Targets: []Target{{ComponentType: "BIOS", Version: "1.0"}}}, true},
+		{"no name", Baseline{Targets: []Target{{ComponentType: "BIOS", Version: "1.0"}}}, false},
+		{"slash in name", Baseline{Name: "a/b"}, false},
+		{"no version", Baseline{Name: "a", Targets: []Target{{ComponentType: "BIOS"}}}, false},
+		{"no component selector", Baseline{Name: "a", Targets: []Target{{Model: "R640", Version: "1.0"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.baseline.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalid) {
+				t.Errorf("expected ErrInvalid, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package baseline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+)
+
+// stateFile holds API-defined baselines in the state directory
+const stateFile = "baselines.json"
+
+var (
+	// ErrNotFound is returned when a baseline does not exist
+	ErrNotFound = errors.New("baseline not found")
+	// ErrReadOnly is returned when the API tries to change a ConfigMap-defined baseline
+	ErrReadOnly = errors.New("baseline is managed by ConfigMap")
+)
+
+// Manager holds the baselines defined in a ConfigMap and through the API and
+// assigns them to nodes
+type Manager struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	stateDir  string
+
+	mu        sync.RWMutex
+	configMap map[string]Baseline
+	api       map[string]Baseline
+}
+
+// NewManager creates a Manager with no baselines
+func NewManager() *Manager {
+	return &Manager{
+		configMap: make(map[string]Baseline),
+		api:       make(map[string]Baseline),
+	}
+}
+
+// SetConfigMap reads baselines from a ConfigMap on every Refresh. Each data key
+// holds one baseline in YAML or JSON; its name defaults to the key without extension.
+func (m *Manager) SetConfigMap(client kubernetes.Interface, namespace, name string) {
+	m.client = client
+	m.namespace = namespace
+	m.name = name
+}
+
+// SetStateDir enables persisting API-defined baselines to dir so they survive restarts; see Load
+func (m *Manager) SetStateDir(dir string) {
+	m.stateDir = dir
+}
+
+// Load restores API-defined baselines persisted by a previous run. It is a no-op
+// if persistence is disabled or nothing has been persisted yet.
+func (m *Manager) Load() error {
+	if m.stateDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.stateDir, stateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read baselines: %w", err)
+	}
+
+	var baselines []Baseline
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return fmt.Errorf("failed to decode baselines: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range baselines {
+		b.Source = SourceAPI
+		m.api[b.Name] = b
+	}
+	return nil
+}
+
+// Refresh re-reads the ConfigMap, if configured. A missing ConfigMap removes its
+// baselines; invalid entries are skipped and reported in the returned error.
+func (m *Manager) Refresh(ctx context.Context) error {
+	if m.client == nil {
+		return nil
+	}
+
+	cm, err := m.client.CoreV1().ConfigMaps(m.namespace).Get(ctx, m.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		m.mu.Lock()
+		m.configMap = make(map[string]Baseline)
+		m.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get configmap %s/%s: %w", m.namespace, m.name, err)
+	}
+
+	baselines := make(map[string]Baseline, len(cm.Data))
+	var errs []error
+	for key, value := range cm.Data {
+		var b Baseline
+		if err := yaml.Unmarshal([]byte(value), &b); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if b.Name == "" {
+			b.Name = strings.TrimSuffix(key, filepath.Ext(key))
+		}
+		if err := b.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		b.Source = SourceConfigMap
+		baselines[b.Name] = b
+	}
+
+	m.mu.Lock()
+	m.configMap = baselines
+	m.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// List returns all baselines sorted by name
+func (m *Manager) List() []Baseline {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	baselines := make([]Baseline, 0, len(m.configMap)+len(m.api))
+	for _, b := range m.configMap {
+		baselines = append(baselines, b)
+	}
+	for name, b := range m.api {
+		if _, shadowed := m.configMap[name]; !shadowed {
+			baselines = append(baselines, b)
+		}
+	}
+	sort.Slice(baselines, func(i, j int) bool { return baselines[i].Name < baselines[j].Name })
+	return baselines
+}
+
+// Get returns a baseline by name
+func (m *Manager) Get(name string) (Baseline, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if b, ok := m.configMap[name]; ok {
+		return b, true
+	}
+	b, ok := m.api[name]
+	return b, ok
+}
+
+// Put creates or replaces an API-defined baseline
+func (m *Manager) Put(b Baseline) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	b.Source = SourceAPI
+
+	m.mu.Lock()
+	if _, ok := m.configMap[b.Name]; ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrReadOnly, b.Name)
+	}
+	m.api[b.Name] = b
+	m.mu.Unlock()
+
+	m.persist()
+	return nil
+}
+
+// Delete removes an API-defined baseline
+func (m *Manager) Delete(name string) error {
+	m.mu.Lock()
+	if _, ok := m.configMap[name]; ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrReadOnly, name)
+	}
+	if _, ok := m.api[name]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(m.api, name)
+	m.mu.Unlock()
+
+	m.persist()
+	return nil
+}
+
+// persist writes API-defined baselines to the state directory, if enabled
+func (m *Manager) persist() {
+	if m.stateDir == "" {
+		return
+	}
+
+	m.mu.RLock()
+	baselines := make([]Baseline, 0, len(m.api))
+	for _, b := range m.api {
+		baselines = append(baselines, b)
+	}
+	m.mu.RUnlock()
+	sort.Slice(baselines, func(i, j int) bool { return baselines[i].Name < baselines[j].Name })
+
+	if err := writeState(m.stateDir, baselines); err != nil {
+		log.Printf("Failed to persist baselines: %v", err)
+	}
+}
+
+// writeState atomically replaces the persisted baselines in dir
+func writeState(dir string, baselines []Baseline) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create baseline state dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(baselines, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baselines: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, stateFile+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create baseline state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write baseline state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write baseline state: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, stateFile)); err != nil {
+		return fmt.Errorf("failed to replace baseline state: %w", err)
+	}
+	return nil
+}
+
+// For returns the baseline assigned to a node: one naming the node, else one
+// naming its namespace, else a default. Ties go to the first name alphabetically.
+func (m *Manager) For(node *models.Node) (Baseline, bool) {
+	var best Baseline
+	bestScore := 0
+	for _, b := range m.List() {
+		if score := b.specificity(node); score > bestScore {
+			best, bestScore = b, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// Evaluate applies the node's assigned baseline, or clears a previous evaluation
+// if it no longer has one
+func (m *Manager) Evaluate(node *models.Node) {
+	if b, ok := m.For(node); ok {
+		Apply(node, &b)
+		return
+	}
+	Apply(node, nil)
+}
+
+// ApplyAll re-evaluates every stored node, e.g. after a baseline changed
+func (m *Manager) ApplyAll(nodes *store.Store) {
+	for _, node := range nodes.ListNodes() {
+		if len(node.Firmware) == 0 {
+			continue
+		}
+		// Copy so readers of the stored node never see a partial update
+		node.Firmware = slices.Clone(node.Firmware)
+		m.Evaluate(&node)
+		node.RefreshFirmwareStatus()
+		nodes.SetNode(node)
+	}
+}
@@ -0,0 +1,166 @@
+package baseline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+)
+
+func TestManager_Refresh(t *testing.T) {
+	client := fake.NewClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "baselines", Namespace: "insights"},
+		Data: map[string]string{
+			"ocp-4.16.yaml": `
+release: "4.16"
+namespaces: [cluster-a]
+targets:
+  - model: PowerEdge R640
+    componentType: BIOS
+    version: 2.19.1
+`,
+			"broken.yaml": `targets: [{model: R640}]`,
+		},
+	})
+
+	m := NewManager()
+	m.SetConfigMap(client, "insights", "baselines")
+
+	err := m.Refresh(context.Background())
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected invalid baseline to be reported, got %v", err)
+	}
+
+	b, ok := m.Get("ocp-4.16")
+	if !ok {
+		t.Fatal("expected baseline named after its key")
+	}
+	if b.Source != SourceConfigMap || b.Release != "4.16" || len(b.Targets) != 1 {
+		t.Errorf("unexpected baseline: %+v", b)
+	}
+	if _, ok := m.Get("broken"); ok {
+		t.Error("invalid baseline should be skipped")
+	}
+
+	// ConfigMap baselines cannot be changed through the API
+	if err := m.Put(Baseline{Name: "ocp-4.16", Targets: b.Targets}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+	if err := m.Delete("ocp-4.16"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestManager_RefreshMissingConfigMap(t *testing.T) {
+	m := NewManager()
+	m.SetConfigMap(fake.NewClientset(), "insights", "baselines")
+
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.List()) != 0 {
+		t.Error("expected no baselines")
+	}
+}
+
+func TestManager_For(t *testing.T) {
+	m := NewManager()
+	targets := []Target{{ComponentType: "BIOS", Version: "1.0"}}
+	for _, b := range []Baseline{
+		{Name: "default", Targets: targets},
+		{Name: "cluster-a", Namespaces: []string{"ns-a"}, Targets: targets},
+		{Name: "canary", Nodes: []string{"worker-0"}, Targets: targets},
+		{Name: "pilot", Nodes: []string{"ns-b/worker-3"}, Targets: targets},
+	} {
+		if err := m.Put(b); err != nil {
+			t.Fatalf("put %s: %v", b.Name, err)
+		}
+	}
+
+	tests := []struct {
+		node     models.Node
+		baseline string
+	}{
+		{models.Node{Name: "worker-0", Namespace: "ns-a"}, "canary"},
+		{models.Node{Name: "worker-1", Namespace: "ns-a"}, "cluster-a"},
+		{models.Node{Name: "worker-2", Namespace: "ns-b"}, "default"},
+		{models.Node{Name: "worker-3", Namespace: "ns-b"}, "pilot"},
+		{models.Node{Name: "worker-3", Namespace: "ns-c"}, "default"},
+	}
+	for _, tt := range tests {
+		b, ok := m.For(&tt.node)
+		if !ok || b.Name != tt.baseline {
+			t.Errorf("%s: expected %s, got %q", tt.node.Name, tt.baseline, b.Name)
+		}
+	}
+
+	if err := m.Delete("default"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok := m.For(&models.Node{Name: "worker-2", Namespace: "ns-b"}); ok {
+		t.Error("expected no baseline after default was deleted")
+	}
+}
+
+func TestManager_PersistAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	m := NewManager()
+	m.SetStateDir(dir)
+	if err := m.Put(Baseline{Name: "ocp-4.16", Targets: []Target{{ComponentType: "BIOS", Version: "2.19.1"}}}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	restored := NewManager()
+	restored.SetStateDir(dir)
+	if err := restored.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	b, ok := restored.Get("ocp-4.16")
+	if !ok || b.Source != SourceAPI || b.Targets[0].Version != "2.19.1" {
+		t.Errorf("unexpected restored baseline: %+v", b)
+	}
+}
+
+func TestManager_ApplyAll(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{
+		Name:  "worker-0",
+		Model: "PowerEdge R640",
+		Firmware: []models.FirmwareComponent{
+			// The catalog has a newer BIOS, but the baseline pins the installed one
+			{Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.19.1", AvailableVersion: "2.20.0"},
+			{Name: "iDRAC", ComponentType: "Firmware", CurrentVersion: "6.10.30.00", AvailableVersion: "7.00.00.00"},
+		},
+	})
+
+	m := NewManager()
+	if err := m.Put(Baseline{Name: "ocp-4.16", Targets: []Target{{ComponentType: "BIOS", Version: "2.19.1"}}}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	m.ApplyAll(s)
+
+	node, _ := s.GetNode("", "worker-0")
+	if node.Compliance != models.ComplianceCompliant {
+		t.Errorf("expected compliant, got %q", node.Compliance)
+	}
+	if node.UpdatesAvailable != 0 || node.Status != models.StatusUpToDate {
+		t.Errorf("expected no updates against baseline, got %d (%s)", node.UpdatesAvailable, node.Status)
+	}
+
+	if err := m.Delete("ocp-4.16"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	m.ApplyAll(s)
+
+	node, _ = s.GetNode("", "worker-0")
+	if node.Baseline != "" || node.UpdatesAvailable != 2 {
+		t.Errorf("expected catalog freshness without baseline, got %q with %d updates", node.Baseline, node.UpdatesAvailable)
+	}
+}
@@ -1,16 +1,20 @@
 package catalog
 
 import (
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/version"
 )
 
 // Cache stores catalog entries with TTL
 type Cache struct {
 	mu        sync.RWMutex
 	entries   map[string]models.CatalogEntry // key: "model|componentType"
+	bySystem  map[string][]models.CatalogEntry
+	all       []models.CatalogEntry
 	updatedAt time.Time
 	ttl       time.Duration
 }
@@ -25,24 +29,59 @@ func NewCache(ttl time.Duration) *Cache {
 
 // Set updates the cache with new entries
 func (c *Cache) Set(entries []models.CatalogEntry) {
+	c.SetAt(entries, time.Now())
+}
+
+// SetAt updates the cache with entries fetched at the given time, e.g. when
+// restoring a persisted catalog
+func (c *Cache) SetAt(entries []models.CatalogEntry, updatedAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.entries = make(map[string]models.CatalogEntry)
+	c.bySystem = make(map[string][]models.CatalogEntry)
 	for _, entry := range entries {
+		// Index packages with device data by system for identity matching
+		if len(entry.Devices) > 0 {
+			if entry.SystemID != "" {
+				key := systemIDKey(entry.SystemID)
+				c.bySystem[key] = append(c.bySystem[key], entry)
+			}
+			if entry.SystemModelID != "" {
+				key := systemModelKey(entry.SystemModelID)
+				c.bySystem[key] = append(c.bySystem[key], entry)
+			}
+		}
+
 		key := models.CatalogKey(entry.SystemModelID, entry.ComponentType)
 		// Keep the latest version if multiple exist
 		if existing, ok := c.entries[key]; ok {
-			if entry.Version > existing.Version {
+			if version.Newer(entry.Version, existing.Version) {
 				c.entries[key] = entry
 			}
 		} else {
 			c.entries[key] = entry
 		}
 	}
+	c.all = entries
+	c.updatedAt = updatedAt
+}
+
+// Touch marks the cached entries as current without replacing them, e.g. after
+// the source reports the catalog unchanged
+func (c *Cache) Touch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.updatedAt = time.Now()
 }
 
+// Entries returns the entries passed to the last Set
+func (c *Cache) Entries() []models.CatalogEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.all
+}
+
 // GetLatestVersion returns the latest version for a model/component
 func (c *Cache) GetLatestVersion(systemModel, componentType string) (string, bool) {
 	c.mu.RLock()
@@ -89,3 +128,57 @@ func (c *Cache) Count() int {
 	defer c.mu.RUnlock()
 	return len(c.entries)
 }
+
+// Match returns the newest package that applies to the given device on the given
+// system. The system is looked up by its vendor system ID when known, falling back
+// to the model name; the device must match one of the package's supported devices.
+func (c *Cache) Match(systemID, systemModel string, id models.DeviceIdentity) (models.CatalogEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var candidates []models.CatalogEntry
+	if systemID != "" {
+		candidates = c.bySystem[systemIDKey(systemID)]
+	}
+	if len(candidates) == 0 && systemModel != "" {
+		candidates = c.bySystem[systemModelKey(systemModel)]
+	}
+
+	var best models.CatalogEntry
+	found := false
+	for _, entry := range candidates {
+		if !supportsDevice(entry, id) {
+			continue
+		}
+		if !found || version.Newer(entry.Version, best.Version) {
+			best = entry
+			found = true
+		}
+	}
+	return best, found
+}
+
+func supportsDevice(entry models.CatalogEntry, id models.DeviceIdentity) bool {
+	for _, d := range entry.Devices {
+		if d.Matches(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// systemIDKey normalises a hex system ID, e.g. "8b4" -> "id:08B4"
+func systemIDKey(systemID string) string {
+	id := strings.ToUpper(strings.TrimSpace(systemID))
+	for len(id) < 4 {
+		id = "0" + id
+	}
+	return "id:" + id
+}
+
+// systemModelKey normalises a model name so "PowerEdge R640" and "R640" share a key
+func systemModelKey(model string) string {
+	m := strings.ToUpper(strings.TrimSpace(model))
+	m = strings.TrimPrefix(m, "POWEREDGE ")
+	return "model:" + m
+}
@@ -80,3 +80,59 @@ func TestCache_NotFound(t *testing.T) {
 		t.Error("expected not found for non-existent model")
 	}
 }
+
+func TestCache_SetKeepsNewestVersion(t *testing.T) {
+	cache := NewCache(1 * time.Hour)
+
+	cache.Set([]models.CatalogEntry{
+		{ComponentType: "NIC", SystemModelID: "PowerEdge R640", Version: "9.10.0"},
+		{ComponentType: "NIC", SystemModelID: "PowerEdge R640", Version: "9.2.0"},
+	})
+
+	version, _ := cache.GetLatestVersion("PowerEdge R640", "NIC")
+	if version != "9.10.0" {
+		t.Errorf("expected version 9.10.0, got %s", version)
+	}
+}
+
+func TestCache_Match(t *testing.T) {
+	cache := NewCache(1 * time.Hour)
+
+	x710 := models.DeviceIdentity{ComponentID: "104999", VendorID: "8086", DeviceID: "1572", SubVendorID: "8086", SubDeviceID: "0006"}
+	bcm := models.DeviceIdentity{VendorID: "14E4", DeviceID: "16D7", SubVendorID: "14E4", SubDeviceID: "4141"}
+	cache.Set([]models.CatalogEntry{
+		{ComponentID: "BIOS1", ComponentType: "BIOS", SystemModelID: "R640", SystemID: "0716", Version: "2.19.1", Devices: []models.DeviceIdentity{{ComponentID: "159"}}},
+		{ComponentID: "NIC1", ComponentType: "Firmware", SystemModelID: "R640", SystemID: "0716", Version: "22.5.7", Devices: []models.DeviceIdentity{x710}},
+		{ComponentID: "NIC2", ComponentType: "Firmware", SystemModelID: "R640", SystemID: "0716", Version: "22.31.6", Devices: []models.DeviceIdentity{x710}},
+		{ComponentID: "NIC3", ComponentType: "Firmware", SystemModelID: "R640", SystemID: "0716", Version: "223.0.1", Devices: []models.DeviceIdentity{bcm}},
+	})
+
+	tests := []struct {
+		name      string
+		systemID  string
+		model     string
+		id        models.DeviceIdentity
+		wantFound bool
+		wantPkg   string
+	}{
+		{"bios by component id", "0716", "", models.DeviceIdentity{ComponentID: "159"}, true, "BIOS1"},
+		{"nic picks newest package", "0716", "", x710, true, "NIC2"},
+		{"nic by pci ignores component id", "0716", "", models.DeviceIdentity{VendorID: "14e4", DeviceID: "16d7", SubVendorID: "14e4", SubDeviceID: "4141"}, true, "NIC3"},
+		{"other pci device has no package", "0716", "", models.DeviceIdentity{ComponentID: "104999", VendorID: "8086", DeviceID: "1572", SubVendorID: "8086", SubDeviceID: "0000"}, false, ""},
+		{"falls back to model name", "", "PowerEdge R640", models.DeviceIdentity{ComponentID: "159"}, true, "BIOS1"},
+		{"unknown system", "08B4", "PowerEdge R740", models.DeviceIdentity{ComponentID: "159"}, false, ""},
+		{"no identity", "0716", "", models.DeviceIdentity{}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, found := cache.Match(tt.systemID, tt.model, tt.id)
+			if found != tt.wantFound {
+				t.Fatalf("Match() found = %v, want %v", found, tt.wantFound)
+			}
+			if entry.ComponentID != tt.wantPkg {
+				t.Errorf("Match() package = %q, want %q", entry.ComponentID, tt.wantPkg)
+			}
+		})
+	}
+}
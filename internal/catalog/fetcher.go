@@ -1,13 +1,31 @@
 package catalog
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 )
 
+// ErrNotModified is returned by conditional fetches when the catalog is unchanged
+var ErrNotModified = errors.New("catalog not modified")
+
+// Validators are the HTTP cache validators of a downloaded catalog
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// IsZero returns true if no validators were recorded
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
 // Fetcher downloads catalog files from a URL
 type Fetcher struct {
 	url    string
@@ -16,43 +34,127 @@ type Fetcher struct {
 
 // NewFetcher creates a new catalog fetcher
 func NewFetcher(url string) *Fetcher {
+	return NewFetcherWithClient(url, &http.Client{
+		Timeout: 5 * time.Minute, // Catalog can be large
+	})
+}
+
+// NewFetcherWithClient creates a catalog fetcher using the given HTTP client,
+// e.g. one that trusts an internal mirror's CA
+func NewFetcherWithClient(url string, client *http.Client) *Fetcher {
 	return &Fetcher{
-		url: url,
-		client: &http.Client{
-			Timeout: 5 * time.Minute, // Catalog can be large
-		},
+		url:    url,
+		client: client,
 	}
 }
 
 // Fetch downloads and decompresses the catalog
 func (f *Fetcher) Fetch() ([]byte, error) {
-	resp, err := f.client.Get(f.url)
+	data, err := f.FetchRaw(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch catalog: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return decompress(data)
+}
+
+// FetchRaw downloads the catalog exactly as published, without decompressing it,
+// so it can be checked against a signature or hash
+func (f *Fetcher) FetchRaw(ctx context.Context) ([]byte, error) {
+	data, _, err := f.fetch(ctx, f.url, Validators{})
+	return data, err
+}
+
+// FetchIfModified opens the catalog download unless it is unchanged since the
+// given validators were recorded, in which case it returns ErrNotModified. The
+// caller streams and closes the returned body; the validators describe it.
+func (f *Fetcher) FetchIfModified(ctx context.Context, v Validators) (io.ReadCloser, Validators, error) {
+	return f.open(ctx, f.url, v)
+}
+
+func (f *Fetcher) get(ctx context.Context, url string) ([]byte, error) {
+	data, _, err := f.fetch(ctx, url, Validators{})
+	return data, err
+}
+
+// fetch downloads url into memory, for sidecars and callers that need the whole file
+func (f *Fetcher) fetch(ctx context.Context, url string, v Validators) ([]byte, Validators, error) {
+	body, validators, err := f.open(ctx, url, v)
+	if err != nil {
+		return nil, validators, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, Validators{}, fmt.Errorf("failed to read catalog: %w", err)
+	}
+	return data, validators, nil
+}
 
+// open requests url and returns the response body without reading it
+func (f *Fetcher) open(ctx context.Context, url string, v Validators) (io.ReadCloser, Validators, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, Validators{}, fmt.Errorf("failed to fetch catalog: %w", err)
+	}
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, Validators{}, fmt.Errorf("failed to fetch catalog: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return nil, v, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("catalog fetch returned status %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, Validators{}, fmt.Errorf("catalog fetch returned status %d", resp.StatusCode)
+	}
+
+	return resp.Body, Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// openPayload returns a reader over the catalog XML read from r, gunzipping it on
+// the fly if it starts with the gzip magic number
+func openPayload(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return br, nil
+	}
+	gzReader, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
 	}
+	return gzReader, nil
+}
 
-	// Check if response is gzipped
-	var reader io.Reader = resp.Body
-	if resp.Header.Get("Content-Type") == "application/gzip" ||
-		resp.Header.Get("Content-Encoding") == "gzip" ||
-		len(f.url) > 3 && f.url[len(f.url)-3:] == ".gz" {
-		gzReader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer gzReader.Close()
-		reader = gzReader
+// decompress gunzips data if it carries the gzip magic number and returns it
+// unchanged otherwise
+func decompress(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
 	}
 
-	data, err := io.ReadAll(reader)
+	gzReader, err := gzip.NewReader(bytes.NewReader(data))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read catalog: %w", err)
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
 	}
+	defer gzReader.Close()
 
-	return data, nil
+	out, err := io.ReadAll(gzReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %w", err)
+	}
+	return out, nil
 }
@@ -1,18 +1,17 @@
 package catalog
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
 
 // XML structures for Dell catalog
-type manifest struct {
-	XMLName    xml.Name            `xml:"Manifest"`
-	Components []softwareComponent `xml:"SoftwareComponent"`
-}
-
 type softwareComponent struct {
 	PackageID        string           `xml:"packageID,attr"`
 	ReleaseDate      string           `xml:"releaseDate,attr"`
@@ -23,6 +22,7 @@ type softwareComponent struct {
 	ComponentType    componentType    `xml:"ComponentType"`
 	Criticality      criticality      `xml:"Criticality"`
 	SupportedSystems supportedSystems `xml:"SupportedSystems"`
+	SupportedDevices supportedDevices `xml:"SupportedDevices"`
 }
 
 type componentName struct {
@@ -48,50 +48,193 @@ type brand struct {
 type model struct {
 	SystemID string `xml:"systemID,attr"`
 	Name     string `xml:",chardata"`
+	Display  string `xml:"Display"`
+}
+
+type supportedDevices struct {
+	Devices []device `xml:"Device"`
+}
+
+type device struct {
+	ComponentID string  `xml:"componentID,attr"`
+	PCIInfo     pciInfo `xml:"PCIInfo"`
 }
 
+type pciInfo struct {
+	VendorID    string `xml:"vendorID,attr"`
+	DeviceID    string `xml:"deviceID,attr"`
+	SubVendorID string `xml:"subVendorID,attr"`
+	SubDeviceID string `xml:"subDeviceID,attr"`
+}
+
+// defaultDownloadBaseURL serves packages of a catalog that names no baseLocation
+const defaultDownloadBaseURL = "https://downloads.dell.com"
+
 // Parser parses Dell catalog XML
-type Parser struct{}
+type Parser struct {
+	downloadBaseURL string
+}
 
 // NewParser creates a new catalog parser
 func NewParser() *Parser {
 	return &Parser{}
 }
 
+// SetDownloadBaseURL sets the repository mirror that package download URLs are
+// built from, overriding the catalog's own baseLocation
+func (p *Parser) SetDownloadBaseURL(baseURL string) {
+	p.downloadBaseURL = baseURL
+}
+
+// SystemFilter reports whether entries for a catalog system (hex system ID and
+// model name) should be kept
+type SystemFilter func(systemID, model string) bool
+
 // Parse parses XML data into catalog entries
 func (p *Parser) Parse(data []byte) ([]models.CatalogEntry, error) {
-	var m manifest
-	if err := xml.Unmarshal(data, &m); err != nil {
-		return nil, fmt.Errorf("failed to parse catalog XML: %w", err)
-	}
+	return p.ParseReader(bytes.NewReader(data), nil)
+}
+
+// ParseReader stream-parses a catalog, decoding one SoftwareComponent at a time so
+// the whole manifest is never held in memory. If keep is non-nil, entries are only
+// created for the systems it accepts.
+func (p *Parser) ParseReader(r io.Reader, keep SystemFilter) ([]models.CatalogEntry, error) {
+	decoder := xml.NewDecoder(r)
 
 	var entries []models.CatalogEntry
+	seenRoot := false
+	baseURL := ""
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse catalog XML: %w", err)
+		}
 
-	for _, comp := range m.Components {
-		componentType := mapComponentType(comp.ComponentType.Value)
-
-		// Create an entry for each supported system model
-		for _, brand := range comp.SupportedSystems.Brands {
-			for _, model := range brand.Models {
-				entry := models.CatalogEntry{
-					ComponentID:   comp.PackageID,
-					ComponentType: componentType,
-					SystemModelID: model.Name,
-					Version:       comp.VendorVersion,
-					ReleaseDate:   comp.ReleaseDate,
-					Criticality:   comp.Criticality.Value,
-					DownloadURL:   "https://downloads.dell.com/" + comp.Path,
-					FileName:      comp.Name.Display,
-					SizeMB:        int(comp.Size / 1024 / 1024),
-				}
-				entries = append(entries, entry)
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !seenRoot {
+			if start.Name.Local != "Manifest" {
+				return nil, fmt.Errorf("failed to parse catalog XML: expected Manifest, got %s", start.Name.Local)
 			}
+			seenRoot = true
+			baseURL = p.baseURL(start)
+			continue
+		}
+		if start.Name.Local != "SoftwareComponent" {
+			continue
 		}
+
+		var comp softwareComponent
+		if err := decoder.DecodeElement(&comp, &start); err != nil {
+			return nil, fmt.Errorf("failed to parse catalog XML: %w", err)
+		}
+		entries = appendEntries(entries, comp, baseURL, keep)
 	}
 
+	if !seenRoot {
+		return nil, fmt.Errorf("failed to parse catalog XML: %w", io.ErrUnexpectedEOF)
+	}
 	return entries, nil
 }
 
+// baseURL returns the URL package paths are relative to: the configured mirror,
+// else the Manifest's baseLocation over HTTPS where it is offered
+func (p *Parser) baseURL(manifest xml.StartElement) string {
+	if p.downloadBaseURL != "" {
+		return p.downloadBaseURL
+	}
+
+	var location, protocols string
+	for _, attr := range manifest.Attr {
+		switch attr.Name.Local {
+		case "baseLocation":
+			location = strings.TrimSpace(attr.Value)
+		case "baseLocationAccessProtocols":
+			protocols = attr.Value
+		}
+	}
+	if location == "" {
+		return defaultDownloadBaseURL
+	}
+	if strings.Contains(location, "://") {
+		return location
+	}
+
+	scheme := "https"
+	if offered := strings.Split(strings.ToLower(protocols), ","); protocols != "" && !slices.Contains(offered, "https") {
+		scheme = strings.TrimSpace(offered[0])
+	}
+	return scheme + "://" + location
+}
+
+// downloadURL joins a package path onto a base URL
+func downloadURL(baseURL, path string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// appendEntries adds one entry per supported system model of a package
+func appendEntries(entries []models.CatalogEntry, comp softwareComponent, baseURL string, keep SystemFilter) []models.CatalogEntry {
+	componentType := mapComponentType(comp.ComponentType.Value)
+	var devices []models.DeviceIdentity
+	devicesParsed := false
+
+	for _, brand := range comp.SupportedSystems.Brands {
+		for _, model := range brand.Models {
+			modelName := strings.TrimSpace(model.Name)
+			if modelName == "" {
+				modelName = strings.TrimSpace(model.Display)
+			}
+			systemID := strings.ToUpper(model.SystemID)
+			if keep != nil && !keep(systemID, modelName) {
+				continue
+			}
+			// Devices are shared by every model entry of the package
+			if !devicesParsed {
+				devices = parseDevices(comp.SupportedDevices)
+				devicesParsed = true
+			}
+			entries = append(entries, models.CatalogEntry{
+				ComponentID:   comp.PackageID,
+				ComponentType: componentType,
+				SystemModelID: modelName,
+				SystemID:      systemID,
+				Version:       comp.VendorVersion,
+				ReleaseDate:   comp.ReleaseDate,
+				Criticality:   comp.Criticality.Value,
+				DownloadURL:   downloadURL(baseURL, comp.Path),
+				FileName:      comp.Name.Display,
+				SizeMB:        int(comp.Size / 1024 / 1024),
+				Devices:       devices,
+			})
+		}
+	}
+	return entries
+}
+
+// parseDevices converts a package's SupportedDevices into device identities
+func parseDevices(sd supportedDevices) []models.DeviceIdentity {
+	if len(sd.Devices) == 0 {
+		return nil
+	}
+	devices := make([]models.DeviceIdentity, 0, len(sd.Devices))
+	for _, d := range sd.Devices {
+		devices = append(devices, models.DeviceIdentity{
+			ComponentID: d.ComponentID,
+			VendorID:    strings.ToUpper(d.PCIInfo.VendorID),
+			DeviceID:    strings.ToUpper(d.PCIInfo.DeviceID),
+			SubVendorID: strings.ToUpper(d.PCIInfo.SubVendorID),
+			SubDeviceID: strings.ToUpper(d.PCIInfo.SubDeviceID),
+		})
+	}
+	return devices
+}
+
 // mapComponentType maps Dell component type codes to readable names
 func mapComponentType(code string) string {
 	types := map[string]string{
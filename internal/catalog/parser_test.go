@@ -1,7 +1,17 @@
 package catalog
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
 
 func TestParser_Parse(t *testing.T) {
@@ -64,3 +74,259 @@ func TestParser_EmptyManifest(t *testing.T) {
 		t.Errorf("expected 0 entries, got %d", len(entries))
 	}
 }
+
+func TestParser_SupportedDevices(t *testing.T) {
+	xmlData := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<Manifest version="2.0">
+	<SoftwareComponent packageID="NIC1" vendorVersion="22.5.7" path="FOLDER1/Network.EXE">
+		<Name><Display>Intel NIC Family Version 22.5.7</Display></Name>
+		<ComponentType value="FRMW"/>
+		<SupportedDevices>
+			<Device componentID="104999" embedded="1">
+				<Display lang="en">Intel(R) Ethernet 10G X710</Display>
+				<PCIInfo deviceID="1572" subDeviceID="0006" subVendorID="8086" vendorID="8086"/>
+			</Device>
+		</SupportedDevices>
+		<SupportedSystems>
+			<Brand>
+				<Model systemID="0716" systemIDType="BIOS"><Display lang="en">R640</Display></Model>
+			</Brand>
+		</SupportedSystems>
+	</SoftwareComponent>
+</Manifest>`)
+
+	entries, err := NewParser().Parse(xmlData)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.SystemID != "0716" || entry.SystemModelID != "R640" {
+		t.Errorf("unexpected system: %q %q", entry.SystemID, entry.SystemModelID)
+	}
+	if len(entry.Devices) != 1 {
+		t.Fatalf("expected 1 device, got %d", len(entry.Devices))
+	}
+	d := entry.Devices[0]
+	if d.ComponentID != "104999" || d.VendorID != "8086" || d.DeviceID != "1572" || d.SubDeviceID != "0006" {
+		t.Errorf("unexpected device: %+v", d)
+	}
+}
+
+func TestParser_ParseReaderFilter(t *testing.T) {
+	xmlData := `<?xml version="1.0" encoding="utf-8"?>
+<Manifest version="2.0">
+	<SoftwareComponent packageID="BIOS1" vendorVersion="2.19.1" path="FOLDER1/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems>
+			<Brand>
+				<Model systemID="08B4">PowerEdge R640</Model>
+				<Model systemID="08ff">PowerEdge R740</Model>
+			</Brand>
+		</SupportedSystems>
+	</SoftwareComponent>
+	<SoftwareComponent packageID="BIOS2" vendorVersion="1.4.2" path="FOLDER2/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems>
+			<Brand><Model systemID="0A6B">PowerEdge R750</Model></Brand>
+		</SupportedSystems>
+	</SoftwareComponent>
+</Manifest>`
+
+	keep := func(systemID, model string) bool {
+		return systemID == "08FF"
+	}
+	entries, err := NewParser().ParseReader(strings.NewReader(xmlData), keep)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].ComponentID != "BIOS1" || entries[0].SystemModelID != "PowerEdge R740" {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+}
+
+func TestParser_DownloadURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		mirror   string
+		want     string
+	}{
+		{"no baseLocation", `<Manifest version="2.0">`, "", "https://downloads.dell.com/FOLDER1/BIOS.EXE"},
+		{"Dell catalog", `<Manifest baseLocation="downloads.dell.com" baseLocationAccessProtocols="HTTP,HTTPS">`, "", "https://downloads.dell.com/FOLDER1/BIOS.EXE"},
+		{"mirrored catalog", `<Manifest baseLocation="repo.example.com/dell/" baseLocationAccessProtocols="HTTP">`, "", "http://repo.example.com/dell/FOLDER1/BIOS.EXE"},
+		{"baseLocation with scheme", `<Manifest baseLocation="https://repo.example.com/dell">`, "", "https://repo.example.com/dell/FOLDER1/BIOS.EXE"},
+		{"configured mirror", `<Manifest baseLocation="downloads.dell.com">`, "https://mirror.example.com/firmware/", "https://mirror.example.com/firmware/FOLDER1/BIOS.EXE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xmlData := tt.manifest + `
+	<SoftwareComponent packageID="BIOS1" vendorVersion="2.19.1" path="FOLDER1/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems><Brand><Model systemID="08B4">PowerEdge R640</Model></Brand></SupportedSystems>
+	</SoftwareComponent>
+</Manifest>`
+			parser := NewParser()
+			parser.SetDownloadBaseURL(tt.mirror)
+			entries, err := parser.Parse([]byte(xmlData))
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if len(entries) != 1 || entries[0].DownloadURL != tt.want {
+				t.Errorf("expected download URL %q, got %+v", tt.want, entries)
+			}
+		})
+	}
+}
+
+func TestParser_ParseReaderRejectsNonManifest(t *testing.T) {
+	if _, err := NewParser().ParseReader(strings.NewReader(`<html><body>Not Found</body></html>`), nil); err == nil {
+		t.Error("expected error for non-catalog document")
+	}
+}
+
+// syntheticCatalog builds a gzipped catalog with packages for many system models,
+// roughly the shape of the real Dell catalog
+func syntheticCatalog(b *testing.B, packages, modelsPerPackage int) []byte {
+	b.Helper()
+	var xmlBuf bytes.Buffer
+	xmlBuf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n" + `<Manifest version="2.0">`)
+	for i := 0; i < packages; i++ {
+		fmt.Fprintf(&xmlBuf, `<SoftwareComponent packageID="PKG%05d" releaseDate="2024-01-15" vendorVersion="1.%d.0" path="FOLDER%05d/Package.EXE" size="16777216">`, i, i%50, i)
+		xmlBuf.WriteString(`<Name><Display lang="en">Firmware Package</Display></Name><ComponentType value="FRMW"/><Criticality value="Recommended"/>`)
+		xmlBuf.WriteString(`<Description><Display lang="en">` + strings.Repeat("Release notes text. ", 20) + `</Display></Description>`)
+		xmlBuf.WriteString(`<SupportedDevices><Device componentID="104999"><Display lang="en">Network Adapter</Display><PCIInfo deviceID="1572" subDeviceID="0006" subVendorID="8086" vendorID="8086"/></Device></SupportedDevices>`)
+		xmlBuf.WriteString(`<SupportedSystems><Brand>`)
+		for m := 0; m < modelsPerPackage; m++ {
+			fmt.Fprintf(&xmlBuf, `<Model systemID="%04X"><Display lang="en">R%d</Display></Model>`, 0x0700+m, 600+m)
+		}
+		xmlBuf.WriteString(`</Brand></SupportedSystems></SoftwareComponent>`)
+	}
+	xmlBuf.WriteString(`</Manifest>`)
+
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	gz.Write(xmlBuf.Bytes())
+	gz.Close()
+	return gzBuf.Bytes()
+}
+
+// peakHeap samples live heap bytes while fn runs and returns the peak above the
+// starting point
+func peakHeap(fn func()) uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	read := func() uint64 {
+		metrics.Read(sample)
+		return sample[0].Value.Uint64()
+	}
+
+	runtime.GC()
+	base := read()
+	peak := base
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(100 * time.Microsecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				peak = max(peak, read())
+			}
+		}
+	}()
+
+	fn()
+	close(done)
+	<-stopped
+	return max(peak, read()) - base
+}
+
+// unmarshalManifest is the whole-document catalog structure parsed before streaming
+type unmarshalManifest struct {
+	XMLName    xml.Name            `xml:"Manifest"`
+	Components []softwareComponent `xml:"SoftwareComponent"`
+}
+
+// BenchmarkParseUnmarshal handles the catalog as it was before streaming: the
+// download is decompressed in full and unmarshalled in one go
+func BenchmarkParseUnmarshal(b *testing.B) {
+	data := syntheticCatalog(b, 2000, 40)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		peak = max(peak, peakHeap(func() {
+			xmlData, err := decompress(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var m unmarshalManifest
+			if err := xml.Unmarshal(xmlData, &m); err != nil {
+				b.Fatal(err)
+			}
+			var entries []models.CatalogEntry
+			for _, comp := range m.Components {
+				entries = appendEntries(entries, comp, defaultDownloadBaseURL, nil)
+			}
+		}))
+	}
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
+
+// BenchmarkParseStream stream-parses from the gzip reader, keeping every entry
+func BenchmarkParseStream(b *testing.B) {
+	data := syntheticCatalog(b, 2000, 40)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		peak = max(peak, peakHeap(func() {
+			r, err := openPayload(bytes.NewReader(data))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := NewParser().ParseReader(r, nil); err != nil {
+				b.Fatal(err)
+			}
+		}))
+	}
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
+
+// BenchmarkParseStreamFiltered stream-parses from the gzip reader, keeping only
+// the entries for a two-model inventory
+func BenchmarkParseStreamFiltered(b *testing.B) {
+	data := syntheticCatalog(b, 2000, 40)
+	keep := func(systemID, model string) bool {
+		return systemID == "0700" || systemID == "0701"
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		peak = max(peak, peakHeap(func() {
+			r, err := openPayload(bytes.NewReader(data))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := NewParser().ParseReader(r, keep); err != nil {
+				b.Fatal(err)
+			}
+		}))
+	}
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
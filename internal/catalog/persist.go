@@ -0,0 +1,77 @@
+package catalog
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// snapshotFile is the name of the persisted catalog within the cache directory
+const snapshotFile = "catalog.json.gz"
+
+// snapshot is the on-disk form of a parsed catalog
+type snapshot struct {
+	Source       string                `json:"source"`
+	Verification Verification          `json:"verification"`
+	Validators   Validators            `json:"validators"`
+	SyncedAt     time.Time             `json:"syncedAt"`
+	Covered      []string              `json:"covered,omitempty"`
+	Entries      []models.CatalogEntry `json:"entries"`
+}
+
+// writeSnapshot atomically replaces the snapshot in dir
+func writeSnapshot(dir string, snap snapshot) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create catalog cache dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, snapshotFile+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create catalog snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(gz).Encode(snap); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write catalog snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write catalog snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write catalog snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, snapshotFile)); err != nil {
+		return fmt.Errorf("failed to replace catalog snapshot: %w", err)
+	}
+	return nil
+}
+
+// readSnapshot loads the snapshot from dir, returning os.ErrNotExist if there is none
+func readSnapshot(dir string) (*snapshot, error) {
+	f, err := os.Open(filepath.Join(dir, snapshotFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	var snap snapshot
+	if err := json.NewDecoder(gz).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("failed to read catalog snapshot: %w", err)
+	}
+	return &snap, nil
+}
@@ -1,9 +1,15 @@
 package catalog
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
@@ -11,43 +17,327 @@ import (
 
 // Service manages catalog fetching, parsing, and caching
 type Service struct {
-	fetcher *Fetcher
-	parser  *Parser
-	cache   *Cache
+	sources  []Source
+	verifier *Verifier
+	parser   *Parser
+	cache    *Cache
+	cacheDir string
+
+	inventory func() []models.Node
+
+	mu         sync.RWMutex
+	status     Status
+	validators Validators
+	covered    map[string]bool // system keys the catalog was filtered to; nil means all
 }
 
-// NewService creates a new catalog service
+// Status reports where the current catalog came from, how it was verified and how old it is
+type Status struct {
+	Source       string       `json:"source,omitempty"`
+	Verification Verification `json:"verification"`
+	LastSynced   time.Time    `json:"lastSynced,omitzero"`
+	AgeSeconds   int64        `json:"ageSeconds"`
+	Stale        bool         `json:"stale"`
+	Entries      int          `json:"entries"`
+	LastError    string       `json:"lastError,omitempty"`
+}
+
+// NewService creates a new catalog service that downloads from catalogURL
+// without verification
 func NewService(catalogURL string, cacheTTL time.Duration) *Service {
+	return NewServiceWithSources([]Source{&HTTPSource{url: catalogURL, fetcher: NewFetcher(catalogURL)}}, nil, cacheTTL)
+}
+
+// NewServiceWithSources creates a catalog service that tries sources in order,
+// accepting the first catalog that passes the verifier (nil accepts anything)
+func NewServiceWithSources(sources []Source, verifier *Verifier, cacheTTL time.Duration) *Service {
 	return &Service{
-		fetcher: NewFetcher(catalogURL),
-		parser:  NewParser(),
-		cache:   NewCache(cacheTTL),
+		sources:  sources,
+		verifier: verifier,
+		parser:   NewParser(),
+		cache:    NewCache(cacheTTL),
+	}
+}
+
+// SetCacheDir enables persisting the parsed catalog to dir (e.g. a PVC mount)
+// after each sync so it survives restarts; see Load
+func (s *Service) SetCacheDir(dir string) {
+	s.cacheDir = dir
+}
+
+// SetDownloadBaseURL builds package download URLs from a repository mirror
+// rather than the catalog's baseLocation
+func (s *Service) SetDownloadBaseURL(baseURL string) {
+	s.parser.SetDownloadBaseURL(baseURL)
+}
+
+// SetInventory limits parsed catalog entries to the systems in the node inventory
+// returned by fn. Until the inventory has nodes, the full catalog is kept.
+func (s *Service) SetInventory(fn func() []models.Node) {
+	s.inventory = fn
+}
+
+// inventoryKeys returns the system keys of the current inventory, or nil if none
+func (s *Service) inventoryKeys() map[string]bool {
+	if s.inventory == nil {
+		return nil
+	}
+	var keys map[string]bool
+	for _, node := range s.inventory() {
+		if keys == nil {
+			keys = make(map[string]bool)
+		}
+		if node.SystemID != "" {
+			keys[systemIDKey(node.SystemID)] = true
+		}
+		if node.Model != "" {
+			keys[systemModelKey(node.Model)] = true
+		}
+	}
+	return keys
+}
+
+// inventoryChanged returns true if the inventory has systems the filtered catalog does not cover
+func (s *Service) inventoryChanged(keys map[string]bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.covered == nil {
+		return false
+	}
+	for key := range keys {
+		if !s.covered[key] {
+			return true
+		}
 	}
+	return false
 }
 
-// Sync fetches and parses the catalog
+// Load restores the catalog persisted by a previous sync, keeping its original
+// sync time so NeedsSync reflects its real age. It is a no-op if persistence is
+// disabled or nothing has been persisted yet.
+func (s *Service) Load() error {
+	if s.cacheDir == "" {
+		return nil
+	}
+
+	snap, err := readSnapshot(s.cacheDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.cache.SetAt(snap.Entries, snap.SyncedAt)
+
+	s.mu.Lock()
+	s.status = Status{
+		Source:       snap.Source,
+		Verification: snap.Verification,
+		LastSynced:   snap.SyncedAt,
+		Entries:      s.cache.Count(),
+	}
+	s.validators = snap.Validators
+	s.covered = nil
+	if len(snap.Covered) > 0 {
+		s.covered = make(map[string]bool, len(snap.Covered))
+		for _, key := range snap.Covered {
+			s.covered[key] = true
+		}
+	}
+	s.mu.Unlock()
+
+	log.Printf("Loaded persisted catalog from %s: %d entries, synced %s", snap.Source, s.cache.Count(), snap.SyncedAt.Format(time.RFC3339))
+	return nil
+}
+
+// Sync fetches, verifies and parses the catalog from the first source that
+// provides a valid one. If the active source reports the catalog unchanged, the
+// cached entries are kept and only their sync time is refreshed. If every source
+// fails, the last good catalog keeps being served.
 func (s *Service) Sync(ctx context.Context) error {
 	log.Println("Syncing Dell firmware catalog...")
 
-	data, err := s.fetcher.Fetch()
+	// New systems in the inventory need a full download to pick up their entries
+	keys := s.inventoryKeys()
+	conditional := !s.inventoryChanged(keys)
+
+	var errs []error
+	for _, src := range s.sources {
+		payload, err := s.fetch(ctx, src, conditional)
+		if errors.Is(err, ErrNotModified) {
+			s.cache.Touch()
+			s.mu.Lock()
+			s.status.LastSynced = s.cache.LastUpdated()
+			s.status.LastError = ""
+			s.mu.Unlock()
+			s.persist()
+			log.Printf("Catalog unchanged at %s", src.Name())
+			return nil
+		}
+		if err != nil {
+			log.Printf("Catalog source %s failed: %v", src.Name(), err)
+			errs = append(errs, fmt.Errorf("%s: fetch failed: %w", src.Name(), err))
+			continue
+		}
+
+		entries, verification, err := s.accept(payload, keys)
+		if err != nil {
+			log.Printf("Catalog source %s failed: %v", src.Name(), err)
+			errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+			continue
+		}
+
+		s.cache.Set(entries)
+		log.Printf("Catalog synced from %s (verification: %s): %d entries", src.Name(), verification.Method, s.cache.Count())
+
+		s.mu.Lock()
+		s.status = Status{
+			Source:       src.Name(),
+			Verification: verification,
+			LastSynced:   s.cache.LastUpdated(),
+			Entries:      s.cache.Count(),
+		}
+		s.validators = payload.Validators
+		s.covered = keys
+		s.mu.Unlock()
+		s.persist()
+		return nil
+	}
+
+	err := errors.Join(errs...)
+	if err == nil {
+		err = errors.New("no catalog sources configured")
+	}
+	s.mu.Lock()
+	s.status.LastError = err.Error()
+	s.mu.Unlock()
+
+	if s.cache.Count() > 0 {
+		log.Printf("Keeping last good catalog, synced %s ago", time.Since(s.cache.LastUpdated()).Round(time.Minute))
+	}
+	return err
+}
+
+// fetch downloads from src, conditionally if allowed and src is the source of the
+// cached catalog and supports it
+func (s *Service) fetch(ctx context.Context, src Source, conditional bool) (*Payload, error) {
+	s.mu.RLock()
+	active, validators := s.status.Source, s.validators
+	s.mu.RUnlock()
+
+	if cs, ok := src.(ConditionalSource); ok && conditional && active == src.Name() && !validators.IsZero() && s.cache.Count() > 0 {
+		return cs.FetchIfModified(ctx, validators)
+	}
+	return src.Fetch(ctx)
+}
+
+// accept stream-parses a payload, keeping only entries for the given system keys
+// (all entries if keys is nil), and verifies it against the digest taken while
+// it was read. The entries are discarded unless the payload passes verification.
+func (s *Service) accept(payload *Payload, keys map[string]bool) ([]models.CatalogEntry, Verification, error) {
+	body := payload.Body
+	if body == nil {
+		body = io.NopCloser(bytes.NewReader(payload.Data))
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	r := io.TeeReader(body, hash)
+	data := payload.Data
+	var buf bytes.Buffer
+	if payload.Body != nil && s.verifier.needsData() {
+		r = io.TeeReader(r, &buf)
+	}
+
+	entries, parseErr := s.parse(r, keys)
+
+	// Hash whatever the parser left unread so the digest covers the whole file
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, Verification{}, fmt.Errorf("failed to read catalog: %w", err)
+	}
+	if payload.Body != nil {
+		data = buf.Bytes()
+	}
+
+	verification, err := s.verifier.verify(payload, data, hash.Sum(nil))
 	if err != nil {
-		return fmt.Errorf("fetch failed: %w", err)
+		return nil, verification, err
+	}
+	if parseErr != nil {
+		return nil, verification, parseErr
 	}
+	return entries, verification, nil
+}
 
-	entries, err := s.parser.Parse(data)
+// parse stream-parses the catalog read from r, see accept
+func (s *Service) parse(r io.Reader, keys map[string]bool) ([]models.CatalogEntry, error) {
+	xmlReader, err := openPayload(r)
 	if err != nil {
-		return fmt.Errorf("parse failed: %w", err)
+		return nil, fmt.Errorf("decompress failed: %w", err)
 	}
 
-	s.cache.Set(entries)
-	log.Printf("Catalog synced: %d entries", s.cache.Count())
+	var keep SystemFilter
+	if keys != nil {
+		keep = func(systemID, model string) bool {
+			return (systemID != "" && keys[systemIDKey(systemID)]) || (model != "" && keys[systemModelKey(model)])
+		}
+	}
 
-	return nil
+	entries, err := s.parser.ParseReader(xmlReader, keep)
+	if err != nil {
+		return nil, fmt.Errorf("parse failed: %w", err)
+	}
+	return entries, nil
 }
 
-// NeedsSync returns true if catalog needs refresh
+// persist writes the current catalog to the cache directory, if enabled
+func (s *Service) persist() {
+	if s.cacheDir == "" {
+		return
+	}
+
+	s.mu.RLock()
+	snap := snapshot{
+		Source:       s.status.Source,
+		Verification: s.status.Verification,
+		Validators:   s.validators,
+		SyncedAt:     s.status.LastSynced,
+	}
+	for key := range s.covered {
+		snap.Covered = append(snap.Covered, key)
+	}
+	s.mu.RUnlock()
+	snap.Entries = s.cache.Entries()
+
+	if err := writeSnapshot(s.cacheDir, snap); err != nil {
+		log.Printf("Failed to persist catalog: %v", err)
+	}
+}
+
+// Status returns the active source, verification result and age of the current catalog
+func (s *Service) Status() Status {
+	s.mu.RLock()
+	status := s.status
+	s.mu.RUnlock()
+
+	if !status.LastSynced.IsZero() {
+		status.AgeSeconds = int64(time.Since(status.LastSynced).Seconds())
+	}
+	status.Stale = s.cache.IsStale()
+	return status
+}
+
+// Provider names the vendor catalog this service serves, matching
+// redfish.Vendor.CatalogProvider
+func (s *Service) Provider() string {
+	return models.CatalogProviderDell
+}
+
+// NeedsSync returns true if catalog needs refresh, either because it is stale or
+// because the inventory has systems it was not filtered for
 func (s *Service) NeedsSync() bool {
-	return s.cache.IsStale()
+	return s.cache.IsStale() || s.inventoryChanged(s.inventoryKeys())
 }
 
 // GetLatestVersion returns the latest version for a model/component
@@ -55,6 +345,11 @@ func (s *Service) GetLatestVersion(systemModel, componentType string) (string, b
 	return s.cache.GetLatestVersion(systemModel, componentType)
 }
 
+// Match returns the newest package for a device on a system, see Cache.Match
+func (s *Service) Match(systemID, systemModel string, id models.DeviceIdentity) (models.CatalogEntry, bool) {
+	return s.cache.Match(systemID, systemModel, id)
+}
+
 // GetEntry returns the full catalog entry
 func (s *Service) GetEntry(systemModel, componentType string) (models.CatalogEntry, bool) {
 	return s.cache.GetEntry(systemModel, componentType)
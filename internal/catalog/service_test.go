@@ -1,12 +1,21 @@
 package catalog
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
 
 func TestService_Sync(t *testing.T) {
@@ -54,3 +63,267 @@ func TestService_NeedsSync(t *testing.T) {
 		t.Error("new service should need sync")
 	}
 }
+
+// staticSource serves a fixed payload
+type staticSource struct {
+	name    string
+	payload *Payload
+}
+
+func (s staticSource) Name() string { return s.name }
+
+func (s staticSource) Fetch(ctx context.Context) (*Payload, error) {
+	if s.payload == nil {
+		return nil, errors.New("unavailable")
+	}
+	return s.payload, nil
+}
+
+func TestService_SyncSourceFallback(t *testing.T) {
+	mockXML := []byte(`<?xml version="1.0"?>
+<Manifest version="2.0">
+	<SoftwareComponent packageID="ABC123" vendorVersion="2.19.1" path="FOLDER/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems>
+			<Brand><Model systemID="08B4">PowerEdge R640</Model></Brand>
+		</SupportedSystems>
+	</SoftwareComponent>
+</Manifest>`)
+	sum := sha256.Sum256(mockXML)
+
+	verifier, err := NewVerifier("", "", true)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	svc := NewServiceWithSources([]Source{
+		staticSource{name: "file:/missing"},
+		staticSource{name: "configmap:unsigned", payload: &Payload{Data: mockXML}},
+		staticSource{name: "url:mirror", payload: &Payload{Data: mockXML, Hash: hex.EncodeToString(sum[:])}},
+	}, verifier, time.Hour)
+
+	if err := svc.Sync(context.Background()); err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+
+	status := svc.Status()
+	if status.Source != "url:mirror" {
+		t.Errorf("expected url:mirror to be active, got %q", status.Source)
+	}
+	if !status.Verification.Verified || status.Verification.Method != VerifySHA256 {
+		t.Errorf("unexpected verification: %+v", status.Verification)
+	}
+	if status.Entries != 1 {
+		t.Errorf("expected 1 entry, got %d", status.Entries)
+	}
+}
+
+func TestService_SyncStreamedPayload(t *testing.T) {
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	gz.Write([]byte(`<?xml version="1.0"?>
+<Manifest version="2.0">
+	<SoftwareComponent packageID="ABC123" vendorVersion="2.19.1" path="FOLDER/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems>
+			<Brand><Model systemID="08B4">PowerEdge R640</Model></Brand>
+		</SupportedSystems>
+	</SoftwareComponent>
+</Manifest>`))
+	gz.Close()
+	catalog := gzBuf.Bytes()
+	sum := sha256.Sum256(catalog)
+
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	signed, err := NewVerifier(writePublicKey(t, edPub), "", true)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	hashed, err := NewVerifier("", hex.EncodeToString(sum[:]), true)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	tampered := bytes.Clone(catalog)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		data     []byte
+		sig      []byte
+		wantErr  bool
+	}{
+		{"sha256", hashed, catalog, nil, false},
+		{"sha256 tampered", hashed, tampered, nil, true},
+		{"ed25519", signed, catalog, ed25519.Sign(edPriv, catalog), false},
+		{"ed25519 tampered", signed, tampered, ed25519.Sign(edPriv, catalog), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := io.NopCloser(bytes.NewReader(tt.data))
+			svc := NewServiceWithSources([]Source{
+				staticSource{name: "url:mirror", payload: &Payload{Body: body, Signature: tt.sig}},
+			}, tt.verifier, time.Hour)
+
+			err := svc.Sync(context.Background())
+			if tt.wantErr {
+				if !errors.Is(err, ErrVerification) || svc.Status().Entries != 0 {
+					t.Errorf("expected verification failure and no entries, got %v, %+v", err, svc.Status())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sync error: %v", err)
+			}
+			if status := svc.Status(); !status.Verification.Verified || status.Entries != 1 {
+				t.Errorf("unexpected status: %+v", status)
+			}
+		})
+	}
+}
+
+func TestService_SyncAllSourcesFail(t *testing.T) {
+	svc := NewServiceWithSources([]Source{staticSource{name: "file:/missing"}}, nil, time.Hour)
+
+	if err := svc.Sync(context.Background()); err == nil {
+		t.Fatal("expected error when no source is available")
+	}
+	if svc.Status().LastError == "" {
+		t.Error("expected last error to be recorded")
+	}
+}
+
+func TestService_ConditionalSyncAndPersistence(t *testing.T) {
+	mockXML := `<?xml version="1.0"?>
+<Manifest version="2.0">
+	<SoftwareComponent packageID="ABC123" vendorVersion="2.19.1" path="FOLDER/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems>
+			<Brand><Model systemID="08B4">PowerEdge R640</Model></Brand>
+		</SupportedSystems>
+	</SoftwareComponent>
+</Manifest>`
+
+	var downloads, notModified int
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Catalog.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		if failing {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(mockXML))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	svc := NewService(server.URL+"/Catalog.xml", 1*time.Hour)
+	svc.SetCacheDir(dir)
+
+	if err := svc.Sync(context.Background()); err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+	if err := svc.Sync(context.Background()); err != nil {
+		t.Fatalf("second sync error: %v", err)
+	}
+	if downloads != 1 || notModified != 1 {
+		t.Errorf("expected 1 download and 1 not-modified, got %d and %d", downloads, notModified)
+	}
+
+	// A restarted service restores the catalog and revalidates conditionally
+	restarted := NewService(server.URL+"/Catalog.xml", 1*time.Hour)
+	restarted.SetCacheDir(dir)
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if restarted.NeedsSync() {
+		t.Error("expected restored catalog to be fresh")
+	}
+	if version, found := restarted.GetLatestVersion("PowerEdge R640", "BIOS"); !found || version != "2.19.1" {
+		t.Errorf("expected restored BIOS 2.19.1, got %q (found=%v)", version, found)
+	}
+	if err := restarted.Sync(context.Background()); err != nil {
+		t.Fatalf("restarted sync error: %v", err)
+	}
+	if downloads != 1 {
+		t.Errorf("expected no re-download after restart, got %d downloads", downloads)
+	}
+
+	// A failed fetch keeps serving the last good catalog
+	failing = true
+	if err := restarted.Sync(context.Background()); err == nil {
+		t.Fatal("expected sync error")
+	}
+	if _, found := restarted.GetLatestVersion("PowerEdge R640", "BIOS"); !found {
+		t.Error("expected last good catalog to be kept")
+	}
+	status := restarted.Status()
+	if status.LastError == "" || status.Entries != 1 || status.LastSynced.IsZero() {
+		t.Errorf("unexpected status after failed sync: %+v", status)
+	}
+}
+
+func TestService_LoadWithoutSnapshot(t *testing.T) {
+	svc := NewService("http://example.com", time.Hour)
+	svc.SetCacheDir(t.TempDir())
+	if err := svc.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !svc.NeedsSync() {
+		t.Error("expected empty service to need sync")
+	}
+}
+
+func TestService_SyncFiltersToInventory(t *testing.T) {
+	mockXML := []byte(`<?xml version="1.0"?>
+<Manifest version="2.0">
+	<SoftwareComponent packageID="BIOS1" vendorVersion="2.19.1" path="FOLDER/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems>
+			<Brand>
+				<Model systemID="08B4">PowerEdge R640</Model>
+				<Model systemID="08FF">PowerEdge R740</Model>
+			</Brand>
+		</SupportedSystems>
+	</SoftwareComponent>
+</Manifest>`)
+
+	nodes := []models.Node{{Name: "a", Model: "PowerEdge R640", SystemID: "08B4"}}
+	svc := NewServiceWithSources([]Source{staticSource{name: "static", payload: &Payload{Data: mockXML}}}, nil, time.Hour)
+	svc.SetInventory(func() []models.Node { return nodes })
+
+	if err := svc.Sync(context.Background()); err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+	if got := svc.Status().Entries; got != 1 {
+		t.Fatalf("expected 1 entry for inventoried system, got %d", got)
+	}
+	if svc.NeedsSync() {
+		t.Error("fresh catalog covering the inventory should not need sync")
+	}
+
+	// A newly discovered model needs a re-sync to pick up its entries
+	nodes = append(nodes, models.Node{Name: "b", Model: "PowerEdge R740"})
+	if !svc.NeedsSync() {
+		t.Fatal("expected sync to be needed after inventory grew")
+	}
+	if err := svc.Sync(context.Background()); err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+	if _, ok := svc.GetLatestVersion("PowerEdge R740", "BIOS"); !ok {
+		t.Error("expected R740 entry after re-sync")
+	}
+	if svc.NeedsSync() {
+		t.Error("catalog covering the grown inventory should not need sync")
+	}
+}
@@ -0,0 +1,225 @@
+package catalog
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Sidecar suffixes for the detached signature and published hash of a catalog
+const (
+	SignatureSuffix = ".sig"
+	HashSuffix      = ".sha256"
+)
+
+// Payload is a catalog as published by a source, before decompression, together
+// with whatever verification material the source published alongside it
+type Payload struct {
+	Data []byte
+	// Body streams the catalog in place of Data for sources that download it.
+	// It is read once and closed when the payload is accepted.
+	Body       io.ReadCloser
+	Signature  []byte
+	Hash       string
+	Validators Validators
+}
+
+// Source provides the raw catalog. Sources are tried in priority order until one
+// returns a payload that passes verification.
+type Source interface {
+	// Name describes the source for status reporting, e.g. "file:/catalog/Catalog.xml.gz"
+	Name() string
+	Fetch(ctx context.Context) (*Payload, error)
+}
+
+// ConditionalSource is a Source that can skip the download when the catalog has
+// not changed since it was last fetched
+type ConditionalSource interface {
+	Source
+	// FetchIfModified returns ErrNotModified if the catalog still matches v
+	FetchIfModified(ctx context.Context, v Validators) (*Payload, error)
+}
+
+// FileSource reads the catalog from a mounted file. A signature and hash are read
+// from the same path with SignatureSuffix and HashSuffix appended, if present.
+type FileSource struct {
+	path string
+}
+
+// NewFileSource creates a source for a local catalog file
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Name returns the source description
+func (s *FileSource) Name() string {
+	return "file:" + s.path
+}
+
+// Fetch reads the catalog file and any sidecars
+func (s *FileSource) Fetch(ctx context.Context) (*Payload, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog file: %w", err)
+	}
+
+	payload := &Payload{Data: data}
+	if sig, err := os.ReadFile(s.path + SignatureSuffix); err == nil {
+		payload.Signature = sig
+	}
+	if hash, err := os.ReadFile(s.path + HashSuffix); err == nil {
+		payload.Hash = parseHashFile(hash)
+	}
+	return payload, nil
+}
+
+// ObjectSource reads the catalog from a key in a ConfigMap or Secret. The signature
+// and hash are read from the same object under key+SignatureSuffix and key+HashSuffix.
+type ObjectSource struct {
+	client    kubernetes.Interface
+	secret    bool
+	namespace string
+	name      string
+	key       string
+}
+
+// NewConfigMapSource creates a source that reads key from a ConfigMap
+func NewConfigMapSource(client kubernetes.Interface, namespace, name, key string) *ObjectSource {
+	return &ObjectSource{client: client, namespace: namespace, name: name, key: key}
+}
+
+// NewSecretSource creates a source that reads key from a Secret
+func NewSecretSource(client kubernetes.Interface, namespace, name, key string) *ObjectSource {
+	return &ObjectSource{client: client, secret: true, namespace: namespace, name: name, key: key}
+}
+
+// Name returns the source description
+func (s *ObjectSource) Name() string {
+	kind := "configmap"
+	if s.secret {
+		kind = "secret"
+	}
+	return fmt.Sprintf("%s:%s/%s/%s", kind, s.namespace, s.name, s.key)
+}
+
+// Fetch reads the catalog and any sidecar keys from the object
+func (s *ObjectSource) Fetch(ctx context.Context) (*Payload, error) {
+	var data map[string][]byte
+	if s.secret {
+		secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", s.namespace, s.name, err)
+		}
+		data = secret.Data
+	} else {
+		cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get configmap %s/%s: %w", s.namespace, s.name, err)
+		}
+		// Compressed catalogs live in binaryData, plain XML in data
+		data = make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+		for k, v := range cm.BinaryData {
+			data[k] = v
+		}
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+	}
+
+	catalog, ok := data[s.key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in %s", s.key, s.Name())
+	}
+
+	payload := &Payload{Data: catalog}
+	if sig, ok := data[s.key+SignatureSuffix]; ok {
+		payload.Signature = sig
+	}
+	if hash, ok := data[s.key+HashSuffix]; ok {
+		payload.Hash = parseHashFile(hash)
+	}
+	return payload, nil
+}
+
+// HTTPSource downloads the catalog from a URL, typically downloads.dell.com or an
+// internal mirror. The signature and hash are fetched from the URL with
+// SignatureSuffix and HashSuffix appended; missing sidecars are not an error.
+type HTTPSource struct {
+	url     string
+	fetcher *Fetcher
+}
+
+// NewHTTPSource creates a source for a catalog URL. If caFile is set, the
+// client trusts that CA bundle in addition to the system roots.
+func NewHTTPSource(url, caFile string) (*HTTPSource, error) {
+	client := &http.Client{
+		Timeout: 5 * time.Minute, // Catalog can be large
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in catalog CA file")
+		}
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}
+	}
+
+	return &HTTPSource{url: url, fetcher: NewFetcherWithClient(url, client)}, nil
+}
+
+// Name returns the source description
+func (s *HTTPSource) Name() string {
+	return "url:" + s.url
+}
+
+// Fetch downloads the catalog and any sidecars
+func (s *HTTPSource) Fetch(ctx context.Context) (*Payload, error) {
+	return s.FetchIfModified(ctx, Validators{})
+}
+
+// FetchIfModified opens the catalog download and fetches any sidecars unless the
+// catalog is unchanged since v was recorded
+func (s *HTTPSource) FetchIfModified(ctx context.Context, v Validators) (*Payload, error) {
+	body, validators, err := s.fetcher.FetchIfModified(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{Body: body, Validators: validators}
+	if sig, err := s.fetcher.get(ctx, s.url+SignatureSuffix); err == nil {
+		payload.Signature = sig
+	}
+	if hash, err := s.fetcher.get(ctx, s.url+HashSuffix); err == nil {
+		payload.Hash = parseHashFile(hash)
+	}
+	return payload, nil
+}
+
+// parseHashFile extracts the digest from sha256sum-style output ("<hex>  <file>")
+func parseHashFile(data []byte) string {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFileSource_Fetch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Catalog.xml")
+	os.WriteFile(path, []byte("<Manifest/>"), 0o644)
+	os.WriteFile(path+HashSuffix, []byte("ABCDEF  Catalog.xml\n"), 0o644)
+
+	src := NewFileSource(path)
+	if src.Name() != "file:"+path {
+		t.Errorf("unexpected name %q", src.Name())
+	}
+
+	payload, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(payload.Data) != "<Manifest/>" {
+		t.Errorf("unexpected data %q", payload.Data)
+	}
+	if payload.Hash != "abcdef" {
+		t.Errorf("expected hash abcdef, got %q", payload.Hash)
+	}
+	if payload.Signature != nil {
+		t.Error("expected no signature")
+	}
+
+	if _, err := NewFileSource(filepath.Join(dir, "missing.xml")).Fetch(context.Background()); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestObjectSource_Fetch(t *testing.T) {
+	client := fake.NewClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "insights"},
+			BinaryData: map[string][]byte{"Catalog.xml.gz": []byte("gz"), "Catalog.xml.gz.sig": []byte("sig")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "insights"},
+			Data:       map[string][]byte{"Catalog.xml": []byte("<Manifest/>")},
+		},
+	)
+
+	cm := NewConfigMapSource(client, "insights", "catalog", "Catalog.xml.gz")
+	payload, err := cm.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(payload.Data) != "gz" || string(payload.Signature) != "sig" {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+
+	secret := NewSecretSource(client, "insights", "catalog", "Catalog.xml")
+	if secret.Name() != "secret:insights/catalog/Catalog.xml" {
+		t.Errorf("unexpected name %q", secret.Name())
+	}
+	payload, err = secret.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(payload.Data) != "<Manifest/>" {
+		t.Errorf("unexpected data %q", payload.Data)
+	}
+
+	if _, err := NewConfigMapSource(client, "insights", "catalog", "other").Fetch(context.Background()); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
+
+func TestHTTPSource_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Catalog.xml":
+			w.Write([]byte("<Manifest/>"))
+		case "/Catalog.xml.sha256":
+			w.Write([]byte("0123abcd"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	src, err := NewHTTPSource(server.URL+"/Catalog.xml", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer payload.Body.Close()
+	if payload.Hash != "0123abcd" || payload.Signature != nil {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+	data, err := io.ReadAll(payload.Body)
+	if err != nil || string(data) != "<Manifest/>" {
+		t.Errorf("unexpected catalog %q: %v", data, err)
+	}
+
+	if _, err := NewHTTPSource(server.URL, filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+}
@@ -0,0 +1,171 @@
+package catalog
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Verification methods reported in Verification.Method
+const (
+	VerifyNone      = "none"
+	VerifySignature = "signature"
+	VerifySHA256    = "sha256"
+)
+
+// Verification describes how a catalog payload was checked before being accepted
+type Verification struct {
+	Method   string `json:"method"`
+	Verified bool   `json:"verified"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// ErrVerification is returned when a catalog fails its signature or hash check
+var ErrVerification = errors.New("catalog verification failed")
+
+// Verifier checks a catalog against a detached signature and/or SHA-256 digest.
+// Signatures cover the catalog file exactly as published and may be RSA PKCS#1 v1.5
+// or ECDSA over SHA-256 (`openssl dgst -sha256 -sign key.pem -out Catalog.xml.gz.sig
+// Catalog.xml.gz`), or Ed25519.
+type Verifier struct {
+	publicKey crypto.PublicKey
+	sha256    string
+	required  bool
+}
+
+// NewVerifier creates a Verifier. publicKeyFile is a PEM public key; when set, every
+// catalog must carry a valid signature. pinnedSHA256 overrides any published hash.
+// If required is set, catalogs with no signature or hash to check are rejected.
+func NewVerifier(publicKeyFile, pinnedSHA256 string, required bool) (*Verifier, error) {
+	v := &Verifier{
+		sha256:   strings.ToLower(strings.TrimSpace(pinnedSHA256)),
+		required: required,
+	}
+
+	if publicKeyFile != "" {
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog public key: %w", err)
+		}
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKey = key
+	}
+
+	return v, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("catalog public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse catalog public key: %w", err)
+	}
+	return key, nil
+}
+
+// Verify checks a payload, returning the verification result and a non-nil error
+// (wrapping ErrVerification) if the payload must not be used. A nil Verifier
+// accepts everything unverified.
+func (v *Verifier) Verify(p *Payload) (Verification, error) {
+	digest := sha256.Sum256(p.Data)
+	return v.verify(p, p.Data, digest[:])
+}
+
+// needsData returns true if verification needs the whole catalog rather than
+// just its SHA-256 digest, as Ed25519 signs the catalog itself
+func (v *Verifier) needsData() bool {
+	if v == nil {
+		return false
+	}
+	_, ok := v.publicKey.(ed25519.PublicKey)
+	return ok
+}
+
+// verify checks a payload given its SHA-256 digest, for payloads that were
+// streamed rather than held in Data. data is only used if needsData.
+func (v *Verifier) verify(p *Payload, data, digest []byte) (Verification, error) {
+	if v == nil {
+		return Verification{Method: VerifyNone}, nil
+	}
+
+	var methods []string
+
+	if v.publicKey != nil {
+		if len(p.Signature) == 0 {
+			return v.fail(VerifySignature, "no signature published")
+		}
+		if err := verifySignature(v.publicKey, data, digest, p.Signature); err != nil {
+			return v.fail(VerifySignature, err.Error())
+		}
+		methods = append(methods, VerifySignature)
+	}
+
+	expected := v.sha256
+	if expected == "" && isSHA256Hex(p.Hash) {
+		expected = p.Hash
+	}
+	if expected != "" {
+		actual := hex.EncodeToString(digest)
+		if subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) != 1 {
+			return v.fail(VerifySHA256, fmt.Sprintf("sha256 mismatch: expected %s, got %s", expected, actual))
+		}
+		methods = append(methods, VerifySHA256)
+	}
+
+	if len(methods) == 0 {
+		if v.required {
+			return v.fail(VerifyNone, "no signature or hash available")
+		}
+		return Verification{Method: VerifyNone}, nil
+	}
+
+	return Verification{Method: strings.Join(methods, "+"), Verified: true}, nil
+}
+
+func (v *Verifier) fail(method, detail string) (Verification, error) {
+	return Verification{Method: method, Detail: detail}, fmt.Errorf("%w: %s", ErrVerification, detail)
+}
+
+func verifySignature(key crypto.PublicKey, data, digest, sig []byte) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig); err != nil {
+			return errors.New("invalid RSA signature")
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, sig) {
+			return errors.New("invalid ECDSA signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, data, sig) {
+			return errors.New("invalid Ed25519 signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return nil
+}
+
+func isSHA256Hex(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,114 @@
+package catalog
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePublicKey(t *testing.T, pub crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "catalog.pub")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+	return path
+}
+
+func TestVerifier_Signature(t *testing.T) {
+	data := []byte("<Manifest/>")
+	digest := sha256.Sum256(data)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	rsaSig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	edSig := ed25519.Sign(edPriv, data)
+
+	tests := []struct {
+		name    string
+		key     crypto.PublicKey
+		payload Payload
+		wantErr bool
+	}{
+		{"rsa valid", &rsaKey.PublicKey, Payload{Data: data, Signature: rsaSig}, false},
+		{"rsa tampered", &rsaKey.PublicKey, Payload{Data: []byte("<Manifest></Manifest>"), Signature: rsaSig}, true},
+		{"ed25519 valid", edPub, Payload{Data: data, Signature: edSig}, false},
+		{"missing signature", edPub, Payload{Data: data}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVerifier(writePublicKey(t, tt.key), "", false)
+			if err != nil {
+				t.Fatalf("NewVerifier: %v", err)
+			}
+			result, err := v.Verify(&tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrVerification) {
+				t.Errorf("expected ErrVerification, got %v", err)
+			}
+			if result.Verified == tt.wantErr {
+				t.Errorf("Verified = %v, wantErr %v", result.Verified, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifier_Hash(t *testing.T) {
+	data := []byte("<Manifest/>")
+	sum := sha256.Sum256(data)
+	good := hex.EncodeToString(sum[:])
+	bad := hex.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name       string
+		pinned     string
+		required   bool
+		published  string
+		wantErr    bool
+		wantMethod string
+	}{
+		{"published hash", "", false, good, false, VerifySHA256},
+		{"published mismatch", "", false, bad, true, VerifySHA256},
+		{"pinned overrides published", good, false, bad, false, VerifySHA256},
+		{"pinned mismatch", bad, false, good, true, VerifySHA256},
+		{"garbage published hash ignored", "", false, "<html>", false, VerifyNone},
+		{"nothing to check", "", false, "", false, VerifyNone},
+		{"nothing to check but required", "", true, "", true, VerifyNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVerifier("", tt.pinned, tt.required)
+			if err != nil {
+				t.Fatalf("NewVerifier: %v", err)
+			}
+			result, err := v.Verify(&Payload{Data: data, Hash: tt.published})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("Method = %q, want %q", result.Method, tt.wantMethod)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Package changes detects what changed on a node between two polls
+package changes
+
+import (
+	"fmt"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// Detect compares two snapshots of the same node and returns an event for each
+// change worth recording. Data missing from either snapshot is not a change, so
+// a subsystem that failed to poll does not produce events.
+func Detect(previous, current models.Node) []models.ChangeEvent {
+	d := detector{node: current}
+	d.firmware(previous.Firmware, current.Firmware)
+	d.health(previous.Health, current.Health)
+	d.powerState(previous.PowerState, current.PowerState)
+	d.power(previous.PowerSummary, current.PowerSummary)
+	d.fans(previous.ThermalSummary, current.ThermalSummary)
+	d.disks(previous.Storage, current.Storage)
+	d.nics(previous.NetworkAdapters, current.NetworkAdapters)
+	d.updates(previous, current)
+	return d.events
+}
+
+type detector struct {
+	node   models.Node
+	events []models.ChangeEvent
+}
+
+func (d *detector) add(changeType models.ChangeType, severity models.HealthStatus, component, previous, current, message string) {
+	d.events = append(d.events, models.ChangeEvent{
+		Timestamp: d.node.LastScanned,
+		Type:      changeType,
+		Severity:  severity,
+		Component: component,
+		Previous:  previous,
+		Current:   current,
+		Message:   message,
+		NodeName:  d.node.Name,
+		Namespace: d.node.Namespace,
+	})
+}
+
+func (d *detector) firmware(previous, current []models.FirmwareComponent) {
+	before, after := firmwareByKey(previous), firmwareByKey(current)
+	for _, fw := range current {
+		key := firmwareKey(fw)
+		if _, unique := after[key]; !unique {
+			continue
+		}
+		old, ok := before[key]
+		if !ok || old.CurrentVersion == fw.CurrentVersion || old.CurrentVersion == "" || fw.CurrentVersion == "" {
+			continue
+		}
+		d.add(models.ChangeFirmwareVersion, models.HealthOK, fw.Name, old.CurrentVersion, fw.CurrentVersion,
+			fmt.Sprintf("%s firmware changed from %s to %s", fw.Name, old.CurrentVersion, fw.CurrentVersion))
+	}
+}
+
+func (d *detector) health(previous, current models.HealthStatus) {
+	if !knownHealth(previous) || !knownHealth(current) || previous == current {
+		return
+	}
+	d.add(models.ChangeHealth, current, "", string(previous), string(current),
+		fmt.Sprintf("Health changed from %s to %s", previous, current))
+}
+
+func (d *detector) powerState(previous, current models.PowerState) {
+	if previous == "" || current == "" || previous == models.PowerUnknown || current == models.PowerUnknown || previous == current {
+		return
+	}
+	d.add(models.ChangePowerState, models.HealthOK, "", string(previous), string(current),
+		fmt.Sprintf("Power state changed from %s to %s", previous, current))
+}
+
+func (d *detector) power(previous, current *models.PowerSummary) {
+	if previous == nil || current == nil {
+		return
+	}
+	if previous.Redundancy != "Lost" && current.Redundancy == "Lost" {
+		d.add(models.ChangePSURedundancyLost, models.HealthCritical, "", previous.Redundancy, current.Redundancy,
+			fmt.Sprintf("PSU redundancy lost: %d of %d power supplies healthy", current.PSUsHealthy, current.PSUCount))
+	}
+}
+
+func (d *detector) fans(previous, current *models.ThermalSummary) {
+	if previous == nil || current == nil || current.FansHealthy >= previous.FansHealthy {
+		return
+	}
+	d.add(models.ChangeFanFailed, models.HealthWarning, "",
+		fmt.Sprintf("%d/%d", previous.FansHealthy, previous.FanCount), fmt.Sprintf("%d/%d", current.FansHealthy, current.FanCount),
+		fmt.Sprintf("Healthy fans dropped from %d to %d of %d", previous.FansHealthy, current.FansHealthy, current.FanCount))
+}
+
+func (d *detector) disks(previous, current *models.StorageDetail) {
+	if previous == nil || current == nil {
+		return
+	}
+	before := make(map[string]models.Disk, len(previous.Disks))
+	for _, disk := range previous.Disks {
+		before[disk.SlotNumber+"/"+disk.Name] = disk
+	}
+	for _, disk := range current.Disks {
+		old, ok := before[disk.SlotNumber+"/"+disk.Name]
+		if ok && old.State != "Offline" && disk.State == "Offline" {
+			d.add(models.ChangeDiskOffline, models.HealthCritical, disk.Name, old.State, disk.State,
+				fmt.Sprintf("Disk %s went offline", disk.Name))
+		}
+	}
+}
+
+func (d *detector) nics(previous, current []models.NetworkAdapter) {
+	before := make(map[string]models.NetworkAdapter, len(previous))
+	for _, nic := range previous {
+		before[nicKey(nic)] = nic
+	}
+	for _, nic := range current {
+		old, ok := before[nicKey(nic)]
+		if ok && old.LinkStatus == "Up" && nic.LinkStatus == "Down" {
+			d.add(models.ChangeNICLinkDown, models.HealthWarning, nicName(nic), old.LinkStatus, nic.LinkStatus,
+				fmt.Sprintf("Link down on %s", nicName(nic)))
+		}
+	}
+}
+
+// updates reports each component that needs an update it did not need before,
+// or now needs a different version
+func (d *detector) updates(previous, current models.Node) {
+	if previous.Firmware == nil {
+		return
+	}
+	before, after := firmwareByKey(previous.Firmware), firmwareByKey(current.Firmware)
+	for _, fw := range current.Firmware {
+		key := firmwareKey(fw)
+		if _, unique := after[key]; !unique || !current.NeedsUpdate(&fw) {
+			continue
+		}
+		target := updateTarget(current, fw)
+		if old, ok := before[key]; ok && previous.NeedsUpdate(&old) && updateTarget(previous, old) == target {
+			continue
+		}
+		d.add(models.ChangeUpdateAvailable, models.HealthOK, fw.Name, fw.CurrentVersion, target,
+			fmt.Sprintf("Update available for %s: %s to %s", fw.Name, fw.CurrentVersion, target))
+	}
+}
+
+// updateTarget is the version a component should be updated to
+func updateTarget(node models.Node, fw models.FirmwareComponent) string {
+	if node.Baseline != "" {
+		return fw.TargetVersion
+	}
+	return fw.AvailableVersion
+}
+
+// firmwareByKey indexes components by what identifies them across versions.
+// Inventory IDs cannot be used as some BMCs include the version in them.
+// Components that cannot be told apart are left out.
+func firmwareByKey(firmware []models.FirmwareComponent) map[string]models.FirmwareComponent {
+	byKey := make(map[string]models.FirmwareComponent, len(firmware))
+	duplicate := make(map[string]bool)
+	for _, fw := range firmware {
+		key := firmwareKey(fw)
+		if _, ok := byKey[key]; ok {
+			duplicate[key] = true
+		}
+		byKey[key] = fw
+	}
+	for key := range duplicate {
+		delete(byKey, key)
+	}
+	return byKey
+}
+
+func firmwareKey(fw models.FirmwareComponent) string {
+	id := fw.Identity
+	return fw.Name + "|" + id.ComponentID + "|" + id.VendorID + ":" + id.DeviceID + ":" + id.SubVendorID + ":" + id.SubDeviceID
+}
+
+func knownHealth(h models.HealthStatus) bool {
+	return h == models.HealthOK || h == models.HealthWarning || h == models.HealthCritical
+}
+
+func nicKey(nic models.NetworkAdapter) string {
+	if nic.MACAddress != "" {
+		return nic.MACAddress
+	}
+	return nic.Name + "/" + nic.Port
+}
+
+func nicName(nic models.NetworkAdapter) string {
+	if nic.Port != "" {
+		return nic.Name + " " + nic.Port
+	}
+	return nic.Name
+}
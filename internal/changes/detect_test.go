@@ -0,0 +1,115 @@
+package changes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func baseNode() models.Node {
+	return models.Node{
+		Name:        "worker-0",
+		Namespace:   "ns-a",
+		LastScanned: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Health:      models.HealthOK,
+		PowerState:  models.PowerOn,
+		Firmware: []models.FirmwareComponent{
+			{ID: "Installed-159-1.0.0", Name: "BIOS", CurrentVersion: "1.0.0"},
+			{ID: "Installed-25227-6.10.00", Name: "iDRAC", CurrentVersion: "6.10.00"},
+		},
+		PowerSummary:   &models.PowerSummary{PSUCount: 2, PSUsHealthy: 2, Redundancy: "Full"},
+		ThermalSummary: &models.ThermalSummary{FanCount: 6, FansHealthy: 6},
+		Storage: &models.StorageDetail{Disks: []models.Disk{
+			{Name: "Disk 0", SlotNumber: "0", State: "Online"},
+		}},
+		NetworkAdapters: []models.NetworkAdapter{
+			{Name: "NIC.Integrated.1", Port: "1", LinkStatus: "Up", MACAddress: "aa:bb:cc:dd:ee:01"},
+		},
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(n *models.Node)
+		want     models.ChangeType
+		severity models.HealthStatus
+	}{
+		{"firmware version", func(n *models.Node) {
+			n.Firmware[0].ID = "Installed-159-1.1.0"
+			n.Firmware[0].CurrentVersion = "1.1.0"
+		}, models.ChangeFirmwareVersion, models.HealthOK},
+		{"health degraded", func(n *models.Node) { n.Health = models.HealthWarning }, models.ChangeHealth, models.HealthWarning},
+		{"power state", func(n *models.Node) { n.PowerState = models.PowerOff }, models.ChangePowerState, models.HealthOK},
+		{"PSU redundancy", func(n *models.Node) {
+			n.PowerSummary = &models.PowerSummary{PSUCount: 2, PSUsHealthy: 1, Redundancy: "Lost"}
+		}, models.ChangePSURedundancyLost, models.HealthCritical},
+		{"fan failed", func(n *models.Node) {
+			n.ThermalSummary = &models.ThermalSummary{FanCount: 6, FansHealthy: 5}
+		}, models.ChangeFanFailed, models.HealthWarning},
+		{"disk offline", func(n *models.Node) {
+			n.Storage = &models.StorageDetail{Disks: []models.Disk{{Name: "Disk 0", SlotNumber: "0", State: "Offline"}}}
+		}, models.ChangeDiskOffline, models.HealthCritical},
+		{"NIC link down", func(n *models.Node) {
+			n.NetworkAdapters = []models.NetworkAdapter{{Name: "NIC.Integrated.1", Port: "1", LinkStatus: "Down", MACAddress: "aa:bb:cc:dd:ee:01"}}
+		}, models.ChangeNICLinkDown, models.HealthWarning},
+		{"update available", func(n *models.Node) {
+			n.Firmware = append([]models.FirmwareComponent(nil), n.Firmware...)
+			n.Firmware[1].AvailableVersion = "7.00.00"
+		}, models.ChangeUpdateAvailable, models.HealthOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous, current := baseNode(), baseNode()
+			tt.change(&current)
+
+			events := Detect(previous, current)
+			if len(events) != 1 {
+				t.Fatalf("expected 1 event, got %+v", events)
+			}
+			e := events[0]
+			if e.Type != tt.want || e.Severity != tt.severity {
+				t.Errorf("expected %s/%s, got %s/%s", tt.want, tt.severity, e.Type, e.Severity)
+			}
+			if e.NodeName != "worker-0" || e.Namespace != "ns-a" || !e.Timestamp.Equal(current.LastScanned) || e.Message == "" {
+				t.Errorf("unexpected event: %+v", e)
+			}
+		})
+	}
+}
+
+func TestDetect_NoChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(n *models.Node)
+	}{
+		{"identical", func(n *models.Node) {}},
+		{"health unknown after a failed poll", func(n *models.Node) { n.Health = models.HealthUnknown }},
+		{"subsystems missing", func(n *models.Node) {
+			n.PowerSummary = nil
+			n.ThermalSummary = nil
+			n.Storage = nil
+			n.NetworkAdapters = nil
+			n.Firmware = nil
+		}},
+		{"fan recovered", func(n *models.Node) { n.ThermalSummary = &models.ThermalSummary{FanCount: 6, FansHealthy: 6} }},
+		{"ambiguous components", func(n *models.Node) {
+			n.Firmware = []models.FirmwareComponent{
+				{Name: "BIOS", CurrentVersion: "1.1.0"},
+				{Name: "BIOS", CurrentVersion: "1.2.0"},
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous, current := baseNode(), baseNode()
+			tt.change(&current)
+			if events := Detect(previous, current); len(events) != 0 {
+				t.Errorf("expected no events, got %+v", events)
+			}
+		})
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/url"
 	"strings"
+	"sync"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
@@ -26,31 +31,100 @@ var bmhGVR = schema.GroupVersionResource{
 type DiscoveredHost struct {
 	Name        string
 	Namespace   string
-	BMCAddress  string
+	BMCAddress  string // host, and port if not the default
+	BMC         models.BMCEndpoint
 	Credentials models.BMCCredentials
+	Status      HostStatus
+	KubeNode    *models.KubeNode // nil if no Node runs on the host
+	// Err is set if the host's BMC address or credentials could not be read.
+	// Such hosts are reported so the failure is visible, but cannot be polled.
+	Err error
+}
+
+// HostStatus is what Metal3 reports about a BareMetalHost in its status
+type HostStatus struct {
+	ProvisioningState string
+	OperationalStatus string
+	PoweredOn         *bool // nil if Metal3 has not reported a power state
+	ErrorMessage      string
+	Hardware          *models.HostHardware // nil until the host has been inspected
 }
 
 // Discoverer finds BareMetalHost resources and extracts BMC info
 type Discoverer struct {
-	dynamicClient     dynamic.Interface
-	kubeClient        kubernetes.Interface
-	namespace         string
+	dynamicClient      dynamic.Interface
+	kubeClient         kubernetes.Interface
+	namespace          string
 	watchAllNamespaces bool
+
+	caNamespace     string // cluster-wide CA bundle ConfigMap, see SetCABundle
+	caName          string
+	namespaceCAName string // per-namespace override, see SetNamespaceCABundle
+
+	mu       sync.RWMutex
+	hosts    cache.Store // BareMetalHost informer cache, set once Watch has synced
+	machines cache.Store // Machine informer cache, likewise; empty without the Machine API
+	nodes    cache.Store // Node informer cache, likewise
 }
 
 // NewDiscoverer creates a new BareMetalHost discoverer
 // If namespace is empty or watchAllNamespaces is true, it will search all namespaces
 func NewDiscoverer(dynamicClient dynamic.Interface, kubeClient kubernetes.Interface, namespace string, watchAllNamespaces bool) *Discoverer {
 	return &Discoverer{
-		dynamicClient:     dynamicClient,
-		kubeClient:        kubeClient,
-		namespace:         namespace,
+		dynamicClient:      dynamicClient,
+		kubeClient:         kubeClient,
+		namespace:          namespace,
 		watchAllNamespaces: watchAllNamespaces,
 	}
 }
 
 // Discover finds all BareMetalHost resources and returns their BMC info
 func (d *Discoverer) Discover(ctx context.Context) ([]DiscoveredHost, error) {
+	items, err := d.listHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Found %d BareMetalHost resources", len(items))
+
+	var hosts []DiscoveredHost
+	namespaceCount := make(map[string]int)
+	topo := d.loadTopology(ctx)
+	cas := d.newCABundles()
+
+	for _, item := range items {
+		host := d.hostInfo(ctx, item, topo, cas)
+		hosts = append(hosts, host)
+		namespaceCount[item.GetNamespace()]++
+	}
+
+	// Log summary of discovered hosts by namespace
+	if d.watchAllNamespaces || d.namespace == "" {
+		for ns, count := range namespaceCount {
+			log.Printf("  Namespace %s: %d hosts", ns, count)
+		}
+	}
+
+	return hosts, nil
+}
+
+// listHosts returns BareMetalHosts from the informer cache if Watch is running,
+// otherwise from the API server
+func (d *Discoverer) listHosts(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	d.mu.RLock()
+	hosts := d.hosts
+	d.mu.RUnlock()
+
+	if hosts != nil {
+		var items []*unstructured.Unstructured
+		for _, obj := range hosts.List() {
+			if item, ok := obj.(*unstructured.Unstructured); ok {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+
 	var list *unstructured.UnstructuredList
 	var err error
 
@@ -68,32 +142,83 @@ func (d *Discoverer) Discover(ctx context.Context) ([]DiscoveredHost, error) {
 		return nil, fmt.Errorf("failed to list BareMetalHosts: %w", err)
 	}
 
-	log.Printf("Found %d BareMetalHost resources", len(list.Items))
+	items := make([]*unstructured.Unstructured, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, &list.Items[i])
+	}
+	return items, nil
+}
 
-	var hosts []DiscoveredHost
-	namespaceCount := make(map[string]int)
+// GetHost fetches a single BareMetalHost and returns its BMC info and the
+// Kubernetes Node running on it
+func (d *Discoverer) GetHost(ctx context.Context, namespace, name string) (*DiscoveredHost, error) {
+	item, err := d.getHost(ctx, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BareMetalHost %s/%s: %w", namespace, name, err)
+	}
+	return d.extractHostInfo(ctx, item, d.loadTopology(ctx), d.newCABundles())
+}
 
-	for _, item := range list.Items {
-		host, err := d.extractHostInfo(ctx, &item)
-		if err != nil {
-			log.Printf("Warning: Failed to extract host info for %s/%s: %v", item.GetNamespace(), item.GetName(), err)
-			continue
+// Host is GetHost for hosts that are reported even if their BMC details cannot
+// be read, as Discover does; the error is then returned in the host's Err. ok
+// is false if the host no longer exists or could not be read.
+func (d *Discoverer) Host(ctx context.Context, namespace, name string) (host DiscoveredHost, ok bool) {
+	item, err := d.getHost(ctx, namespace, name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Printf("Warning: Failed to get BareMetalHost %s/%s: %v", namespace, name, err)
 		}
-		hosts = append(hosts, *host)
-		namespaceCount[item.GetNamespace()]++
+		return DiscoveredHost{}, false
 	}
+	return d.hostInfo(ctx, item, d.loadTopology(ctx), d.newCABundles()), true
+}
 
-	// Log summary of discovered hosts by namespace
-	if d.watchAllNamespaces || d.namespace == "" {
-		for ns, count := range namespaceCount {
-			log.Printf("  Namespace %s: %d hosts", ns, count)
-		}
+// getHost returns a BareMetalHost from the informer cache if Watch is running,
+// otherwise from the API server
+func (d *Discoverer) getHost(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	d.mu.RLock()
+	hosts := d.hosts
+	d.mu.RUnlock()
+
+	if hosts == nil {
+		return d.dynamicClient.Resource(bmhGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	obj, exists, err := hosts.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
 	}
+	item, ok := obj.(*unstructured.Unstructured)
+	if !exists || !ok {
+		return nil, apierrors.NewNotFound(bmhGVR.GroupResource(), name)
+	}
+	return item, nil
+}
 
-	return hosts, nil
+// hostInfo is extractHostInfo for hosts that are reported even if their BMC
+// details cannot be read; the error is then returned in the host's Err
+func (d *Discoverer) hostInfo(ctx context.Context, bmh *unstructured.Unstructured, topo *topology, cas *caBundles) DiscoveredHost {
+	host, err := d.extractHostInfo(ctx, bmh, topo, cas)
+	if err == nil {
+		return *host
+	}
+	log.Printf("Warning: Failed to extract host info for %s/%s: %v", bmh.GetNamespace(), bmh.GetName(), err)
+
+	address, _, _ := unstructured.NestedString(bmh.Object, "spec", "bmc", "address")
+	bmc := ParseBMCEndpoint(address)
+	return DiscoveredHost{
+		Name:       bmh.GetName(),
+		Namespace:  bmh.GetNamespace(),
+		BMCAddress: bmc.Address(),
+		BMC:        bmc,
+		Status:     parseHostStatus(bmh),
+		KubeNode:   topo.kubeNode(bmh),
+		Err:        err,
+	}
 }
 
-func (d *Discoverer) extractHostInfo(ctx context.Context, bmh *unstructured.Unstructured) (*DiscoveredHost, error) {
+// extractHostInfo reads a host's BMC details and status. The Kubernetes Node is
+// only resolved if topo is set.
+func (d *Discoverer) extractHostInfo(ctx context.Context, bmh *unstructured.Unstructured, topo *topology, cas *caBundles) (*DiscoveredHost, error) {
 	name := bmh.GetName()
 	namespace := bmh.GetNamespace()
 
@@ -102,6 +227,9 @@ func (d *Discoverer) extractHostInfo(ctx context.Context, bmh *unstructured.Unst
 	if err != nil || !found {
 		return nil, fmt.Errorf("BMC address not found for %s", name)
 	}
+	bmc := ParseBMCEndpoint(bmcAddress)
+	bmc.DisableCertificateVerification, _, _ = unstructured.NestedBool(bmh.Object, "spec", "bmc", "disableCertificateVerification")
+	bmc.CABundle = cas.forNamespace(ctx, namespace)
 
 	// Get credentials secret reference
 	secretName, found, err := unstructured.NestedString(bmh.Object, "spec", "bmc", "credentialsName")
@@ -118,11 +246,36 @@ func (d *Discoverer) extractHostInfo(ctx context.Context, bmh *unstructured.Unst
 	return &DiscoveredHost{
 		Name:        name,
 		Namespace:   namespace,
-		BMCAddress:  ParseBMCAddress(bmcAddress),
+		BMCAddress:  bmc.Address(),
+		BMC:         bmc,
 		Credentials: *creds,
+		Status:      parseHostStatus(bmh),
+		KubeNode:    topo.kubeNode(bmh),
 	}, nil
 }
 
+// parseHostStatus reads the provisioning state and inspected hardware from a
+// BareMetalHost's status. Missing or malformed fields are left empty.
+func parseHostStatus(bmh *unstructured.Unstructured) HostStatus {
+	var status HostStatus
+	status.ProvisioningState, _, _ = unstructured.NestedString(bmh.Object, "status", "provisioning", "state")
+	status.OperationalStatus, _, _ = unstructured.NestedString(bmh.Object, "status", "operationalStatus")
+	status.ErrorMessage, _, _ = unstructured.NestedString(bmh.Object, "status", "errorMessage")
+	if poweredOn, found, err := unstructured.NestedBool(bmh.Object, "status", "poweredOn"); err == nil && found {
+		status.PoweredOn = &poweredOn
+	}
+
+	if raw, found, err := unstructured.NestedMap(bmh.Object, "status", "hardware"); err == nil && found {
+		var hardware models.HostHardware
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &hardware); err != nil {
+			log.Printf("Warning: Failed to parse hardware status for %s/%s: %v", bmh.GetNamespace(), bmh.GetName(), err)
+		} else {
+			status.Hardware = &hardware
+		}
+	}
+	return status
+}
+
 func (d *Discoverer) getCredentials(ctx context.Context, namespace, secretName string) (*models.BMCCredentials, error) {
 	secret, err := d.kubeClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
 	if err != nil {
@@ -135,22 +288,55 @@ func (d *Discoverer) getCredentials(ctx context.Context, namespace, secretName s
 	}, nil
 }
 
-// ParseBMCAddress extracts the IP/hostname from various BMC address formats
-func ParseBMCAddress(address string) string {
-	// Handle various BMC address formats
-	// e.g., idrac-virtualmedia://192.168.1.100/redfish/v1/...
-	// e.g., redfish-virtualmedia://10.0.0.50/redfish/v1/...
-	// e.g., ipmi://192.168.1.100
-
-	if strings.Contains(address, "://") {
-		u, err := url.Parse(address)
-		if err == nil {
-			return u.Hostname()
+// redfishSystemsPath prefixes the system path in Redfish BMC addresses, e.g.
+// redfish://10.0.0.1/redfish/v1/Systems/System.Embedded.1
+const redfishSystemsPath = "/redfish/v1/Systems/"
+
+// ParseBMCEndpoint parses the BMC address formats Metal3 accepts
+func ParseBMCEndpoint(address string) models.BMCEndpoint {
+	// e.g., idrac-virtualmedia://192.168.1.100/redfish/v1/Systems/System.Embedded.1
+	// e.g., redfish+http://10.0.0.50:8000/redfish/v1/Systems/1
+	// e.g., ipmi://192.168.1.100:623
+	endpoint := models.BMCEndpoint{Scheme: "https", Host: address}
+	if !strings.Contains(address, "://") {
+		// A bare address is an IPMI BMC, so any port is not the Redfish one
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			endpoint.Host = host
 		}
+		return endpoint
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return endpoint
 	}
+	driver, transport, _ := strings.Cut(strings.ToLower(u.Scheme), "+")
+	endpoint.Driver = driver
+	endpoint.Host = u.Hostname()
 
-	// If no scheme, assume it's just an IP/hostname
-	return address
+	// Only Redfish drivers address the Redfish service itself. For the others
+	// (ipmi, idrac over WS-Man, ...) Redfish is on the default HTTPS port.
+	if !isRedfishDriver(driver) {
+		return endpoint
+	}
+	if transport == "http" {
+		endpoint.Scheme = "http"
+	}
+	endpoint.Port = u.Port()
+	if id, ok := strings.CutPrefix(strings.TrimSuffix(u.Path, "/"), redfishSystemsPath); ok && !strings.Contains(id, "/") {
+		endpoint.SystemID = id
+	}
+	return endpoint
+}
+
+// isRedfishDriver reports whether a Metal3 driver talks Redfish to the address
+func isRedfishDriver(driver string) bool {
+	return strings.Contains(driver, "redfish") || driver == "idrac-virtualmedia"
+}
+
+// ParseBMCAddress extracts the IP/hostname from various BMC address formats
+func ParseBMCAddress(address string) string {
+	return ParseBMCEndpoint(address).Host
 }
 
 // IsDellHardware checks if the manufacturer indicates Dell hardware
@@ -1,7 +1,16 @@
 package discovery
 
 import (
+	"context"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
 
 func TestParseBMCAddress(t *testing.T) {
@@ -25,6 +34,40 @@ func TestParseBMCAddress(t *testing.T) {
 	}
 }
 
+func TestParseBMCEndpoint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected models.BMCEndpoint
+	}{
+		{"idrac-virtualmedia://192.168.1.100/redfish/v1/Systems/System.Embedded.1",
+			models.BMCEndpoint{Driver: "idrac-virtualmedia", Scheme: "https", Host: "192.168.1.100", SystemID: "System.Embedded.1"}},
+		{"redfish://bmc.example.com:8443/redfish/v1/Systems/System.Embedded.2/",
+			models.BMCEndpoint{Driver: "redfish", Scheme: "https", Host: "bmc.example.com", Port: "8443", SystemID: "System.Embedded.2"}},
+		{"redfish+http://10.0.0.50:8000/redfish/v1/Systems/1",
+			models.BMCEndpoint{Driver: "redfish", Scheme: "http", Host: "10.0.0.50", Port: "8000", SystemID: "1"}},
+		{"redfish-virtualmedia+https://[fd00::5]/redfish/v1/",
+			models.BMCEndpoint{Driver: "redfish-virtualmedia", Scheme: "https", Host: "fd00::5"}},
+		{"idrac-redfish://10.0.0.51",
+			models.BMCEndpoint{Driver: "idrac-redfish", Scheme: "https", Host: "10.0.0.51"}},
+		// Ports of non-Redfish drivers belong to another protocol
+		{"ipmi://192.168.1.100:623",
+			models.BMCEndpoint{Driver: "ipmi", Scheme: "https", Host: "192.168.1.100"}},
+		{"idrac+http://192.168.1.101:80",
+			models.BMCEndpoint{Driver: "idrac", Scheme: "https", Host: "192.168.1.101"}},
+		{"192.168.1.100:623", models.BMCEndpoint{Scheme: "https", Host: "192.168.1.100"}},
+		{"192.168.1.100", models.BMCEndpoint{Scheme: "https", Host: "192.168.1.100"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := ParseBMCEndpoint(tt.input)
+			if result != tt.expected {
+				t.Errorf("ParseBMCEndpoint(%q) = %+v, want %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestIsDellHardware(t *testing.T) {
 	tests := []struct {
 		manufacturer string
@@ -47,3 +90,93 @@ func TestIsDellHardware(t *testing.T) {
 		})
 	}
 }
+
+func TestParseHostStatus(t *testing.T) {
+	bmh := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "worker-0", "namespace": "ns-a"},
+		"status": map[string]interface{}{
+			"operationalStatus": "error",
+			"errorMessage":      "BMC unreachable",
+			"poweredOn":         true,
+			"provisioning":      map[string]interface{}{"state": "provisioned"},
+			"hardware": map[string]interface{}{
+				"systemVendor": map[string]interface{}{
+					"manufacturer": "Dell Inc.",
+					"productName":  "PowerEdge R640 (SKU=0716;ModelName=PowerEdge R640)",
+					"serialNumber": "ABC1234",
+				},
+				"cpu":          map[string]interface{}{"arch": "x86_64", "model": "Intel Xeon Gold 6130", "clockMegahertz": 2100.0, "count": int64(64)},
+				"ramMebibytes": int64(196608),
+				"nics": []interface{}{
+					map[string]interface{}{"name": "eno1", "mac": "aa:bb:cc:dd:ee:ff", "speedGbps": int64(25)},
+				},
+				"storage": []interface{}{
+					map[string]interface{}{"name": "/dev/sda", "type": "SSD", "sizeBytes": int64(480103981056)},
+				},
+			},
+		},
+	}}
+
+	status := parseHostStatus(bmh)
+	if status.ProvisioningState != "provisioned" || status.OperationalStatus != "error" || status.ErrorMessage != "BMC unreachable" {
+		t.Errorf("unexpected state: %+v", status)
+	}
+	if status.PoweredOn == nil || !*status.PoweredOn {
+		t.Errorf("expected poweredOn true, got %v", status.PoweredOn)
+	}
+	hw := status.Hardware
+	if hw == nil {
+		t.Fatal("expected hardware")
+	}
+	if hw.SystemVendor.Model() != "PowerEdge R640" || hw.SystemVendor.SerialNumber != "ABC1234" {
+		t.Errorf("unexpected system vendor: %+v", hw.SystemVendor)
+	}
+	if hw.CPU.Count != 64 || hw.RAMMebibytes != 196608 {
+		t.Errorf("unexpected cpu/ram: %+v %d", hw.CPU, hw.RAMMebibytes)
+	}
+	if len(hw.NICs) != 1 || hw.NICs[0].SpeedGbps != 25 || len(hw.Storage) != 1 || hw.Storage[0].Type != "SSD" {
+		t.Errorf("unexpected nics/storage: %+v %+v", hw.NICs, hw.Storage)
+	}
+
+	// Hosts that have not been inspected or reported yet
+	empty := parseHostStatus(&unstructured.Unstructured{Object: map[string]interface{}{}})
+	if empty.PoweredOn != nil || empty.Hardware != nil || empty.ProvisioningState != "" {
+		t.Errorf("expected empty status, got %+v", empty)
+	}
+}
+
+func TestDiscoverer_Discover_Errors(t *testing.T) {
+	noAddress := testBMH("ns-a", "worker-2", "", "worker-2-bmc")
+	unstructured.RemoveNestedField(noAddress.Object, "spec", "bmc", "address")
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{bmhGVR: "BareMetalHostList", machineGVR: "MachineList"},
+		testBMH("ns-a", "worker-0", "redfish://10.0.0.1/redfish/v1/Systems/1", "worker-0-bmc"),
+		testBMH("ns-a", "worker-1", "redfish://10.0.0.2/redfish/v1/Systems/1", "missing-bmc"),
+		noAddress,
+	)
+	kubeClient := fake.NewClientset(testSecret("ns-a", "worker-0-bmc", "calvin"))
+
+	hosts, err := NewDiscoverer(dynamicClient, kubeClient, "", true).Discover(context.Background())
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+
+	// Hosts that cannot be polled are still reported, with the reason
+	byName := make(map[string]DiscoveredHost)
+	for _, host := range hosts {
+		byName[host.Name] = host
+	}
+	if len(byName) != 3 {
+		t.Fatalf("expected 3 hosts, got %d", len(byName))
+	}
+	if host := byName["worker-0"]; host.Err != nil || host.Credentials.Password != "calvin" {
+		t.Errorf("unexpected worker-0: %+v", host)
+	}
+	if host := byName["worker-1"]; host.Err == nil || host.BMCAddress != "10.0.0.2" {
+		t.Errorf("expected credentials error with address for worker-1, got %+v", host)
+	}
+	if host := byName["worker-2"]; host.Err == nil || host.BMCAddress != "" {
+		t.Errorf("expected address error for worker-2, got %+v", host)
+	}
+}
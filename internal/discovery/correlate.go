@@ -0,0 +1,217 @@
+package discovery
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+var machineGVR = schema.GroupVersionResource{
+	Group:    "machine.openshift.io",
+	Version:  "v1beta1",
+	Resource: "machines",
+}
+
+const (
+	// machineAnnotation is set on Nodes to the "namespace/name" of their Machine
+	machineAnnotation = "machine.openshift.io/machine"
+	// roleLabelPrefix prefixes the node-role labels, e.g. node-role.kubernetes.io/master
+	roleLabelPrefix = "node-role.kubernetes.io/"
+	// bareMetalProviderPrefix prefixes the providerID of Nodes backed by a
+	// BareMetalHost: baremetalhost:///<namespace>/<name>/<uid>
+	bareMetalProviderPrefix = "baremetalhost:///"
+)
+
+// topology maps BareMetalHosts to the Machines consuming them and the Nodes
+// those Machines became
+type topology struct {
+	machineNodes map[string]string // Machine "namespace/name" -> status.nodeRef.name
+	nodes        map[string]*corev1.Node
+	byMachine    map[string]*corev1.Node // by machineAnnotation
+	byHost       map[string]*corev1.Node // by BareMetalHost "namespace/name" in the providerID
+}
+
+// loadTopology reads Machines and Nodes once so every host can be correlated
+// without further API calls. They are read from the informer caches once
+// Watch has synced them, otherwise listed. Either list may fail, e.g. on
+// clusters without the Machine API; hosts are then reported without a Node.
+func (d *Discoverer) loadTopology(ctx context.Context) *topology {
+	t := &topology{
+		machineNodes: make(map[string]string),
+		nodes:        make(map[string]*corev1.Node),
+		byMachine:    make(map[string]*corev1.Node),
+		byHost:       make(map[string]*corev1.Node),
+	}
+
+	for _, m := range d.listMachines(ctx) {
+		nodeName, _, _ := unstructured.NestedString(m.Object, "status", "nodeRef", "name")
+		if nodeName != "" {
+			t.machineNodes[m.GetNamespace()+"/"+m.GetName()] = nodeName
+		}
+	}
+
+	for _, node := range d.listNodes(ctx) {
+		t.nodes[node.Name] = node
+		if machine := node.Annotations[machineAnnotation]; machine != "" {
+			t.byMachine[machine] = node
+		}
+		if host, ok := providerHost(node.Spec.ProviderID); ok {
+			t.byHost[host] = node
+		}
+	}
+	return t
+}
+
+// listMachines returns the Machines from the informer cache if Watch has
+// synced it, otherwise from the API server
+func (d *Discoverer) listMachines(ctx context.Context) []*unstructured.Unstructured {
+	d.mu.RLock()
+	machines := d.machines
+	d.mu.RUnlock()
+
+	if machines != nil {
+		cached := machines.List()
+		items := make([]*unstructured.Unstructured, 0, len(cached))
+		for _, obj := range cached {
+			if m, ok := obj.(*unstructured.Unstructured); ok {
+				items = append(items, m)
+			}
+		}
+		return items
+	}
+
+	list, err := d.dynamicClient.Resource(machineGVR).Namespace(d.listNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Warning: Failed to list Machines, using Node annotations only: %v", err)
+		return nil
+	}
+	items := make([]*unstructured.Unstructured, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, &list.Items[i])
+	}
+	return items
+}
+
+// listNodes returns the Nodes from the informer cache if Watch has synced it,
+// otherwise from the API server
+func (d *Discoverer) listNodes(ctx context.Context) []*corev1.Node {
+	d.mu.RLock()
+	nodes := d.nodes
+	d.mu.RUnlock()
+
+	if nodes != nil {
+		cached := nodes.List()
+		items := make([]*corev1.Node, 0, len(cached))
+		for _, obj := range cached {
+			if node, ok := obj.(*corev1.Node); ok {
+				items = append(items, node)
+			}
+		}
+		return items
+	}
+
+	list, err := d.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Warning: Failed to list Nodes, hosts will not be correlated: %v", err)
+		return nil
+	}
+	items := make([]*corev1.Node, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, &list.Items[i])
+	}
+	return items
+}
+
+// listNamespace returns the namespace to list in, or all namespaces
+func (d *Discoverer) listNamespace() string {
+	if d.watchAllNamespaces {
+		return metav1.NamespaceAll
+	}
+	return d.namespace
+}
+
+// kubeNode finds the Node running on a BareMetalHost: via the consumer
+// Machine's nodeRef, else the Node annotated with that Machine, else the Node
+// whose providerID names the host
+func (t *topology) kubeNode(bmh *unstructured.Unstructured) *models.KubeNode {
+	if t == nil {
+		return nil
+	}
+
+	machine := consumerMachine(bmh)
+	var node *corev1.Node
+	if machine != "" {
+		if name, ok := t.machineNodes[machine]; ok {
+			node = t.nodes[name]
+		}
+		if node == nil {
+			node = t.byMachine[machine]
+		}
+	}
+	if node == nil {
+		node = t.byHost[bmh.GetNamespace()+"/"+bmh.GetName()]
+	}
+	if node == nil {
+		return nil
+	}
+
+	info := newKubeNode(node)
+	info.Machine = machine
+	return info
+}
+
+// consumerMachine returns the "namespace/name" of the Machine consuming a host
+func consumerMachine(bmh *unstructured.Unstructured) string {
+	ref, found, err := unstructured.NestedStringMap(bmh.Object, "spec", "consumerRef")
+	if err != nil || !found || ref["kind"] != "Machine" || ref["name"] == "" {
+		return ""
+	}
+	namespace := ref["namespace"]
+	if namespace == "" {
+		namespace = bmh.GetNamespace()
+	}
+	return namespace + "/" + ref["name"]
+}
+
+// providerHost extracts the BareMetalHost "namespace/name" from a Node providerID
+func providerHost(providerID string) (string, bool) {
+	rest, ok := strings.CutPrefix(providerID, bareMetalProviderPrefix)
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return parts[0] + "/" + parts[1], true
+}
+
+// newKubeNode summarises the scheduling state of a Node
+func newKubeNode(node *corev1.Node) *models.KubeNode {
+	info := &models.KubeNode{
+		Name:           node.Name,
+		Roles:          []string{},
+		Unschedulable:  node.Spec.Unschedulable,
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+	}
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, roleLabelPrefix); ok && role != "" {
+			info.Roles = append(info.Roles, role)
+		}
+	}
+	sort.Strings(info.Roles)
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			info.Ready = cond.Status == corev1.ConditionTrue
+		}
+	}
+	return info
+}
@@ -0,0 +1,127 @@
+package discovery
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func testMachine(namespace, name, nodeName string) *unstructured.Unstructured {
+	m := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machine.openshift.io/v1beta1",
+		"kind":       "Machine",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+	}}
+	if nodeName != "" {
+		m.Object["status"] = map[string]interface{}{
+			"nodeRef": map[string]interface{}{"kind": "Node", "name": nodeName},
+		}
+	}
+	return m
+}
+
+func testNode(name string, ready bool, labels, annotations map[string]string, providerID string) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: "v1.29.5+29c95f3"},
+		},
+	}
+}
+
+func withConsumer(bmh *unstructured.Unstructured, machine string) *unstructured.Unstructured {
+	bmh.Object["spec"].(map[string]interface{})["consumerRef"] = map[string]interface{}{
+		"apiVersion": "machine.openshift.io/v1beta1",
+		"kind":       "Machine",
+		"name":       machine,
+		"namespace":  bmh.GetNamespace(),
+	}
+	return bmh
+}
+
+func TestDiscoverer_KubeNode(t *testing.T) {
+	const ns = "openshift-machine-api"
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{bmhGVR: "BareMetalHostList", machineGVR: "MachineList"},
+		// nodeRef on the Machine
+		withConsumer(testBMH(ns, "master-0", "10.0.0.1", "bmc"), "ocp-master-0"),
+		testMachine(ns, "ocp-master-0", "master-0.example.com"),
+		// Machine without nodeRef yet, Node annotated with the Machine
+		withConsumer(testBMH(ns, "worker-0", "10.0.0.2", "bmc"), "ocp-worker-0"),
+		testMachine(ns, "ocp-worker-0", ""),
+		// No Machine, Node found by providerID
+		testBMH(ns, "worker-1", "10.0.0.3", "bmc"),
+		// Not provisioned
+		testBMH(ns, "spare-0", "10.0.0.4", "bmc"),
+	)
+	kubeClient := fake.NewClientset(
+		testSecret(ns, "bmc", "calvin"),
+		testNode("master-0.example.com", true,
+			map[string]string{"node-role.kubernetes.io/master": "", "node-role.kubernetes.io/control-plane": ""}, nil, ""),
+		testNode("worker-0.example.com", false,
+			map[string]string{"node-role.kubernetes.io/worker": ""},
+			map[string]string{machineAnnotation: ns + "/ocp-worker-0"}, ""),
+		testNode("worker-1.example.com", true,
+			map[string]string{"node-role.kubernetes.io/worker": ""}, nil,
+			"baremetalhost:///"+ns+"/worker-1/2f1e0e3c-3e7b-4c0a-9b8e-0c3f0f4b1a2d"),
+	)
+	cordoned, _ := kubeClient.CoreV1().Nodes().Get(context.Background(), "worker-1.example.com", metav1.GetOptions{})
+	cordoned.Spec.Unschedulable = true
+	if _, err := kubeClient.CoreV1().Nodes().Update(context.Background(), cordoned, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("cordon: %v", err)
+	}
+
+	d := NewDiscoverer(dynamicClient, kubeClient, ns, false)
+	hosts, err := d.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	byName := make(map[string]DiscoveredHost)
+	for _, h := range hosts {
+		byName[h.Name] = h
+	}
+
+	tests := []struct {
+		host string
+		want *models.KubeNode
+	}{
+		{"master-0", &models.KubeNode{Name: "master-0.example.com", Machine: ns + "/ocp-master-0", Roles: []string{"control-plane", "master"}, Ready: true}},
+		{"worker-0", &models.KubeNode{Name: "worker-0.example.com", Machine: ns + "/ocp-worker-0", Roles: []string{"worker"}}},
+		{"worker-1", &models.KubeNode{Name: "worker-1.example.com", Roles: []string{"worker"}, Ready: true, Unschedulable: true}},
+		{"spare-0", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got := byName[tt.host].KubeNode
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected no node, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("expected a node")
+			}
+			tt.want.KubeletVersion = "v1.29.5+29c95f3"
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"context"
+	"log"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// caBundleKey is the ConfigMap key holding a PEM CA bundle, as filled in by
+// OpenShift's trusted CA injection
+const caBundleKey = "ca-bundle.crt"
+
+// SetCABundle trusts the CAs in the named ConfigMap for every BMC, in addition
+// to the system roots
+func (d *Discoverer) SetCABundle(namespace, name string) {
+	d.caNamespace = namespace
+	d.caName = name
+}
+
+// SetNamespaceCABundle makes the ConfigMap of this name in a host's namespace,
+// if it exists, override the cluster-wide CA bundle for the hosts in that namespace
+func (d *Discoverer) SetNamespaceCABundle(name string) {
+	d.namespaceCAName = name
+}
+
+// caBundles resolves the CA bundle for each namespace, reading each ConfigMap
+// at most once per discovery pass
+type caBundles struct {
+	d           *Discoverer
+	cluster     *string
+	byNamespace map[string]string
+}
+
+func (d *Discoverer) newCABundles() *caBundles {
+	return &caBundles{d: d, byNamespace: make(map[string]string)}
+}
+
+// forNamespace returns the PEM CA bundle to trust for hosts in a namespace
+func (c *caBundles) forNamespace(ctx context.Context, namespace string) string {
+	if bundle, ok := c.byNamespace[namespace]; ok {
+		return bundle
+	}
+
+	bundle, found := "", false
+	if c.d.namespaceCAName != "" {
+		bundle, found = c.d.readCABundle(ctx, namespace, c.d.namespaceCAName)
+	}
+	if !found {
+		bundle = c.clusterBundle(ctx)
+	}
+	c.byNamespace[namespace] = bundle
+	return bundle
+}
+
+func (c *caBundles) clusterBundle(ctx context.Context) string {
+	if c.cluster == nil {
+		bundle := ""
+		if c.d.caName != "" {
+			var found bool
+			if bundle, found = c.d.readCABundle(ctx, c.d.caNamespace, c.d.caName); !found {
+				log.Printf("Warning: CA bundle ConfigMap %s/%s not found, using system roots only", c.d.caNamespace, c.d.caName)
+			}
+		}
+		c.cluster = &bundle
+	}
+	return *c.cluster
+}
+
+// readCABundle reads a ConfigMap's CA bundle. found is false if the ConfigMap
+// does not exist or cannot be read.
+func (d *Discoverer) readCABundle(ctx context.Context, namespace, name string) (bundle string, found bool) {
+	cm, err := d.kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Printf("Warning: Failed to read CA bundle ConfigMap %s/%s: %v", namespace, name, err)
+		}
+		return "", false
+	}
+	bundle, ok := cm.Data[caBundleKey]
+	if !ok {
+		log.Printf("Warning: CA bundle ConfigMap %s/%s has no %s key", namespace, name, caBundleKey)
+	}
+	return bundle, ok
+}
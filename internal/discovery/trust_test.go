@@ -0,0 +1,69 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testCABundle(namespace, name, bundle string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string]string{caBundleKey: bundle},
+	}
+}
+
+func TestDiscoverer_CABundles(t *testing.T) {
+	insecure := testBMH("ns-b", "worker-1", "redfish://10.0.0.2/redfish/v1/Systems/1", "worker-1-bmc")
+	insecure.Object["spec"].(map[string]interface{})["bmc"].(map[string]interface{})["disableCertificateVerification"] = true
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{bmhGVR: "BareMetalHostList", machineGVR: "MachineList"},
+		testBMH("ns-a", "worker-0", "redfish://10.0.0.1/redfish/v1/Systems/1", "worker-0-bmc"),
+		insecure,
+	)
+	kubeClient := fake.NewClientset(
+		testSecret("ns-a", "worker-0-bmc", "calvin"),
+		testSecret("ns-b", "worker-1-bmc", "calvin"),
+		testCABundle("insights", "bmc-ca", "cluster CA"),
+		testCABundle("ns-b", "bmc-ca-override", "ns-b CA"),
+	)
+
+	d := NewDiscoverer(dynamicClient, kubeClient, "", true)
+	d.SetCABundle("insights", "bmc-ca")
+	d.SetNamespaceCABundle("bmc-ca-override")
+
+	hosts, err := d.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	byName := make(map[string]DiscoveredHost)
+	for _, host := range hosts {
+		byName[host.Name] = host
+	}
+
+	// ns-a has no override, so uses the cluster bundle and verifies certificates
+	if bmc := byName["worker-0"].BMC; bmc.CABundle != "cluster CA" || bmc.DisableCertificateVerification {
+		t.Errorf("unexpected worker-0 endpoint: %+v", bmc)
+	}
+	if bmc := byName["worker-1"].BMC; bmc.CABundle != "ns-b CA" || !bmc.DisableCertificateVerification {
+		t.Errorf("unexpected worker-1 endpoint: %+v", bmc)
+	}
+
+	// Without a cluster bundle only namespaces with an override get one
+	d = NewDiscoverer(dynamicClient, kubeClient, "", true)
+	d.SetNamespaceCABundle("bmc-ca-override")
+	host, err := d.GetHost(context.Background(), "ns-a", "worker-0")
+	if err != nil {
+		t.Fatalf("get host: %v", err)
+	}
+	if host.BMC.CABundle != "" {
+		t.Errorf("expected no CA bundle, got %q", host.BMC.CABundle)
+	}
+}
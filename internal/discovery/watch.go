@@ -0,0 +1,278 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// credentialsIndex indexes BareMetalHosts by "namespace/credentialsName"
+const credentialsIndex = "credentials"
+
+// informerResync is how often the informers replay their caches. Changes are
+// delivered by watch, so this only guards against missed events.
+const informerResync = 10 * time.Minute
+
+// informerSyncTimeout bounds how long Watch waits for the initial list
+const informerSyncTimeout = 2 * time.Minute
+
+// credentialsSecretSelector selects the BMC credential Secrets. The
+// baremetal-operator labels each Secret a BareMetalHost references, including
+// one created after the host, which is then seen as added.
+const credentialsSecretSelector = "environment.metal3.io=baremetal"
+
+// HostHandler reacts to BareMetalHost changes observed by Watch. It is called
+// from the informers, so must not block; the host's details can be read with
+// Discoverer.Host.
+type HostHandler interface {
+	// HostChanged is called when a host is created, its BMC spec or reported
+	// status changes, or its credentials Secret is rotated
+	HostChanged(namespace, name string)
+	// HostDeleted is called when a host is removed
+	HostDeleted(namespace, name string)
+}
+
+// Watch starts informers on BareMetalHosts and their credential Secrets and
+// notifies handler of changes until ctx is cancelled. Once the caches have
+// synced, Discover serves hosts from the informer instead of listing them.
+// Machines and Nodes are cached too, so hosts are correlated with their Node
+// without listing either on every change.
+func (d *Discoverer) Watch(ctx context.Context, handler HostHandler) error {
+	namespace := d.listNamespace()
+
+	dynamicFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(d.dynamicClient, informerResync, namespace, nil)
+	hosts := dynamicFactory.ForResource(bmhGVR).Informer()
+	if err := hosts.AddIndexers(cache.Indexers{credentialsIndex: indexByCredentials}); err != nil {
+		return fmt.Errorf("failed to index BareMetalHosts: %w", err)
+	}
+
+	// Only Secret metadata is needed to notice rotation; drop the data to keep
+	// the cache small
+	secretFactory := informers.NewSharedInformerFactoryWithOptions(d.kubeClient, informerResync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = credentialsSecretSelector
+		}),
+		informers.WithTransform(stripSecretData),
+	)
+	secrets := secretFactory.Core().V1().Secrets().Informer()
+
+	// Without the Machine API, Nodes are correlated by annotation and providerID
+	var machines cache.SharedIndexInformer
+	if _, err := d.dynamicClient.Resource(machineGVR).Namespace(namespace).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		log.Printf("Warning: Cannot list Machines, using Node annotations only: %v", err)
+	} else {
+		machines = dynamicFactory.ForResource(machineGVR).Informer()
+	}
+	nodeFactory := informers.NewSharedInformerFactoryWithOptions(d.kubeClient, informerResync,
+		informers.WithTransform(stripNodeDetails),
+	)
+	nodes := nodeFactory.Core().V1().Nodes().Informer()
+
+	if _, err := hosts.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			// Hosts present at startup are picked up by the first full poll
+			if isInInitialList {
+				return
+			}
+			notifyChanged(handler, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldHost, ok1 := oldObj.(*unstructured.Unstructured)
+			newHost, ok2 := newObj.(*unstructured.Unstructured)
+			// Ignore resyncs and status updates that do not change what is reported
+			if ok1 && ok2 && oldHost.GetGeneration() == newHost.GetGeneration() && !statusChanged(oldHost, newHost) {
+				return
+			}
+			notifyChanged(handler, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			host, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			log.Printf("BareMetalHost %s/%s deleted", host.GetNamespace(), host.GetName())
+			handler.HostDeleted(host.GetNamespace(), host.GetName())
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to watch BareMetalHosts: %w", err)
+	}
+
+	// Re-poll the hosts using a Secret when it is rotated, or created after them
+	secretChanged := func(secret *corev1.Secret) {
+		key := secret.Namespace + "/" + secret.Name
+		users, err := hosts.GetIndexer().ByIndex(credentialsIndex, key)
+		if err != nil {
+			return
+		}
+		for _, obj := range users {
+			log.Printf("Credentials %s changed, re-polling BareMetalHost %s", key, cacheKey(obj))
+			notifyChanged(handler, obj)
+		}
+	}
+	if _, err := secrets.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if secret, ok := obj.(*corev1.Secret); ok && !isInInitialList {
+				secretChanged(secret)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSecret, ok1 := oldObj.(*corev1.Secret)
+			newSecret, ok2 := newObj.(*corev1.Secret)
+			if !ok1 || !ok2 || oldSecret.ResourceVersion == newSecret.ResourceVersion {
+				return
+			}
+			secretChanged(newSecret)
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to watch Secrets: %w", err)
+	}
+
+	// The informers run until ctx is done, or are stopped early if they never sync
+	watchCtx, stop := context.WithCancel(ctx)
+	context.AfterFunc(ctx, stop)
+	dynamicFactory.Start(watchCtx.Done())
+	secretFactory.Start(watchCtx.Done())
+	nodeFactory.Start(watchCtx.Done())
+
+	// Give up rather than block startup if e.g. the BareMetalHost CRD is missing
+	syncCtx, cancel := context.WithTimeout(watchCtx, informerSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), hosts.HasSynced, secrets.HasSynced) {
+		stop()
+		return errors.New("timed out waiting for BareMetalHost informer to sync")
+	}
+
+	d.mu.Lock()
+	d.hosts = hosts.GetStore()
+	d.mu.Unlock()
+
+	// Until Machines and Nodes are cached, e.g. if they cannot be watched,
+	// they are listed as before
+	go d.cacheTopology(watchCtx, machines, nodes)
+
+	log.Printf("Watching BareMetalHosts and credential Secrets")
+	return nil
+}
+
+// cacheTopology serves Machines and Nodes from their informers once synced.
+// machines is nil without the Machine API.
+func (d *Discoverer) cacheTopology(ctx context.Context, machines, nodes cache.SharedIndexInformer) {
+	synced := []cache.InformerSynced{nodes.HasSynced}
+	machineStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if machines != nil {
+		synced = append(synced, machines.HasSynced)
+		machineStore = machines.GetStore()
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return
+	}
+
+	d.mu.Lock()
+	d.machines = machineStore
+	d.nodes = nodes.GetStore()
+	d.mu.Unlock()
+	log.Printf("Watching Machines and Nodes")
+}
+
+// Exists reports whether a BareMetalHost is still present in the informer cache.
+// It returns true if Watch is not running, since nothing is known to be deleted.
+func (d *Discoverer) Exists(namespace, name string) bool {
+	d.mu.RLock()
+	hosts := d.hosts
+	d.mu.RUnlock()
+
+	if hosts == nil {
+		return true
+	}
+	_, exists, err := hosts.GetByKey(namespace + "/" + name)
+	return err != nil || exists
+}
+
+// notifyChanged passes a changed host to the handler. Its BMC details are
+// resolved by the handler, so the informer is not held up by API calls.
+func notifyChanged(handler HostHandler, obj interface{}) {
+	bmh, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	handler.HostChanged(bmh.GetNamespace(), bmh.GetName())
+}
+
+// reportedStatus lists the BareMetalHost status fields copied into the node
+var reportedStatus = [][]string{
+	{"status", "provisioning", "state"},
+	{"status", "operationalStatus"},
+	{"status", "poweredOn"},
+	{"status", "errorMessage"},
+	{"status", "hardware"},
+}
+
+// statusChanged reports whether any reported status field differs between two
+// versions of a host
+func statusChanged(oldHost, newHost *unstructured.Unstructured) bool {
+	for _, path := range reportedStatus {
+		oldValue, _, _ := unstructured.NestedFieldNoCopy(oldHost.Object, path...)
+		newValue, _, _ := unstructured.NestedFieldNoCopy(newHost.Object, path...)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexByCredentials indexes a BareMetalHost by the Secret holding its BMC credentials
+func indexByCredentials(obj interface{}) ([]string, error) {
+	bmh, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+	secretName, found, err := unstructured.NestedString(bmh.Object, "spec", "bmc", "credentialsName")
+	if err != nil || !found || secretName == "" {
+		return nil, nil
+	}
+	return []string{bmh.GetNamespace() + "/" + secretName}, nil
+}
+
+// stripNodeDetails drops the parts of a Node not used for correlation before it
+// is cached, such as its list of container images
+func stripNodeDetails(obj interface{}) (interface{}, error) {
+	if node, ok := obj.(*corev1.Node); ok {
+		node.ManagedFields = nil
+		node.Status.Images = nil
+		node.Status.VolumesAttached = nil
+		node.Status.VolumesInUse = nil
+	}
+	return obj, nil
+}
+
+// stripSecretData drops Secret contents before they are cached
+func stripSecretData(obj interface{}) (interface{}, error) {
+	if secret, ok := obj.(*corev1.Secret); ok {
+		secret.Data = nil
+		secret.StringData = nil
+		secret.ManagedFields = nil
+	}
+	return obj, nil
+}
+
+func cacheKey(obj interface{}) string {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return "<unknown>"
+	}
+	return key
+}
@@ -0,0 +1,219 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// recordingHandler collects host notifications as "namespace/name"
+type recordingHandler struct {
+	changed chan string
+	deleted chan string
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{
+		changed: make(chan string, 10),
+		deleted: make(chan string, 10),
+	}
+}
+
+func (h *recordingHandler) HostChanged(namespace, name string) { h.changed <- namespace + "/" + name }
+
+func (h *recordingHandler) HostDeleted(namespace, name string) { h.deleted <- namespace + "/" + name }
+
+func testBMH(namespace, name, address, secret string) *unstructured.Unstructured {
+	bmh := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metal3.io/v1alpha1",
+		"kind":       "BareMetalHost",
+		"metadata": map[string]interface{}{
+			"name":       name,
+			"namespace":  namespace,
+			"generation": int64(1),
+		},
+		"spec": map[string]interface{}{
+			"bmc": map[string]interface{}{
+				"address":         address,
+				"credentialsName": secret,
+			},
+		},
+	}}
+	return bmh
+}
+
+func testSecret(namespace, name, password string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"environment.metal3.io": "baremetal"},
+		},
+		Data: map[string][]byte{
+			"username": []byte("root"),
+			"password": []byte(password),
+		},
+	}
+}
+
+func TestDiscoverer_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{bmhGVR: "BareMetalHostList", machineGVR: "MachineList"},
+		withConsumer(testBMH("ns-a", "worker-0", "idrac-virtualmedia://10.0.0.1/redfish/v1/Systems/System.Embedded.1", "worker-0-bmc"), "ocp-worker-0"),
+		testMachine("ns-a", "ocp-worker-0", "worker-0.example.com"),
+	)
+	kubeClient := fake.NewClientset(
+		testSecret("ns-a", "worker-0-bmc", "calvin"),
+		testNode("worker-0.example.com", true, nil, nil, ""),
+	)
+
+	// Fake clients drop events sent before a watch starts, so wait for the
+	// BareMetalHost, Machine, Secret and Node watches
+	watching := make(chan struct{}, 4)
+	signalWatch := func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watching <- struct{}{}
+		return false, nil, nil
+	}
+	dynamicClient.PrependWatchReactor("*", signalWatch)
+	kubeClient.PrependWatchReactor("*", signalWatch)
+
+	d := NewDiscoverer(dynamicClient, kubeClient, "", true)
+	h := newRecordingHandler()
+	if err := d.Watch(ctx, h); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	for range 4 {
+		<-watching
+	}
+
+	// Existing hosts are served from the cache and not reported as changes
+	hosts, err := d.Discover(ctx)
+	if err != nil || len(hosts) != 1 || hosts[0].BMCAddress != "10.0.0.1" {
+		t.Fatalf("discover = %+v, %v", hosts, err)
+	}
+	select {
+	case key := <-h.changed:
+		t.Fatalf("unexpected change for existing host %s", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Once cached, Machines and Nodes are not listed to correlate a host
+	waitTopologyCached(t, d)
+	kubeClient.ClearActions()
+	dynamicClient.ClearActions()
+	host, ok := d.Host(ctx, "ns-a", "worker-0")
+	if !ok || host.KubeNode == nil || host.KubeNode.Name != "worker-0.example.com" {
+		t.Fatalf("unexpected host: %+v", host)
+	}
+	for _, action := range append(kubeClient.Actions(), dynamicClient.Actions()...) {
+		if action.GetVerb() == "list" {
+			t.Errorf("unexpected list of %s", action.GetResource().Resource)
+		}
+	}
+
+	// A new host is reported immediately
+	if _, err := kubeClient.CoreV1().Secrets("ns-a").Create(ctx, testSecret("ns-a", "worker-1-bmc", "secret"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create secret: %v", err)
+	}
+	if _, err := dynamicClient.Resource(bmhGVR).Namespace("ns-a").Create(ctx, testBMH("ns-a", "worker-1", "10.0.0.2", "worker-1-bmc"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create host: %v", err)
+	}
+	if host := waitChanged(t, d, h, "ns-a", "worker-1"); host.BMCAddress != "10.0.0.2" {
+		t.Errorf("unexpected new host: %+v", host)
+	}
+
+	// A rotated password is picked up for the hosts using the Secret. The fake
+	// tracker does not bump resource versions, so set one as the API server would.
+	rotated := testSecret("ns-a", "worker-0-bmc", "rotated")
+	rotated.ResourceVersion = "2"
+	if _, err := kubeClient.CoreV1().Secrets("ns-a").Update(ctx, rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	if host := waitChanged(t, d, h, "ns-a", "worker-0"); host.Credentials.Password != "rotated" {
+		t.Errorf("unexpected rotated host: %+v", host)
+	}
+
+	// A provisioning state change is reported, even though the spec is unchanged
+	provisioned := testBMH("ns-a", "worker-0", "idrac-virtualmedia://10.0.0.1/redfish/v1/Systems/System.Embedded.1", "worker-0-bmc")
+	provisioned.Object["status"] = map[string]interface{}{"provisioning": map[string]interface{}{"state": "provisioned"}}
+	if _, err := dynamicClient.Resource(bmhGVR).Namespace("ns-a").Update(ctx, provisioned, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update host status: %v", err)
+	}
+	if host := waitChanged(t, d, h, "ns-a", "worker-0"); host.Status.ProvisioningState != "provisioned" {
+		t.Errorf("unexpected status change: %+v", host.Status)
+	}
+
+	// A deleted host is reported and no longer exists
+	if err := dynamicClient.Resource(bmhGVR).Namespace("ns-a").Delete(ctx, "worker-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete host: %v", err)
+	}
+	select {
+	case key := <-h.deleted:
+		if key != "ns-a/worker-1" {
+			t.Errorf("deleted %s, want ns-a/worker-1", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delete")
+	}
+	if d.Exists("ns-a", "worker-1") {
+		t.Error("deleted host should not exist")
+	}
+	if !d.Exists("ns-a", "worker-0") {
+		t.Error("remaining host should exist")
+	}
+}
+
+// waitChanged waits for a change to be reported for the named host and returns
+// its details. Other hosts may be reported too, e.g. when a Secret is created
+// after the host using it.
+func waitChanged(t *testing.T, d *Discoverer, h *recordingHandler, namespace, name string) DiscoveredHost {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case key := <-h.changed:
+			if key != namespace+"/"+name {
+				continue
+			}
+			host, ok := d.Host(context.Background(), namespace, name)
+			if !ok {
+				t.Fatalf("changed host %s not found", key)
+			}
+			return host
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s to change", name)
+			return DiscoveredHost{}
+		}
+	}
+}
+
+// waitTopologyCached waits until Watch serves Machines and Nodes from its caches
+func waitTopologyCached(t *testing.T, d *Discoverer) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		d.mu.RLock()
+		cached := d.machines != nil && d.nodes != nil
+		d.mu.RUnlock()
+		if cached {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for Machines and Nodes to be cached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -13,15 +13,20 @@ var (
 			Name: "firmware_scan_total",
 			Help: "Total number of firmware scan operations",
 		},
-		[]string{"node", "status"},
+		[]string{"namespace", "node", "status"},
 	)
 )
 
 // RecordScan increments the scan counter for a node
-func RecordScan(node string, success bool) {
+func RecordScan(namespace, node string, success bool) {
 	status := "success"
 	if !success {
 		status = "error"
 	}
-	FirmwareScanTotal.WithLabelValues(node, status).Inc()
+	FirmwareScanTotal.WithLabelValues(namespace, node, status).Inc()
+}
+
+// DeleteNode removes all series for a node that no longer exists
+func DeleteNode(namespace, node string) {
+	FirmwareScanTotal.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "node": node})
 }
@@ -11,19 +11,38 @@ func TestRecordScan(t *testing.T) {
 	// Reset counter for test isolation
 	FirmwareScanTotal.Reset()
 
-	RecordScan("worker-0", true)
-	RecordScan("worker-0", true)
-	RecordScan("worker-1", false)
+	RecordScan("cluster-a", "worker-0", true)
+	RecordScan("cluster-a", "worker-0", true)
+	RecordScan("cluster-a", "worker-1", false)
+	RecordScan("cluster-b", "worker-0", false)
 
 	// Check worker-0 success count
-	count := testutil.ToFloat64(FirmwareScanTotal.WithLabelValues("worker-0", "success"))
+	count := testutil.ToFloat64(FirmwareScanTotal.WithLabelValues("cluster-a", "worker-0", "success"))
 	if count != 2 {
 		t.Errorf("expected 2, got %f", count)
 	}
 
 	// Check worker-1 error count
-	count = testutil.ToFloat64(FirmwareScanTotal.WithLabelValues("worker-1", "error"))
+	count = testutil.ToFloat64(FirmwareScanTotal.WithLabelValues("cluster-a", "worker-1", "error"))
 	if count != 1 {
 		t.Errorf("expected 1, got %f", count)
 	}
+
+	// Same-named hosts in other namespaces are counted separately
+	count = testutil.ToFloat64(FirmwareScanTotal.WithLabelValues("cluster-b", "worker-0", "error"))
+	if count != 1 {
+		t.Errorf("expected 1, got %f", count)
+	}
+}
+
+func TestDeleteNode(t *testing.T) {
+	FirmwareScanTotal.Reset()
+
+	RecordScan("cluster-a", "worker-0", true)
+	RecordScan("cluster-b", "worker-0", true)
+	DeleteNode("cluster-a", "worker-0")
+
+	if n := testutil.CollectAndCount(FirmwareScanTotal); n != 1 {
+		t.Errorf("expected 1 series left, got %d", n)
+	}
 }
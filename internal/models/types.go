@@ -1,6 +1,14 @@
 package models
 
-import "time"
+import (
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/version"
+)
 
 // NodeStatus represents the firmware status of a node
 type NodeStatus string
@@ -30,6 +38,26 @@ const (
 	SeverityOptional    Severity = "Optional"
 )
 
+// VersionStatus describes how a component's installed firmware compares to the catalog
+type VersionStatus string
+
+const (
+	VersionUpToDate        VersionStatus = "up-to-date"
+	VersionUpdateAvailable VersionStatus = "update-available"
+	VersionNewer           VersionStatus = "newer"
+	VersionUnknown         VersionStatus = "unknown"
+)
+
+// ComplianceStatus describes how installed firmware compares to a baseline's target versions
+type ComplianceStatus string
+
+const (
+	ComplianceCompliant ComplianceStatus = "compliant"
+	ComplianceBehind    ComplianceStatus = "behind"
+	ComplianceAhead     ComplianceStatus = "ahead"
+	ComplianceUnknown   ComplianceStatus = "unknown"
+)
+
 // Node represents a discovered bare metal server
 type Node struct {
 	Name             string              `json:"name"`
@@ -38,35 +66,434 @@ type Node struct {
 	Model            string              `json:"model"`
 	Manufacturer     string              `json:"manufacturer"`
 	ServiceTag       string              `json:"serviceTag"`
+	SystemID         string              `json:"systemId,omitempty"`
 	PowerState       PowerState          `json:"powerState"`
 	LastScanned      time.Time           `json:"lastScanned"`
 	Status           NodeStatus          `json:"status"`
 	FirmwareCount    int                 `json:"firmwareCount"`
 	UpdatesAvailable int                 `json:"updatesAvailable"`
 	Firmware         []FirmwareComponent `json:"firmware,omitempty"`
+	Baseline         string              `json:"baseline,omitempty"`   // assigned firmware baseline, if any
+	Compliance       ComplianceStatus    `json:"compliance,omitempty"` // rollup against Baseline
 	// Health fields
-	Health           HealthStatus        `json:"health,omitempty"`
-	HealthRollup     *HealthRollup       `json:"healthRollup,omitempty"`
-	ThermalSummary   *ThermalSummary     `json:"thermalSummary,omitempty"`
-	PowerSummary     *PowerSummary       `json:"powerSummary,omitempty"`
-	NetworkAdapters  []NetworkAdapter    `json:"networkAdapters,omitempty"`
-	Storage          *StorageDetail      `json:"storage,omitempty"`
+	Health          HealthStatus     `json:"health,omitempty"`
+	HealthRollup    *HealthRollup    `json:"healthRollup,omitempty"`
+	ThermalSummary  *ThermalSummary  `json:"thermalSummary,omitempty"`
+	PowerSummary    *PowerSummary    `json:"powerSummary,omitempty"`
+	NetworkAdapters []NetworkAdapter `json:"networkAdapters,omitempty"`
+	Storage         *StorageDetail   `json:"storage,omitempty"`
+	// BareMetalHost status fields, as recorded by Metal3
+	ProvisioningState string          `json:"provisioningState,omitempty"`
+	OperationalStatus string          `json:"operationalStatus,omitempty"`
+	HostError         string          `json:"hostError,omitempty"` // status.errorMessage
+	Hardware          *HostHardware   `json:"hardware,omitempty"`
+	InventorySource   InventorySource `json:"inventorySource,omitempty"`
+	KubeNode          *KubeNode       `json:"kubeNode,omitempty"` // OpenShift node running on the host
+	// BMCCertificate is nil for BMCs reached over plain HTTP or not reached at all
+	BMCCertificate *BMCCertificate `json:"bmcCertificate,omitempty"`
+	PollError      *PollError      `json:"pollError,omitempty"` // why the last poll failed, if it did
+	PollSummary    *PollSummary    `json:"pollSummary,omitempty"`
+	// Stale lists the subsystems whose data was kept from an earlier poll
+	// because the last poll of them failed
+	Stale      map[string]StaleData `json:"stale,omitempty"`
+	PollReport *PollReport          `json:"-"` // served at /nodes/{name}/pollstatus
+}
+
+// ErrorKind classifies why a node could not be polled
+type ErrorKind string
+
+const (
+	ErrorAuth        ErrorKind = "auth"        // BMC rejected the credentials
+	ErrorUnreachable ErrorKind = "unreachable" // BMC address did not resolve or refused the connection
+	ErrorTLS         ErrorKind = "tls"         // TLS handshake or certificate verification failed
+	ErrorTimeout     ErrorKind = "timeout"
+	ErrorProtocol    ErrorKind = "protocol"  // BMC answered with a Redfish error or an unexpected response
+	ErrorDiscovery   ErrorKind = "discovery" // BareMetalHost has no usable BMC address or credentials
+)
+
+// PollError records a failure to poll a node. FirstSeen is kept while the same
+// kind of error repeats, so it tells how long the node has been failing.
+type PollError struct {
+	Kind      ErrorKind `json:"kind"`
+	Message   string    `json:"message"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// SubsystemStatus is the outcome of polling one area of a node's Redfish data
+type SubsystemStatus struct {
+	OK                  bool       `json:"ok"`
+	Error               string     `json:"error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastSuccess         *time.Time `json:"lastSuccess,omitempty"` // nil if never polled successfully
+}
+
+// PollReport describes the last poll of a node, subsystem by subsystem. Failure
+// counts and last success times carry over from one poll's report to the next.
+type PollReport struct {
+	StartedAt           time.Time                  `json:"startedAt"`
+	FinishedAt          time.Time                  `json:"finishedAt"`
+	DurationSeconds     float64                    `json:"durationSeconds"`
+	OK                  bool                       `json:"ok"` // every subsystem succeeded
+	ConsecutiveFailures int                        `json:"consecutiveFailures"`
+	LastSuccess         *time.Time                 `json:"lastSuccess,omitempty"`
+	Subsystems          map[string]SubsystemStatus `json:"subsystems"`
+
+	previous *PollReport
+}
+
+// StaleData tells when kept data was collected. Only CollectedAt is stored;
+// AgeSeconds is filled in when the node is served, see SetStaleAges.
+type StaleData struct {
+	CollectedAt time.Time `json:"collectedAt"`
+	AgeSeconds  float64   `json:"ageSeconds"`
+}
+
+// SetStaleAges fills in the age of the node's stale data as of now. The Stale
+// map is replaced rather than modified, as it is shared with the stored node.
+func (n *Node) SetStaleAges(now time.Time) {
+	if len(n.Stale) == 0 {
+		return
+	}
+	stale := make(map[string]StaleData, len(n.Stale))
+	for subsystem, data := range n.Stale {
+		data.AgeSeconds = now.Sub(data.CollectedAt).Seconds()
+		stale[subsystem] = data
+	}
+	n.Stale = stale
+}
+
+// PollSummary is the part of a PollReport listed with every node
+type PollSummary struct {
+	LastPoll            time.Time  `json:"lastPoll"`
+	OK                  bool       `json:"ok"`
+	FailedSubsystems    []string   `json:"failedSubsystems,omitempty"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastSuccess         *time.Time `json:"lastSuccess,omitempty"`
+}
+
+// NewPollReport starts the report for a poll following previous, which may be nil
+func NewPollReport(previous *PollReport, started time.Time) *PollReport {
+	return &PollReport{
+		StartedAt:  started,
+		Subsystems: make(map[string]SubsystemStatus),
+		previous:   previous,
+	}
+}
+
+// Record sets the outcome of polling one subsystem; err is nil on success
+func (r *PollReport) Record(subsystem string, err error) {
+	var status SubsystemStatus
+	if r.previous != nil {
+		status = r.previous.Subsystems[subsystem]
+	}
+	if err != nil {
+		status.OK = false
+		status.Error = err.Error()
+		status.ConsecutiveFailures++
+	} else {
+		started := r.StartedAt
+		status = SubsystemStatus{OK: true, LastSuccess: &started}
+	}
+	r.Subsystems[subsystem] = status
+}
+
+// Finish completes the report once every polled subsystem has been recorded
+func (r *PollReport) Finish(finished time.Time) {
+	r.FinishedAt = finished
+	r.DurationSeconds = finished.Sub(r.StartedAt).Seconds()
+
+	// Subsystems left out of this poll keep their last outcome
+	if r.previous != nil {
+		for name, status := range r.previous.Subsystems {
+			if _, ok := r.Subsystems[name]; !ok {
+				r.Subsystems[name] = status
+			}
+		}
+	}
+
+	r.OK = len(r.Subsystems) > 0
+	for _, status := range r.Subsystems {
+		if !status.OK {
+			r.OK = false
+		}
+	}
+	if r.previous != nil {
+		r.ConsecutiveFailures = r.previous.ConsecutiveFailures
+		r.LastSuccess = r.previous.LastSuccess
+	}
+	if r.OK {
+		started := r.StartedAt
+		r.ConsecutiveFailures = 0
+		r.LastSuccess = &started
+	} else {
+		r.ConsecutiveFailures++
+	}
+	r.previous = nil
+}
+
+// Summary returns the summary listed with the node
+func (r *PollReport) Summary() *PollSummary {
+	summary := &PollSummary{
+		LastPoll:            r.StartedAt,
+		OK:                  r.OK,
+		ConsecutiveFailures: r.ConsecutiveFailures,
+		LastSuccess:         r.LastSuccess,
+	}
+	for name, status := range r.Subsystems {
+		if !status.OK {
+			summary.FailedSubsystems = append(summary.FailedSubsystems, name)
+		}
+	}
+	sort.Strings(summary.FailedSubsystems)
+	return summary
+}
+
+// RefreshState is the progress of an on-demand refresh, or of one node in it
+type RefreshState string
+
+const (
+	RefreshQueued  RefreshState = "queued"
+	RefreshRunning RefreshState = "running"
+	RefreshDone    RefreshState = "done"
+	RefreshFailed  RefreshState = "failed"  // the node was polled but a subsystem failed; of a refresh, no node was done
+	RefreshSkipped RefreshState = "skipped" // the node was refreshed too recently
+	RefreshPartial RefreshState = "partial" // of a refresh, some nodes were done and others failed or were skipped
+)
+
+// Refresh is an on-demand poll of one node or the whole fleet
+type Refresh struct {
+	ID          string        `json:"id"`
+	Subsystems  []string      `json:"subsystems,omitempty"` // empty when every subsystem is polled
+	State       RefreshState  `json:"state"`
+	RequestedAt time.Time     `json:"requestedAt"`
+	FinishedAt  *time.Time    `json:"finishedAt,omitempty"`
+	Nodes       []RefreshNode `json:"nodes"`
+}
+
+// RefreshNode is the progress of refreshing one node
+type RefreshNode struct {
+	Namespace string       `json:"namespace"`
+	Name      string       `json:"name"`
+	State     RefreshState `json:"state"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// NewPollError records err as a failure at now, carrying over when it was first
+// seen from the previous error if it was of the same kind
+func NewPollError(kind ErrorKind, err error, previous *PollError, now time.Time) *PollError {
+	e := &PollError{Kind: kind, Message: err.Error(), FirstSeen: now, LastSeen: now}
+	if previous != nil && previous.Kind == kind {
+		e.FirstSeen = previous.FirstSeen
+	}
+	return e
+}
+
+// KubeNode is the Kubernetes Node a BareMetalHost was provisioned as
+type KubeNode struct {
+	Name           string   `json:"name"`
+	Machine        string   `json:"machine,omitempty"` // "namespace/name" of the consuming Machine
+	Roles          []string `json:"roles"`
+	Ready          bool     `json:"ready"`
+	Unschedulable  bool     `json:"unschedulable"` // cordoned
+	KubeletVersion string   `json:"kubeletVersion,omitempty"`
+}
+
+// HasRole reports whether the node's Kubernetes Node has the given role, e.g.
+// "master" or "worker"
+func (n *Node) HasRole(role string) bool {
+	if n.KubeNode == nil {
+		return false
+	}
+	for _, r := range n.KubeNode.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// InventorySource identifies where a node's hardware details came from
+type InventorySource string
+
+const (
+	// InventoryBMC means the details were read from the BMC over Redfish
+	InventoryBMC InventorySource = "bmc"
+	// InventoryBareMetalHost means the BMC was unreachable and the details come
+	// from the BareMetalHost's last inspection
+	InventoryBareMetalHost InventorySource = "baremetalhost"
+)
+
+// HostHardware is the hardware inventory Metal3 records in a BareMetalHost's
+// status.hardware during inspection. Field names follow the Metal3 API.
+type HostHardware struct {
+	Hostname     string           `json:"hostname,omitempty"`
+	SystemVendor HostSystemVendor `json:"systemVendor,omitzero"`
+	Firmware     HostFirmware     `json:"firmware,omitzero"`
+	CPU          HostCPU          `json:"cpu,omitzero"`
+	RAMMebibytes int              `json:"ramMebibytes,omitempty"`
+	NICs         []HostNIC        `json:"nics,omitempty"`
+	Storage      []HostDisk       `json:"storage,omitempty"`
+}
+
+// HostSystemVendor identifies the server model
+type HostSystemVendor struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	ProductName  string `json:"productName,omitempty"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+}
+
+// Model returns the product name without the SKU suffix some BMCs append,
+// e.g. "PowerEdge R640 (SKU=0716;ModelName=PowerEdge R640)"
+func (v HostSystemVendor) Model() string {
+	if i := strings.Index(v.ProductName, " ("); i > 0 {
+		return v.ProductName[:i]
+	}
+	return v.ProductName
+}
+
+// HostFirmware holds the BIOS details found during inspection
+type HostFirmware struct {
+	BIOS struct {
+		Vendor  string `json:"vendor,omitempty"`
+		Version string `json:"version,omitempty"`
+		Date    string `json:"date,omitempty"`
+	} `json:"bios,omitzero"`
+}
+
+// HostCPU describes the host's processors
+type HostCPU struct {
+	Arch           string  `json:"arch,omitempty"`
+	Model          string  `json:"model,omitempty"`
+	ClockMegahertz float64 `json:"clockMegahertz,omitempty"`
+	Count          int     `json:"count,omitempty"`
+}
+
+// HostNIC is a network interface found during inspection
+type HostNIC struct {
+	Name      string `json:"name,omitempty"`
+	Model     string `json:"model,omitempty"`
+	MAC       string `json:"mac,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	SpeedGbps int    `json:"speedGbps,omitempty"`
+}
+
+// HostDisk is a storage device found during inspection
+type HostDisk struct {
+	Name         string `json:"name,omitempty"`
+	Type         string `json:"type,omitempty"` // HDD, SSD or NVME
+	Vendor       string `json:"vendor,omitempty"`
+	Model        string `json:"model,omitempty"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+	SizeBytes    int64  `json:"sizeBytes,omitempty"`
+	Rotational   bool   `json:"rotational,omitempty"`
 }
 
 // FirmwareComponent represents a single firmware component on a server
 type FirmwareComponent struct {
-	ID               string   `json:"id"`
-	Name             string   `json:"name"`
-	CurrentVersion   string   `json:"currentVersion"`
-	AvailableVersion string   `json:"availableVersion,omitempty"`
-	Updateable       bool     `json:"updateable"`
-	ComponentType    string   `json:"componentType"`
-	Severity         Severity `json:"severity,omitempty"`
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	CurrentVersion   string           `json:"currentVersion"`
+	AvailableVersion string           `json:"availableVersion,omitempty"`
+	Updateable       bool             `json:"updateable"`
+	ComponentType    string           `json:"componentType"`
+	Severity         Severity         `json:"severity,omitempty"`
+	VersionStatus    VersionStatus    `json:"versionStatus,omitempty"`
+	Identity         DeviceIdentity   `json:"identity,omitzero"`
+	TargetVersion    string           `json:"targetVersion,omitempty"` // version pinned by the node's baseline
+	Compliance       ComplianceStatus `json:"compliance,omitempty"`
+}
+
+// DeviceIdentity identifies the hardware a firmware component or catalog package
+// applies to, using the vendor component ID and/or PCI IDs
+type DeviceIdentity struct {
+	ComponentID string `json:"componentId,omitempty"`
+	VendorID    string `json:"vendorId,omitempty"`
+	DeviceID    string `json:"deviceId,omitempty"`
+	SubVendorID string `json:"subVendorId,omitempty"`
+	SubDeviceID string `json:"subDeviceId,omitempty"`
+}
+
+// HasPCI returns true if all four PCI IDs are known
+func (d DeviceIdentity) HasPCI() bool {
+	return d.VendorID != "" && d.DeviceID != "" && d.SubVendorID != "" && d.SubDeviceID != ""
+}
+
+// Matches reports whether two identities refer to the same device. When both sides
+// carry full PCI IDs those must match; otherwise the component IDs must.
+func (d DeviceIdentity) Matches(other DeviceIdentity) bool {
+	if d.HasPCI() && other.HasPCI() {
+		return strings.EqualFold(d.VendorID, other.VendorID) &&
+			strings.EqualFold(d.DeviceID, other.DeviceID) &&
+			strings.EqualFold(d.SubVendorID, other.SubVendorID) &&
+			strings.EqualFold(d.SubDeviceID, other.SubDeviceID)
+	}
+	return d.ComponentID != "" && d.ComponentID == other.ComponentID
 }
 
-// NeedsUpdate returns true if an update is available
+// NeedsUpdate returns true if the available version is strictly newer than the installed one
 func (f *FirmwareComponent) NeedsUpdate() bool {
-	return f.AvailableVersion != "" && f.AvailableVersion != f.CurrentVersion
+	return f.AvailableVersion != "" && version.Newer(f.AvailableVersion, f.CurrentVersion)
+}
+
+// CompareVersions classifies the installed version against the available one.
+// It returns "" when there is no available version to compare against.
+func (f *FirmwareComponent) CompareVersions() VersionStatus {
+	if f.AvailableVersion == "" {
+		return ""
+	}
+	switch version.Compare(f.AvailableVersion, f.CurrentVersion) {
+	case version.Greater:
+		return VersionUpdateAvailable
+	case version.Equal:
+		return VersionUpToDate
+	case version.Less:
+		return VersionNewer
+	default:
+		return VersionUnknown
+	}
+}
+
+// NodeKey identifies a node by namespace and name. BareMetalHost names are only
+// unique within a namespace, e.g. master-0 exists in every cluster on a hub.
+func NodeKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// Key returns the node's namespace-qualified identity, see NodeKey
+func (n *Node) Key() string {
+	return NodeKey(n.Namespace, n.Name)
+}
+
+// NeedsUpdate returns true if fw should be updated. Nodes with a baseline are
+// judged against its target versions only; others against the catalog.
+func (n *Node) NeedsUpdate(fw *FirmwareComponent) bool {
+	if n.Baseline != "" {
+		return fw.Compliance == ComplianceBehind
+	}
+	return fw.NeedsUpdate()
+}
+
+// RefreshFirmwareStatus recomputes the node's firmware counts and status
+func (n *Node) RefreshFirmwareStatus() {
+	n.FirmwareCount = len(n.Firmware)
+
+	updatesNeeded := 0
+	for i := range n.Firmware {
+		if n.NeedsUpdate(&n.Firmware[i]) {
+			updatesNeeded++
+		}
+	}
+	n.UpdatesAvailable = updatesNeeded
+
+	if updatesNeeded > 0 {
+		n.Status = StatusNeedsUpdate
+	} else {
+		n.Status = StatusUpToDate
+	}
 }
 
 // BMCCredentials holds credentials for accessing a BMC
@@ -75,19 +502,81 @@ type BMCCredentials struct {
 	Password string
 }
 
+// CertificateStatus is the outcome of validating a BMC's TLS certificate
+type CertificateStatus string
+
+const (
+	// CertificateVerified chains to a trusted CA and matches the BMC address
+	CertificateVerified CertificateStatus = "verified"
+	// CertificateUntrusted does not validate, but was accepted because the
+	// BareMetalHost disables certificate verification
+	CertificateUntrusted CertificateStatus = "untrusted"
+	// CertificateRejected does not validate, so the BMC was not polled
+	CertificateRejected CertificateStatus = "rejected"
+)
+
+// BMCCertificate describes the certificate a BMC presented and how it validated
+type BMCCertificate struct {
+	Status               CertificateStatus `json:"status"`
+	VerificationDisabled bool              `json:"verificationDisabled"`
+	SelfSigned           bool              `json:"selfSigned"`
+	Subject              string            `json:"subject"`
+	Issuer               string            `json:"issuer"`
+	NotAfter             time.Time         `json:"notAfter"`
+	Error                string            `json:"error,omitempty"` // why validation failed
+}
+
+// BMCEndpoint is where a host's Redfish service lives, parsed from the
+// BareMetalHost spec.bmc.address
+type BMCEndpoint struct {
+	Driver   string `json:"driver,omitempty"` // Metal3 driver, e.g. idrac-virtualmedia; empty for a bare address
+	Scheme   string `json:"scheme"`           // http or https
+	Host     string `json:"host"`
+	Port     string `json:"port,omitempty"`
+	SystemID string `json:"systemId,omitempty"` // ComputerSystem Id; empty means the first system
+	// DisableCertificateVerification mirrors spec.bmc.disableCertificateVerification
+	DisableCertificateVerification bool   `json:"disableCertificateVerification,omitempty"`
+	CABundle                       string `json:"-"` // PEM CAs trusted in addition to the system roots
+}
+
+// Address returns the host, with the port if one was given
+func (e BMCEndpoint) Address() string {
+	if e.Port == "" {
+		if strings.Contains(e.Host, ":") {
+			return "[" + e.Host + "]" // IPv6
+		}
+		return e.Host
+	}
+	return net.JoinHostPort(e.Host, e.Port)
+}
+
+// BaseURL returns the URL of the Redfish service, e.g. https://10.0.0.1:8443
+func (e BMCEndpoint) BaseURL() string {
+	scheme := e.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return scheme + "://" + e.Address()
+}
+
 // CatalogEntry represents a firmware update available in Dell's catalog
 type CatalogEntry struct {
-	ComponentID   string `json:"componentId"`
-	ComponentType string `json:"componentType"`
-	SystemModelID string `json:"systemModelId"`
-	Version       string `json:"version"`
-	ReleaseDate   string `json:"releaseDate"`
-	Criticality   string `json:"criticality"` // "Critical", "Recommended", "Optional"
-	DownloadURL   string `json:"downloadUrl"`
-	FileName      string `json:"fileName"`
-	SizeMB        int    `json:"sizeMb"`
+	ComponentID   string           `json:"componentId"`
+	ComponentType string           `json:"componentType"`
+	SystemModelID string           `json:"systemModelId"`
+	SystemID      string           `json:"systemId,omitempty"` // Dell BIOS system ID, 4-digit hex
+	Version       string           `json:"version"`
+	ReleaseDate   string           `json:"releaseDate"`
+	Criticality   string           `json:"criticality"` // "Critical", "Recommended", "Optional"
+	DownloadURL   string           `json:"downloadUrl"`
+	FileName      string           `json:"fileName"`
+	SizeMB        int              `json:"sizeMb"`
+	Devices       []DeviceIdentity `json:"devices,omitempty"` // devices the package applies to
 }
 
+// CatalogProviderDell identifies Dell's firmware catalog
+const CatalogProviderDell = "dell"
+
 // CatalogKey creates a lookup key for catalog entries
 func CatalogKey(systemModel, componentID string) string {
 	return systemModel + "|" + componentID
@@ -213,11 +702,76 @@ type StorageDetail struct {
 
 // HealthEvent represents a system event log entry
 type HealthEvent struct {
+	ID         string       `json:"id"`
+	Timestamp  time.Time    `json:"timestamp"`
+	Severity   HealthStatus `json:"severity"`
+	Message    string       `json:"message"`
+	MessageID  string       `json:"messageId,omitempty"`  // message registry ID, e.g. SUP0516
+	Category   string       `json:"category,omitempty"`   // vendor category, e.g. Updates in the Dell Lifecycle log
+	Resolution string       `json:"resolution,omitempty"` // recommended action from the message registry
+	Component  string       `json:"component,omitempty"`  // affected part, e.g. PSU.Slot.1
+	NodeName   string       `json:"nodeName,omitempty"`
+	Namespace  string       `json:"namespace,omitempty"`
+	BMC        string       `json:"bmc,omitempty"`        // identity of the BMC that logged the event
+	LogService string       `json:"logService,omitempty"` // BMC log the entry was read from
+	Created    string       `json:"-"`                    // entry creation time as the BMC reported it
+}
+
+// Key identifies the log entry an event was read from, so the same entry read
+// by several polls is stored once
+func (e HealthEvent) Key() string {
+	return strings.Join([]string{e.Namespace, e.NodeName, e.BMC, e.LogService, e.ID, e.Created}, "|")
+}
+
+// EventMark is the high-water mark of one BMC event log: the creation time of
+// the newest entry read so far and the IDs of the entries created at that time
+type EventMark struct {
+	Created time.Time `json:"created"`
+	IDs     []string  `json:"ids"`
+}
+
+// Covers reports whether an entry was already read
+func (m EventMark) Covers(created time.Time, id string) bool {
+	return created.Before(m.Created) || created.Equal(m.Created) && slices.Contains(m.IDs, id)
+}
+
+// Advance returns the mark moved forward to include an entry
+func (m EventMark) Advance(created time.Time, id string) EventMark {
+	switch {
+	case created.After(m.Created):
+		return EventMark{Created: created, IDs: []string{id}}
+	case created.Equal(m.Created) && !slices.Contains(m.IDs, id):
+		return EventMark{Created: m.Created, IDs: append(slices.Clone(m.IDs), id)}
+	}
+	return m
+}
+
+// ChangeType identifies what changed on a node between two polls
+type ChangeType string
+
+const (
+	ChangeFirmwareVersion   ChangeType = "firmware-changed"
+	ChangeHealth            ChangeType = "health-changed"
+	ChangePowerState        ChangeType = "power-state-changed"
+	ChangePSURedundancyLost ChangeType = "psu-redundancy-lost"
+	ChangeFanFailed         ChangeType = "fan-failed"
+	ChangeDiskOffline       ChangeType = "disk-offline"
+	ChangeNICLinkDown       ChangeType = "nic-link-down"
+	ChangeUpdateAvailable   ChangeType = "update-available"
+)
+
+// ChangeEvent records a change detected between two polls of a node
+type ChangeEvent struct {
 	ID        string       `json:"id"`
 	Timestamp time.Time    `json:"timestamp"`
+	Type      ChangeType   `json:"type"`
 	Severity  HealthStatus `json:"severity"`
+	Component string       `json:"component,omitempty"` // firmware component, disk or NIC
+	Previous  string       `json:"previous,omitempty"`
+	Current   string       `json:"current,omitempty"`
 	Message   string       `json:"message"`
-	NodeName  string       `json:"nodeName,omitempty"`
+	NodeName  string       `json:"nodeName"`
+	Namespace string       `json:"namespace"`
 }
 
 // TaskState represents the state of a Redfish task
@@ -235,10 +789,12 @@ type Task struct {
 	Node            string    `json:"node"`
 	Namespace       string    `json:"namespace"`
 	TaskID          string    `json:"taskId"`
+	BMCTaskID       string    `json:"bmcTaskId,omitempty"` // the BMC's task or job ID, once submitted
 	TaskType        string    `json:"taskType"`
 	TaskState       TaskState `json:"taskState"`
 	PercentComplete int       `json:"percentComplete"`
 	StartTime       time.Time `json:"startTime"`
+	EndTime         time.Time `json:"endTime,omitzero"`
 	Message         string    `json:"message"`
 }
 
@@ -274,15 +830,44 @@ type JobsSummary struct {
 	Pending    int `json:"pending"`
 	InProgress int `json:"inProgress"`
 	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+}
+
+// ComplianceSummary counts nodes by compliance with their assigned baseline
+type ComplianceSummary struct {
+	Compliant  int `json:"compliant"`
+	Behind     int `json:"behind"`
+	Ahead      int `json:"ahead"`
+	Unknown    int `json:"unknown"`
+	NoBaseline int `json:"noBaseline"`
+}
+
+// Add counts a node in the summary
+func (c *ComplianceSummary) Add(node *Node) {
+	if node.Baseline == "" {
+		c.NoBaseline++
+		return
+	}
+	switch node.Compliance {
+	case ComplianceCompliant:
+		c.Compliant++
+	case ComplianceBehind:
+		c.Behind++
+	case ComplianceAhead:
+		c.Ahead++
+	default:
+		c.Unknown++
+	}
 }
 
 // DashboardStats aggregates all dashboard statistics
 type DashboardStats struct {
-	TotalNodes     int               `json:"totalNodes"`
-	HealthSummary  HealthSummary     `json:"healthSummary"`
-	PowerSummary   PowerStateSummary `json:"powerSummary"`
-	UpdatesSummary UpdatesSummary    `json:"updatesSummary"`
-	JobsSummary    JobsSummary       `json:"jobsSummary"`
-	LastRefresh    time.Time         `json:"lastRefresh"`
-	NextRefresh    time.Time         `json:"nextRefresh"`
+	TotalNodes        int               `json:"totalNodes"`
+	HealthSummary     HealthSummary     `json:"healthSummary"`
+	PowerSummary      PowerStateSummary `json:"powerSummary"`
+	UpdatesSummary    UpdatesSummary    `json:"updatesSummary"`
+	ComplianceSummary ComplianceSummary `json:"complianceSummary"`
+	JobsSummary       JobsSummary       `json:"jobsSummary"`
+	LastRefresh       time.Time         `json:"lastRefresh"`
+	NextRefresh       time.Time         `json:"nextRefresh"`
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -40,6 +41,32 @@ func TestFirmwareComponent(t *testing.T) {
 	if fw.NeedsUpdate() {
 		t.Error("expected firmware to not need update when versions match")
 	}
+
+	fw.AvailableVersion = "2.9.0"
+	if fw.NeedsUpdate() {
+		t.Error("expected firmware newer than catalog to not need update")
+	}
+}
+
+func TestFirmwareComponent_CompareVersions(t *testing.T) {
+	tests := []struct {
+		current   string
+		available string
+		want      VersionStatus
+	}{
+		{"2.18.1", "2.19.1", VersionUpdateAvailable},
+		{"2.19.1", "2.19.1", VersionUpToDate},
+		{"9.10.0", "9.2.0", VersionNewer},
+		{"A05", "2.19.1", VersionUnknown},
+		{"2.19.1", "", ""},
+	}
+
+	for _, tt := range tests {
+		fw := FirmwareComponent{CurrentVersion: tt.current, AvailableVersion: tt.available}
+		if got := fw.CompareVersions(); got != tt.want {
+			t.Errorf("CompareVersions(%q -> %q) = %q, want %q", tt.current, tt.available, got, tt.want)
+		}
+	}
 }
 
 func TestCatalogEntry(t *testing.T) {
@@ -162,3 +189,156 @@ func TestFirmwareComponent_Severity(t *testing.T) {
 		t.Errorf("Severity = %v, want Critical", fw.Severity)
 	}
 }
+
+func TestNode_RefreshFirmwareStatus(t *testing.T) {
+	node := Node{
+		Firmware: []FirmwareComponent{
+			{Name: "BIOS", CurrentVersion: "2.18.1", AvailableVersion: "2.19.1"},
+			{Name: "iDRAC", CurrentVersion: "7.0", AvailableVersion: "7.10", TargetVersion: "7.0", Compliance: ComplianceCompliant},
+		},
+	}
+
+	node.RefreshFirmwareStatus()
+	if node.FirmwareCount != 2 || node.UpdatesAvailable != 2 || node.Status != StatusNeedsUpdate {
+		t.Errorf("without baseline: got %d/%d %s", node.FirmwareCount, node.UpdatesAvailable, node.Status)
+	}
+
+	// With a baseline only components behind their target count
+	node.Baseline = "ocp-4.16"
+	node.RefreshFirmwareStatus()
+	if node.UpdatesAvailable != 0 || node.Status != StatusUpToDate {
+		t.Errorf("with baseline: got %d %s", node.UpdatesAvailable, node.Status)
+	}
+}
+
+func TestBMCEndpoint_BaseURL(t *testing.T) {
+	tests := []struct {
+		endpoint BMCEndpoint
+		address  string
+		baseURL  string
+	}{
+		{BMCEndpoint{Scheme: "https", Host: "10.0.0.1"}, "10.0.0.1", "https://10.0.0.1"},
+		{BMCEndpoint{Scheme: "http", Host: "10.0.0.1", Port: "8000"}, "10.0.0.1:8000", "http://10.0.0.1:8000"},
+		{BMCEndpoint{Scheme: "https", Host: "fd00::5", Port: "8443"}, "[fd00::5]:8443", "https://[fd00::5]:8443"},
+		{BMCEndpoint{Scheme: "https", Host: "fd00::5"}, "[fd00::5]", "https://[fd00::5]"},
+		{BMCEndpoint{Host: "bmc.example.com"}, "bmc.example.com", "https://bmc.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			if got := tt.endpoint.Address(); got != tt.address {
+				t.Errorf("Address() = %q, want %q", got, tt.address)
+			}
+			if got := tt.endpoint.BaseURL(); got != tt.baseURL {
+				t.Errorf("BaseURL() = %q, want %q", got, tt.baseURL)
+			}
+		})
+	}
+}
+
+func TestNewPollError(t *testing.T) {
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := first.Add(time.Hour)
+
+	e := NewPollError(ErrorAuth, errors.New("401"), nil, first)
+	if e.FirstSeen != first || e.LastSeen != first || e.Message != "401" {
+		t.Errorf("unexpected first error: %+v", e)
+	}
+
+	// The same kind of failure keeps its first occurrence
+	repeated := NewPollError(ErrorAuth, errors.New("401 again"), e, later)
+	if repeated.FirstSeen != first || repeated.LastSeen != later || repeated.Message != "401 again" {
+		t.Errorf("unexpected repeated error: %+v", repeated)
+	}
+
+	// A different failure starts over
+	changed := NewPollError(ErrorTimeout, errors.New("timeout"), e, later)
+	if changed.FirstSeen != later {
+		t.Errorf("expected a new first occurrence, got %+v", changed)
+	}
+}
+
+func TestPollReport(t *testing.T) {
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := NewPollReport(nil, first)
+	report.Record("health", nil)
+	report.Record("storage", errors.New("no systems found"))
+	report.Finish(first.Add(time.Second))
+
+	if report.OK || report.ConsecutiveFailures != 1 || report.LastSuccess != nil {
+		t.Errorf("unexpected first report: %+v", report)
+	}
+	if health := report.Subsystems["health"]; !health.OK || health.LastSuccess == nil || !health.LastSuccess.Equal(first) {
+		t.Errorf("unexpected health status: %+v", health)
+	}
+
+	// Failures accumulate per subsystem; last successes carry over
+	second := first.Add(time.Hour)
+	next := NewPollReport(report, second)
+	next.Record("health", errors.New("timeout"))
+	next.Record("storage", errors.New("no systems found"))
+	next.Finish(second.Add(time.Second))
+
+	health := next.Subsystems["health"]
+	if health.OK || health.ConsecutiveFailures != 1 || health.LastSuccess == nil || !health.LastSuccess.Equal(first) {
+		t.Errorf("unexpected health status: %+v", health)
+	}
+	if storage := next.Subsystems["storage"]; storage.ConsecutiveFailures != 2 {
+		t.Errorf("expected 2 storage failures, got %+v", storage)
+	}
+	if next.ConsecutiveFailures != 2 {
+		t.Errorf("expected 2 failed polls, got %d", next.ConsecutiveFailures)
+	}
+
+	// A fully successful poll resets the counts
+	third := second.Add(time.Hour)
+	ok := NewPollReport(next, third)
+	ok.Record("health", nil)
+	ok.Record("storage", nil)
+	ok.Finish(third)
+	if !ok.OK || ok.ConsecutiveFailures != 0 || ok.LastSuccess == nil || !ok.LastSuccess.Equal(third) {
+		t.Errorf("unexpected successful report: %+v", ok)
+	}
+	if summary := ok.Summary(); !summary.OK || len(summary.FailedSubsystems) != 0 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	// Subsystems left out of a poll keep their last outcome
+	fourth := third.Add(time.Hour)
+	partial := NewPollReport(ok, fourth)
+	partial.Record("health", nil)
+	partial.Finish(fourth)
+	if storage := partial.Subsystems["storage"]; !storage.OK || !storage.LastSuccess.Equal(third) {
+		t.Errorf("expected storage to carry over, got %+v", storage)
+	}
+}
+
+func TestEventMark(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mark := EventMark{}.Advance(t0, "1").Advance(t0, "2").Advance(t0.Add(-time.Hour), "0")
+
+	tests := []struct {
+		name    string
+		created time.Time
+		id      string
+		covered bool
+	}{
+		{"older entry", t0.Add(-time.Minute), "9", true},
+		{"entry at the mark", t0, "2", true},
+		{"other entry in the same second", t0, "3", false},
+		{"newer entry", t0.Add(time.Second), "1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mark.Covers(tt.created, tt.id); got != tt.covered {
+				t.Errorf("Covers(%v, %s) = %v, want %v", tt.created, tt.id, got, tt.covered)
+			}
+		})
+	}
+
+	if next := mark.Advance(t0.Add(time.Second), "4"); !next.Created.Equal(t0.Add(time.Second)) || len(next.IDs) != 1 {
+		t.Errorf("expected mark to move to the newer entry, got %+v", next)
+	}
+	if len(mark.IDs) != 2 {
+		t.Errorf("expected advancing to leave the original mark alone, got %+v", mark)
+	}
+}
@@ -2,11 +2,15 @@ package poller
 
 import (
 	"context"
+	"errors"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/cragr/openshift-baremetal-insights/internal/baseline"
 	"github.com/cragr/openshift-baremetal-insights/internal/catalog"
+	"github.com/cragr/openshift-baremetal-insights/internal/changes"
 	"github.com/cragr/openshift-baremetal-insights/internal/discovery"
 	"github.com/cragr/openshift-baremetal-insights/internal/metrics"
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
@@ -14,15 +18,32 @@ import (
 	"github.com/cragr/openshift-baremetal-insights/internal/store"
 )
 
-// Poller periodically polls iDRACs for firmware inventory
+// Poller periodically polls BMCs for firmware inventory
 type Poller struct {
 	discoverer *discovery.Discoverer
 	redfish    *redfish.Client
 	store      *store.Store
 	eventStore *store.EventStore
 	catalog    *catalog.Service
+	baselines  *baseline.Manager
+	changes    *store.ChangeStore
 	interval   time.Duration
 
+	hostChanges chan hostRef
+	getHost     hostLookup
+	changedHost changedHostLookup
+
+	refreshMu        sync.Mutex
+	refreshInterval  time.Duration
+	refreshQueue     chan *nodeRefresh
+	refreshes        map[string]*models.Refresh // by ID
+	pendingRefreshes map[string]*nodeRefresh    // by node key
+	lastRefresh      map[string]time.Time       // by node key
+
+	pollsMu      sync.Mutex
+	polls        map[string]*nodePoll // polls in progress, by node key
+	changedHosts map[string]bool      // change polls waiting, by node key
+
 	mu      sync.Mutex
 	running bool
 	stopCh  chan struct{}
@@ -37,9 +58,85 @@ func New(discoverer *discovery.Discoverer, redfishClient *redfish.Client, store
 		eventStore: eventStore,
 		catalog:    catalogSvc,
 		interval:   interval,
+		getHost:    discoverer.GetHost,
+
+		hostChanges: make(chan hostRef, 64),
+		changedHost: discoverer.Host,
+
+		refreshInterval:  DefaultRefreshInterval,
+		refreshQueue:     make(chan *nodeRefresh, refreshQueueSize),
+		refreshes:        make(map[string]*models.Refresh),
+		pendingRefreshes: make(map[string]*nodeRefresh),
+		lastRefresh:      make(map[string]time.Time),
+
+		polls:        make(map[string]*nodePoll),
+		changedHosts: make(map[string]bool),
+	}
+}
+
+// hostRef names a BareMetalHost
+type hostRef struct {
+	namespace string
+	name      string
+}
+
+// changedHostLookup resolves a changed host, see discovery.Discoverer.Host
+type changedHostLookup func(ctx context.Context, namespace, name string) (discovery.DiscoveredHost, bool)
+
+// nodePoll is a poll of one node in progress. Only one poll of a node runs at
+// a time, so two BMC sessions are never opened to it at once and results are
+// stored in the order they were collected.
+type nodePoll struct {
+	done chan struct{} // closed when the poll finishes
+	full bool          // every subsystem is polled
+}
+
+// lockNode waits until no other poll of a node is running and records this one,
+// which polls every subsystem if full. With skipFull, it instead returns false
+// at once if the running poll polls every subsystem, as its result will be as
+// fresh. It also returns false if ctx ends first.
+func (p *Poller) lockNode(ctx context.Context, key string, full, skipFull bool) bool {
+	for {
+		p.pollsMu.Lock()
+		running, ok := p.polls[key]
+		if !ok {
+			p.polls[key] = &nodePoll{done: make(chan struct{}), full: full}
+			p.pollsMu.Unlock()
+			return true
+		}
+		p.pollsMu.Unlock()
+
+		if skipFull && running.full {
+			return false
+		}
+		select {
+		case <-running.done:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// unlockNode ends a poll recorded by lockNode
+func (p *Poller) unlockNode(key string) {
+	p.pollsMu.Lock()
+	defer p.pollsMu.Unlock()
+	if running, ok := p.polls[key]; ok {
+		close(running.done)
+		delete(p.polls, key)
 	}
 }
 
+// SetBaselines enables evaluating nodes against firmware baselines
+func (p *Poller) SetBaselines(m *baseline.Manager) {
+	p.baselines = m
+}
+
+// SetChangeStore enables recording what changed on each node between polls
+func (p *Poller) SetChangeStore(cs *store.ChangeStore) {
+	p.changes = cs
+}
+
 // Start begins the polling loop
 func (p *Poller) Start(ctx context.Context) {
 	p.mu.Lock()
@@ -49,8 +146,14 @@ func (p *Poller) Start(ctx context.Context) {
 	}
 	p.running = true
 	p.stopCh = make(chan struct{})
+	stopCh := p.stopCh
 	p.mu.Unlock()
 
+	go p.pollChanges(ctx, stopCh)
+	for range refreshWorkers {
+		go p.runRefreshes(ctx, stopCh)
+	}
+
 	// Run immediately on start
 	p.poll(ctx)
 
@@ -80,15 +183,73 @@ func (p *Poller) Stop() {
 	}
 }
 
-func (p *Poller) poll(ctx context.Context) {
-	log.Println("Starting firmware poll...")
+// HostChanged queues an immediate poll of a new or changed host, see discovery.HostHandler
+func (p *Poller) HostChanged(namespace, name string) {
+	select {
+	case p.hostChanges <- hostRef{namespace: namespace, name: name}:
+	default:
+		log.Printf("Poll queue full, %s/%s will be picked up by the next poll", namespace, name)
+	}
+}
 
-	// Sync catalog if needed
-	if p.catalog != nil && p.catalog.NeedsSync() {
-		if err := p.catalog.Sync(ctx); err != nil {
-			log.Printf("Catalog sync error: %v", err)
+// HostDeleted forgets a host that no longer exists, see discovery.HostHandler
+func (p *Poller) HostDeleted(namespace, name string) {
+	p.store.DeleteNode(namespace, name)
+	if p.eventStore != nil {
+		p.eventStore.DeleteNode(namespace, name)
+	}
+	if p.changes != nil {
+		p.changes.DeleteNode(namespace, name)
+	}
+	metrics.DeleteNode(namespace, name)
+	log.Printf("Removed %s/%s", namespace, name)
+}
+
+// pollChanges polls hosts queued by HostChanged until stopped
+func (p *Poller) pollChanges(ctx context.Context, stopCh chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopCh:
+			return
+		case host := <-p.hostChanges:
+			go p.pollChangedHost(ctx, host.namespace, host.name)
 		}
 	}
+}
+
+// pollChangedHost polls a host queued by HostChanged once no other poll of it
+// is running. Changes to a host that is already waiting are merged into that
+// poll. The host's details are read once it runs, so it uses the latest.
+func (p *Poller) pollChangedHost(ctx context.Context, namespace, name string) {
+	key := models.NodeKey(namespace, name)
+	p.pollsMu.Lock()
+	waiting := p.changedHosts[key]
+	p.changedHosts[key] = true
+	p.pollsMu.Unlock()
+	if waiting {
+		return
+	}
+
+	locked := p.lockNode(ctx, key, true, false)
+	p.pollsMu.Lock()
+	delete(p.changedHosts, key)
+	p.pollsMu.Unlock()
+	if !locked {
+		return
+	}
+	defer p.unlockNode(key)
+
+	host, ok := p.changedHost(ctx, namespace, name)
+	if !ok {
+		return
+	}
+	p.pollHost(ctx, host)
+}
+
+func (p *Poller) poll(ctx context.Context) {
+	log.Println("Starting firmware poll...")
 
 	hosts, err := p.discoverer.Discover(ctx)
 	if err != nil {
@@ -98,167 +259,406 @@ func (p *Poller) poll(ctx context.Context) {
 
 	log.Printf("Discovered %d hosts", len(hosts))
 
+	if p.baselines != nil {
+		if err := p.baselines.Refresh(ctx); err != nil {
+			log.Printf("Baseline refresh error: %v", err)
+		}
+	}
+
 	var wg sync.WaitGroup
 	for _, host := range hosts {
 		wg.Add(1)
 		go func(h discovery.DiscoveredHost) {
 			defer wg.Done()
+			// A node already being polled in full is not polled again
+			key := models.NodeKey(h.Namespace, h.Name)
+			if !p.lockNode(ctx, key, true, true) {
+				log.Printf("Skipping %s/%s, already being polled", h.Namespace, h.Name)
+				return
+			}
+			defer p.unlockNode(key)
 			p.pollHost(ctx, h)
 		}(host)
 	}
 	wg.Wait()
 
+	// Sync catalog after polling so it can be filtered to the models just
+	// inventoried, then re-apply it to every node
+	if p.catalog != nil && p.catalog.NeedsSync() {
+		if err := p.catalog.Sync(ctx); err != nil {
+			log.Printf("Catalog sync error: %v", err)
+		} else {
+			p.reapplyCatalog(ctx)
+		}
+	}
+
 	log.Println("Firmware poll complete")
 }
 
-func (p *Poller) pollHost(ctx context.Context, host discovery.DiscoveredHost) {
-	log.Printf("Polling %s at %s", host.Name, host.BMCAddress)
+// applyCatalog fills in available versions from the vendor's catalog, evaluates
+// the node against its baseline and derives its update counts and status
+func (p *Poller) applyCatalog(node *models.Node, vendor redfish.Vendor) {
+	firmware := node.Firmware
+	if p.catalog != nil && vendor.CatalogProvider() == p.catalog.Provider() {
+		for i := range firmware {
+			if entry, found := p.catalog.Match(node.SystemID, node.Model, firmware[i].Identity); found {
+				firmware[i].AvailableVersion = entry.Version
+			}
+		}
+	}
+	for i := range firmware {
+		firmware[i].VersionStatus = firmware[i].CompareVersions()
+	}
 
-	firmware, nodeInfo, err := p.redfish.GetFirmwareInventory(
-		ctx,
-		host.BMCAddress,
-		host.Credentials.Username,
-		host.Credentials.Password,
-	)
+	if p.baselines != nil {
+		p.baselines.Evaluate(node)
+	}
+	node.RefreshFirmwareStatus()
+}
 
-	node := models.Node{
-		Name:        host.Name,
-		Namespace:   host.Namespace,
-		BMCAddress:  host.BMCAddress,
-		LastScanned: time.Now(),
+// reapplyCatalog refreshes available versions on every node with a firmware
+// inventory. Each node is re-read once no poll of it is running, so a poll
+// that finished meanwhile is not overwritten.
+func (p *Poller) reapplyCatalog(ctx context.Context) {
+	for _, listed := range p.store.ListNodes() {
+		key := models.NodeKey(listed.Namespace, listed.Name)
+		if !p.lockNode(ctx, key, false, false) {
+			return
+		}
+		node, ok := p.store.GetNode(listed.Namespace, listed.Name)
+		if ok && len(node.Firmware) > 0 {
+			// Copy so readers of the stored node never see a partial update
+			previous := node
+			node.Firmware = slices.Clone(node.Firmware)
+			p.applyCatalog(&node, redfish.VendorFor(node.Manufacturer))
+			p.store.SetNode(node)
+			p.recordChanges(previous, node)
+		}
+		p.unlockNode(key)
 	}
+}
 
-	if err != nil {
-		log.Printf("Error polling %s: %v", host.Name, err)
-		node.Status = models.StatusUnknown
-		p.store.SetNode(node)
-		metrics.RecordScan(node.Name, false)
-		return
+// recordPollError classifies a failure to poll a BMC. Rejected credentials get
+// their own node status so they stand out from other failures.
+func recordPollError(node *models.Node, previous models.Node, err error) {
+	kind := redfish.ClassifyError(err)
+	node.PollError = models.NewPollError(kind, err, previous.PollError, node.LastScanned)
+	node.Status = models.StatusUnknown
+	if kind == models.ErrorAuth {
+		node.Status = models.StatusAuthFailed
 	}
+}
 
-	if nodeInfo != nil {
-		node.Model = nodeInfo.Model
-		node.Manufacturer = nodeInfo.Manufacturer
-		node.ServiceTag = nodeInfo.ServiceTag
-		node.PowerState = nodeInfo.PowerState
+// storeFailedPoll saves a host that could not be polled at all, recording err
+// as the outcome of every subsystem
+func (p *Poller) storeFailedPoll(host discovery.DiscoveredHost, node, previous models.Node, report *models.PollReport, opts redfish.CollectOptions, err error) {
+	for _, sub := range opts.Subsystems() {
+		report.Record(string(sub), err)
+	}
+	applyHostInventory(&node, host)
+	p.keepPrevious(&node, previous, report, opts.Subsystems())
+	finishReport(&node, report)
+	if p.storeNode(host, previous, node) {
+		metrics.RecordScan(node.Namespace, node.Name, false)
+	}
+}
 
-		// Skip non-Dell hardware
-		if !discovery.IsDellHardware(node.Manufacturer) {
-			log.Printf("Skipping non-Dell hardware: %s (%s)", host.Name, node.Manufacturer)
-			return
+// keepPrevious carries over the previous poll's data for each subsystem that
+// failed this time, so a BMC hiccup does not blank out what was known, and for
+// each subsystem left out of this poll. Data kept after a failure is listed in
+// the node's Stale map with when it was collected.
+func (p *Poller) keepPrevious(node *models.Node, previous models.Node, report *models.PollReport, polled []redfish.Subsystem) {
+	for _, sub := range (redfish.CollectOptions{Events: true}).Subsystems() {
+		name := string(sub)
+		if !slices.Contains(polled, sub) {
+			if p.copySubsystem(node, previous, sub) {
+				if stale, ok := previous.Stale[name]; ok {
+					markStale(node, name, stale.CollectedAt)
+				}
+			}
+			continue
+		}
+
+		status := report.Subsystems[name]
+		if status.OK || status.LastSuccess == nil {
+			continue
+		}
+		if p.copySubsystem(node, previous, sub) {
+			markStale(node, name, *status.LastSuccess)
 		}
 	}
+}
 
-	// Enrich firmware with available versions from catalog
-	if p.catalog != nil {
-		for i := range firmware {
-			if version, found := p.catalog.GetLatestVersion(node.Model, firmware[i].ComponentType); found {
-				firmware[i].AvailableVersion = version
-			}
+// copySubsystem copies one subsystem's data from the previous node, reporting
+// whether there was any to copy
+func (p *Poller) copySubsystem(node *models.Node, previous models.Node, sub redfish.Subsystem) bool {
+	switch sub {
+	case redfish.SubsystemFirmware:
+		if previous.Firmware == nil {
+			return false
 		}
+		if node.InventorySource != models.InventoryBMC && previous.InventorySource == models.InventoryBMC {
+			node.Model = previous.Model
+			node.Manufacturer = previous.Manufacturer
+			node.ServiceTag = previous.ServiceTag
+			node.SystemID = previous.SystemID
+			node.PowerState = previous.PowerState
+			node.InventorySource = models.InventoryBMC
+		}
+		node.Firmware = slices.Clone(previous.Firmware)
+		p.applyCatalog(node, redfish.VendorFor(node.Manufacturer))
+		// Rejected credentials stay visible in the status
+		if node.PollError != nil && node.PollError.Kind == models.ErrorAuth {
+			node.Status = models.StatusAuthFailed
+		}
+	case redfish.SubsystemHealth:
+		if previous.HealthRollup == nil {
+			return false
+		}
+		node.Health = previous.Health
+		node.HealthRollup = previous.HealthRollup
+	case redfish.SubsystemThermal:
+		if previous.ThermalSummary == nil {
+			return false
+		}
+		node.ThermalSummary = previous.ThermalSummary
+	case redfish.SubsystemPower:
+		if previous.PowerSummary == nil {
+			return false
+		}
+		node.PowerSummary = previous.PowerSummary
+	case redfish.SubsystemNetwork:
+		if previous.NetworkAdapters == nil {
+			return false
+		}
+		node.NetworkAdapters = previous.NetworkAdapters
+	case redfish.SubsystemStorage:
+		if previous.Storage == nil {
+			return false
+		}
+		node.Storage = previous.Storage
+	default:
+		// Events are kept by the event store
+		return false
+	}
+	return true
+}
+
+// markStale records that a subsystem's data was collected at an earlier poll
+func markStale(node *models.Node, subsystem string, collectedAt time.Time) {
+	if node.Stale == nil {
+		node.Stale = make(map[string]models.StaleData)
+	}
+	node.Stale[subsystem] = models.StaleData{CollectedAt: collectedAt}
+}
+
+// finishReport completes a poll report and attaches it to the node
+func finishReport(node *models.Node, report *models.PollReport) {
+	report.Finish(time.Now())
+	node.PollReport = report
+	node.PollSummary = report.Summary()
+}
+
+// storeNode saves a poll result unless the host was deleted while it was being
+// polled, recording what changed since the previous poll
+func (p *Poller) storeNode(host discovery.DiscoveredHost, previous, node models.Node) bool {
+	if p.discoverer != nil && !p.discoverer.Exists(host.Namespace, host.Name) {
+		log.Printf("Discarding poll result for deleted host %s/%s", host.Namespace, host.Name)
+		return false
 	}
+	p.store.SetNode(node)
+	p.recordChanges(previous, node)
+	return true
+}
 
-	node.Firmware = firmware
-	node.FirmwareCount = len(firmware)
+// recordChanges records the changes between two snapshots of a node. Nothing
+// is recorded for a node's first poll.
+func (p *Poller) recordChanges(previous, node models.Node) {
+	if p.changes == nil || previous.Name == "" {
+		return
+	}
+	if detected := changes.Detect(previous, node); len(detected) > 0 {
+		p.changes.AddChanges(detected)
+		log.Printf("Detected %d changes on %s/%s", len(detected), node.Namespace, node.Name)
+	}
+}
 
-	// Count updates available (will be populated when catalog is integrated)
-	updatesNeeded := 0
-	for _, fw := range firmware {
-		if fw.NeedsUpdate() {
-			updatesNeeded++
+// applyHostInventory fills in hardware details from the BareMetalHost's last
+// inspection when the BMC could not provide them, so an unreachable host still
+// shows its model, NICs and disks
+func applyHostInventory(node *models.Node, host discovery.DiscoveredHost) {
+	if host.Status.PoweredOn != nil {
+		node.PowerState = models.PowerOff
+		if *host.Status.PoweredOn {
+			node.PowerState = models.PowerOn
 		}
 	}
-	node.UpdatesAvailable = updatesNeeded
 
-	if updatesNeeded > 0 {
-		node.Status = models.StatusNeedsUpdate
-	} else {
-		node.Status = models.StatusUpToDate
+	hw := host.Status.Hardware
+	if hw == nil {
+		return
+	}
+	node.InventorySource = models.InventoryBareMetalHost
+	node.Model = hw.SystemVendor.Model()
+	node.Manufacturer = hw.SystemVendor.Manufacturer
+	node.ServiceTag = hw.SystemVendor.SerialNumber
+
+	node.NetworkAdapters = make([]models.NetworkAdapter, 0, len(hw.NICs))
+	for _, nic := range hw.NICs {
+		node.NetworkAdapters = append(node.NetworkAdapters, models.NetworkAdapter{
+			Name:       nic.Name,
+			Model:      nic.Model,
+			LinkStatus: "Unknown",
+			LinkSpeed:  redfish.FormatLinkSpeed(nic.SpeedGbps * 1000),
+			MACAddress: nic.MAC,
+		})
+	}
+
+	node.Storage = &models.StorageDetail{
+		Controllers: []models.StorageController{},
+		Disks:       make([]models.Disk, 0, len(hw.Storage)),
+	}
+	for _, disk := range hw.Storage {
+		node.Storage.Disks = append(node.Storage.Disks, models.Disk{
+			Name:      disk.Name,
+			State:     "Unknown",
+			Size:      redfish.FormatCapacity(disk.SizeBytes),
+			MediaType: disk.Type,
+		})
+	}
+}
+
+func (p *Poller) pollHost(ctx context.Context, host discovery.DiscoveredHost) {
+	p.pollSubsystems(ctx, host, nil)
+}
+
+// pollSubsystems polls the given subsystems of a host, or all of them if none
+// are given. Subsystems not polled keep their data from the stored node.
+func (p *Poller) pollSubsystems(ctx context.Context, host discovery.DiscoveredHost, only []redfish.Subsystem) {
+	started := time.Now()
+	previous, _ := p.store.GetNode(host.Namespace, host.Name)
+	report := models.NewPollReport(previous.PollReport, started)
+	opts := redfish.CollectOptions{
+		Events:     p.eventStore != nil,
+		EventLimit: 50, // read at most 50 new entries per log each poll
+		Only:       only,
+	}
+	if p.eventStore != nil {
+		// Only read SEL entries newer than those already stored
+		opts.EventMarks = p.eventStore.Marks(host.Namespace, host.Name)
+	}
+
+	node := models.Node{
+		Name:              host.Name,
+		Namespace:         host.Namespace,
+		BMCAddress:        host.BMCAddress,
+		LastScanned:       started,
+		Status:            models.StatusUnknown,
+		ProvisioningState: host.Status.ProvisioningState,
+		OperationalStatus: host.Status.OperationalStatus,
+		HostError:         host.Status.ErrorMessage,
+		Hardware:          host.Status.Hardware,
+		KubeNode:          host.KubeNode,
+	}
+
+	// Hosts without a usable BMC address or credentials are reported, not polled
+	if host.Err != nil {
+		node.PollError = models.NewPollError(models.ErrorDiscovery, host.Err, previous.PollError, started)
+		p.storeFailedPoll(host, node, previous, report, opts, host.Err)
+		return
 	}
 
-	// Get system health
-	healthRollup, overallHealth, err := p.redfish.GetSystemHealth(
+	log.Printf("Polling %s at %s", host.Name, host.BMCAddress)
+
+	data, err := p.redfish.Collect(
 		ctx,
-		host.BMCAddress,
+		host.BMC,
 		host.Credentials.Username,
 		host.Credentials.Password,
+		opts,
 	)
+
 	if err != nil {
+		log.Printf("Error polling %s: %v", host.Name, err)
+		var certErr *redfish.CertificateError
+		if errors.As(err, &certErr) {
+			node.BMCCertificate = certErr.Certificate
+		}
+		recordPollError(&node, previous, err)
+		p.storeFailedPoll(host, node, previous, report, opts, err)
+		return
+	}
+
+	for _, sub := range opts.Subsystems() {
+		report.Record(string(sub), data.Err(sub))
+	}
+	node.BMCCertificate = data.Certificate
+	if data.System != nil {
+		node.Model = data.System.Model
+		node.Manufacturer = data.System.Manufacturer
+		node.ServiceTag = data.System.ServiceTag
+		node.SystemID = data.System.SystemID
+		node.PowerState = data.System.PowerState
+		node.InventorySource = models.InventoryBMC
+	} else {
+		applyHostInventory(&node, host)
+	}
+
+	firmware := data.Firmware
+	if err := data.Err(redfish.SubsystemFirmware); err != nil {
+		log.Printf("Error getting firmware inventory for %s: %v", host.Name, err)
+		recordPollError(&node, previous, err)
+	} else {
+		node.Firmware = firmware
+		p.applyCatalog(&node, data.Vendor)
+	}
+
+	if err := data.Err(redfish.SubsystemHealth); err != nil {
 		log.Printf("Error getting health for %s: %v", host.Name, err)
 	} else {
-		node.Health = overallHealth
-		node.HealthRollup = healthRollup
+		node.Health = data.Health
+		node.HealthRollup = data.HealthRollup
 	}
 
-	// Get thermal data
-	_, thermalSummary, err := p.redfish.GetThermalData(
-		ctx,
-		host.BMCAddress,
-		host.Credentials.Username,
-		host.Credentials.Password,
-	)
-	if err != nil {
+	if err := data.Err(redfish.SubsystemThermal); err != nil {
 		log.Printf("Error getting thermal data for %s: %v", host.Name, err)
 	} else {
-		node.ThermalSummary = thermalSummary
+		node.ThermalSummary = data.ThermalSummary
 	}
 
-	// Get power data
-	_, powerSummary, err := p.redfish.GetPowerData(
-		ctx,
-		host.BMCAddress,
-		host.Credentials.Username,
-		host.Credentials.Password,
-	)
-	if err != nil {
+	if err := data.Err(redfish.SubsystemPower); err != nil {
 		log.Printf("Error getting power data for %s: %v", host.Name, err)
 	} else {
-		node.PowerSummary = powerSummary
+		node.PowerSummary = data.PowerSummary
 	}
 
-	// Get network adapter details
-	networkAdapters, err := p.redfish.GetNetworkAdapters(
-		ctx,
-		host.BMCAddress,
-		host.Credentials.Username,
-		host.Credentials.Password,
-	)
-	if err != nil {
+	if err := data.Err(redfish.SubsystemNetwork); err != nil {
 		log.Printf("Failed to get network adapters for %s: %v", host.Name, err)
 	} else {
-		node.NetworkAdapters = networkAdapters
+		node.NetworkAdapters = data.NetworkAdapters
 	}
 
-	// Get storage details
-	storageDetail, err := p.redfish.GetStorageDetails(
-		ctx,
-		host.BMCAddress,
-		host.Credentials.Username,
-		host.Credentials.Password,
-	)
-	if err != nil {
+	if err := data.Err(redfish.SubsystemStorage); err != nil {
 		log.Printf("Failed to get storage details for %s: %v", host.Name, err)
 	} else {
-		node.Storage = storageDetail
+		node.Storage = data.Storage
 	}
 
-	// Get events and add to event store
-	if p.eventStore != nil {
-		events, err := p.redfish.GetEvents(
-			ctx,
-			host.BMCAddress,
-			host.Credentials.Username,
-			host.Credentials.Password,
-			50, // limit to 50 most recent events
-		)
-		if err != nil {
+	// Add events to event store
+	if slices.Contains(opts.Subsystems(), redfish.SubsystemEvents) {
+		if err := data.Err(redfish.SubsystemEvents); err != nil {
 			log.Printf("Error getting events for %s: %v", host.Name, err)
 		} else {
-			p.eventStore.AddEvents(host.Name, events)
+			p.eventStore.AddEvents(host.Namespace, host.Name, data.Events)
+			p.eventStore.SetMarks(host.Namespace, host.Name, data.EventMarks)
 		}
 	}
 
-	p.store.SetNode(node)
-	metrics.RecordScan(node.Name, true)
+	p.keepPrevious(&node, previous, report, opts.Subsystems())
+	finishReport(&node, report)
+	if !p.storeNode(host, previous, node) {
+		return
+	}
+	metrics.RecordScan(node.Namespace, node.Name, data.Err(redfish.SubsystemFirmware) == nil)
 	log.Printf("Updated firmware inventory for %s: %d components", host.Name, len(firmware))
 }
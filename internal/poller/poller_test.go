@@ -1,8 +1,16 @@
 package poller
 
 import (
+	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/discovery"
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
 )
 
 func TestNewPoller(t *testing.T) {
@@ -22,3 +30,235 @@ func TestNewPollerWithCatalog(t *testing.T) {
 		t.Fatal("expected non-nil poller")
 	}
 }
+
+func TestApplyHostInventory(t *testing.T) {
+	poweredOn := true
+	host := discovery.DiscoveredHost{
+		Name: "worker-0",
+		Status: discovery.HostStatus{
+			PoweredOn: &poweredOn,
+			Hardware: &models.HostHardware{
+				SystemVendor: models.HostSystemVendor{Manufacturer: "Dell Inc.", ProductName: "PowerEdge R640", SerialNumber: "ABC1234"},
+				NICs:         []models.HostNIC{{Name: "eno1", MAC: "aa:bb:cc:dd:ee:ff", SpeedGbps: 25}},
+				Storage:      []models.HostDisk{{Name: "/dev/sda", Type: "SSD", SizeBytes: 480103981056}},
+			},
+		},
+	}
+
+	node := models.Node{Name: "worker-0"}
+	applyHostInventory(&node, host)
+
+	if node.InventorySource != models.InventoryBareMetalHost {
+		t.Errorf("expected inventory source %q, got %q", models.InventoryBareMetalHost, node.InventorySource)
+	}
+	if node.Model != "PowerEdge R640" || node.ServiceTag != "ABC1234" || node.PowerState != models.PowerOn {
+		t.Errorf("unexpected system details: %+v", node)
+	}
+	if len(node.NetworkAdapters) != 1 || node.NetworkAdapters[0].LinkSpeed != "25 Gbps" {
+		t.Errorf("unexpected network adapters: %+v", node.NetworkAdapters)
+	}
+	if node.Storage == nil || len(node.Storage.Disks) != 1 || node.Storage.Disks[0].Size != "480 GB" {
+		t.Errorf("unexpected storage: %+v", node.Storage)
+	}
+
+	// Without an inspection only the power state is known
+	node = models.Node{Name: "worker-1"}
+	applyHostInventory(&node, discovery.DiscoveredHost{Status: discovery.HostStatus{PoweredOn: new(bool)}})
+	if node.InventorySource != "" || node.PowerState != models.PowerOff {
+		t.Errorf("unexpected node without hardware: %+v", node)
+	}
+}
+
+func TestPollHost_Errors(t *testing.T) {
+	s := store.New()
+	p := New(nil, redfish.NewClient(), s, nil, nil, 30*time.Minute)
+	ctx := context.Background()
+
+	// A host whose credentials could not be read is stored with a discovery error
+	host := discovery.DiscoveredHost{Name: "worker-0", Namespace: "ns-a", Err: errors.New("credentials secret not found for worker-0")}
+	p.pollHost(ctx, host)
+	node, ok := s.GetNode("ns-a", "worker-0")
+	if !ok || node.PollError == nil || node.PollError.Kind != models.ErrorDiscovery || node.Status != models.StatusUnknown {
+		t.Fatalf("expected discovery error node, got %+v", node)
+	}
+	firstSeen := node.PollError.FirstSeen
+
+	// Once the host can be read, an unreachable BMC is a new kind of failure
+	host.Err = nil
+	host.BMC = models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"}
+	p.pollHost(ctx, host)
+	node, _ = s.GetNode("ns-a", "worker-0")
+	if node.PollError == nil || node.PollError.Kind != models.ErrorUnreachable {
+		t.Fatalf("expected unreachable error, got %+v", node.PollError)
+	}
+	unreachableSince := node.PollError.FirstSeen
+	if unreachableSince.Before(firstSeen) {
+		t.Errorf("expected first occurrence to restart, got %v before %v", unreachableSince, firstSeen)
+	}
+
+	// Repeating the same failure keeps its first occurrence
+	p.pollHost(ctx, host)
+	node, _ = s.GetNode("ns-a", "worker-0")
+	if node.PollError == nil || !node.PollError.FirstSeen.Equal(unreachableSince) {
+		t.Errorf("expected first occurrence %v to be kept, got %+v", unreachableSince, node.PollError)
+	}
+
+	// Every subsystem is reported as failed, and the failed polls are counted
+	report := node.PollReport
+	if report == nil || report.OK || report.ConsecutiveFailures != 3 || len(report.Subsystems) != 6 {
+		t.Fatalf("unexpected poll report: %+v", report)
+	}
+	if thermal := report.Subsystems["thermal"]; thermal.OK || thermal.ConsecutiveFailures != 3 || thermal.Error == "" {
+		t.Errorf("unexpected thermal status: %+v", thermal)
+	}
+	if node.PollSummary == nil || len(node.PollSummary.FailedSubsystems) != 6 {
+		t.Errorf("unexpected poll summary: %+v", node.PollSummary)
+	}
+}
+
+func TestPollHost_KeepsStaleData(t *testing.T) {
+	s := store.New()
+	p := New(nil, redfish.NewClient(), s, nil, nil, 30*time.Minute)
+
+	// The last successful poll collected firmware and thermal data
+	collected := time.Now().Add(-10 * time.Minute)
+	report := models.NewPollReport(nil, collected)
+	for _, sub := range (redfish.CollectOptions{}).Subsystems() {
+		report.Record(string(sub), nil)
+	}
+	report.Finish(collected)
+	s.SetNode(models.Node{
+		Name:            "worker-0",
+		Namespace:       "ns-a",
+		Model:           "PowerEdge R650",
+		Manufacturer:    "Dell Inc.",
+		InventorySource: models.InventoryBMC,
+		Firmware:        []models.FirmwareComponent{{ID: "bios", Name: "BIOS", CurrentVersion: "1.2.3"}},
+		ThermalSummary:  &models.ThermalSummary{InletTempC: 22, Status: models.HealthOK},
+		PollReport:      report,
+	})
+
+	host := discovery.DiscoveredHost{
+		Name:      "worker-0",
+		Namespace: "ns-a",
+		BMC:       models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"},
+	}
+	p.pollHost(context.Background(), host)
+
+	node, _ := s.GetNode("ns-a", "worker-0")
+	if len(node.Firmware) != 1 || node.Model != "PowerEdge R650" || node.InventorySource != models.InventoryBMC {
+		t.Errorf("expected firmware and system info to be kept, got %+v", node)
+	}
+	if node.ThermalSummary == nil || node.ThermalSummary.InletTempC != 22 {
+		t.Errorf("expected thermal summary to be kept, got %+v", node.ThermalSummary)
+	}
+	if node.PollError == nil || node.PollError.Kind != models.ErrorUnreachable {
+		t.Errorf("expected unreachable error, got %+v", node.PollError)
+	}
+
+	// Only subsystems that had data are marked stale
+	if len(node.Stale) != 2 {
+		t.Fatalf("expected firmware and thermal to be stale, got %+v", node.Stale)
+	}
+	thermal, ok := node.Stale["thermal"]
+	if !ok || !thermal.CollectedAt.Equal(collected) || thermal.AgeSeconds != 0 {
+		t.Errorf("unexpected thermal staleness: %+v", thermal)
+	}
+	if _, ok := node.Stale["power"]; ok {
+		t.Error("expected power without data not to be stale")
+	}
+}
+
+func TestStoreNode_RecordsChanges(t *testing.T) {
+	s := store.New()
+	changes := store.NewChangeStore(100)
+	p := New(nil, redfish.NewClient(), s, nil, nil, 30*time.Minute)
+	p.SetChangeStore(changes)
+	host := discovery.DiscoveredHost{Name: "worker-0", Namespace: "ns-a"}
+
+	// Nothing is recorded for a node's first poll
+	first := models.Node{Name: "worker-0", Namespace: "ns-a", Health: models.HealthOK}
+	p.storeNode(host, models.Node{}, first)
+	if got := changes.ListChanges(0, "", "", ""); len(got) != 0 {
+		t.Fatalf("expected no changes on first poll, got %+v", got)
+	}
+
+	second := first
+	second.Health = models.HealthCritical
+	p.storeNode(host, first, second)
+	got := changes.ListChanges(0, "ns-a", "worker-0", "")
+	if len(got) != 1 || got[0].Type != models.ChangeHealth || got[0].Current != "Critical" {
+		t.Fatalf("unexpected changes: %+v", got)
+	}
+
+	p.HostDeleted("ns-a", "worker-0")
+	if got := changes.ListChanges(0, "", "", ""); len(got) != 0 {
+		t.Errorf("expected changes to be removed with the host, got %+v", got)
+	}
+}
+
+func TestPollChangedHost_WaitsAndMerges(t *testing.T) {
+	s := store.New()
+	p := New(nil, redfish.NewClient(), s, nil, nil, 30*time.Minute)
+	ctx := context.Background()
+
+	// A poll of the node is in progress
+	key := models.NodeKey("ns-a", "worker-0")
+	p.lockNode(ctx, key, true, false)
+
+	// The host's details are read when the poll runs
+	var poweredOn atomic.Bool
+	var lookups atomic.Int32
+	p.changedHost = func(ctx context.Context, namespace, name string) (discovery.DiscoveredHost, bool) {
+		lookups.Add(1)
+		on := poweredOn.Load()
+		return discovery.DiscoveredHost{
+			Name:      name,
+			Namespace: namespace,
+			BMC:       models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"},
+			Status:    discovery.HostStatus{PoweredOn: &on},
+		}, true
+	}
+	done := make(chan struct{})
+	go func() {
+		p.pollChangedHost(ctx, "ns-a", "worker-0")
+		close(done)
+	}()
+	waitForChangedHost(t, p, key)
+	if _, ok := s.GetNode("ns-a", "worker-0"); ok {
+		t.Fatal("expected the change poll to wait for the running poll")
+	}
+
+	// A second change while the first waits is merged into it
+	poweredOn.Store(true)
+	p.pollChangedHost(ctx, "ns-a", "worker-0")
+
+	p.unlockNode(key)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("change poll did not run after the poll finished")
+	}
+	node, ok := s.GetNode("ns-a", "worker-0")
+	if !ok || node.PollReport.ConsecutiveFailures != 1 || node.PowerState != models.PowerOn || lookups.Load() != 1 {
+		t.Errorf("expected one poll with the latest host details, got %d lookups and %+v", lookups.Load(), node)
+	}
+}
+
+// waitForChangedHost waits until a change poll of a node is waiting to run
+func waitForChangedHost(t *testing.T, p *Poller, key string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		p.pollsMu.Lock()
+		waiting := p.changedHosts[key]
+		p.pollsMu.Unlock()
+		if waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("change poll was not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
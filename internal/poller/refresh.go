@@ -0,0 +1,328 @@
+package poller
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/discovery"
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
+)
+
+var (
+	// ErrInvalidSubsystem is returned when a refresh names an unknown subsystem
+	ErrInvalidSubsystem = errors.New("invalid subsystem")
+	// ErrRefreshRateLimited is returned when a node was refreshed too recently
+	ErrRefreshRateLimited = errors.New("node was refreshed too recently")
+)
+
+const (
+	// DefaultRefreshInterval is the minimum time between refreshes of one node
+	DefaultRefreshInterval = 30 * time.Second
+	// refreshRetention is how long finished refreshes can still be queried
+	refreshRetention = time.Hour
+	// refreshWorkers is how many queued node refreshes run at once, so a fleet
+	// refresh does not open a BMC session to every node at the same time
+	refreshWorkers = 8
+	// refreshQueueSize is how many node refreshes can wait for a worker
+	refreshQueueSize = 1024
+)
+
+// hostLookup resolves a node to its BareMetalHost, see discovery.Discoverer.GetHost
+type hostLookup func(ctx context.Context, namespace, name string) (*discovery.DiscoveredHost, error)
+
+// nodeRefresh is a queued poll of one node. Requests for a node that is still
+// queued are merged into it rather than polling it twice.
+type nodeRefresh struct {
+	namespace string
+	name      string
+	only      []redfish.Subsystem // empty = all
+	ids       []string
+}
+
+// SetRefreshInterval sets the minimum time between on-demand refreshes of a node
+func (p *Poller) SetRefreshInterval(d time.Duration) {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+	p.refreshInterval = d
+}
+
+// RefreshNode queues an immediate poll of one node, limited to the given
+// subsystems if any are given
+func (p *Poller) RefreshNode(namespace, name string, subsystems []string) (*models.Refresh, error) {
+	only, err := p.parseSubsystems(subsystems)
+	if err != nil {
+		return nil, err
+	}
+
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	key := models.NodeKey(namespace, name)
+	if _, queued := p.pendingRefreshes[key]; !queued {
+		if wait := p.refreshWait(key); wait > 0 {
+			return nil, fmt.Errorf("%w, retry in %s", ErrRefreshRateLimited, wait.Round(time.Second))
+		}
+	}
+	refresh := p.newRefresh(subsystems)
+	p.queueRefresh(refresh, namespace, name, only)
+	p.updateRefreshState(refresh)
+	return cloneRefresh(refresh), nil
+}
+
+// RefreshAll queues an immediate poll of every known node, limited to the
+// given subsystems if any are given. Nodes refreshed too recently are skipped.
+func (p *Poller) RefreshAll(subsystems []string) (*models.Refresh, error) {
+	only, err := p.parseSubsystems(subsystems)
+	if err != nil {
+		return nil, err
+	}
+
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	refresh := p.newRefresh(subsystems)
+	for _, node := range p.store.ListNodes() {
+		p.queueRefresh(refresh, node.Namespace, node.Name, only)
+	}
+	p.updateRefreshState(refresh)
+	return cloneRefresh(refresh), nil
+}
+
+// Refresh returns the progress of a refresh by ID
+func (p *Poller) Refresh(id string) (*models.Refresh, bool) {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	refresh, ok := p.refreshes[id]
+	if !ok {
+		return nil, false
+	}
+	return cloneRefresh(refresh), true
+}
+
+// parseSubsystems validates the subsystem names of a refresh request
+func (p *Poller) parseSubsystems(names []string) ([]redfish.Subsystem, error) {
+	valid := (redfish.CollectOptions{Events: p.eventStore != nil}).Subsystems()
+	only := make([]redfish.Subsystem, 0, len(names))
+	for _, name := range names {
+		sub := redfish.Subsystem(strings.ToLower(strings.TrimSpace(name)))
+		if !slices.Contains(valid, sub) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidSubsystem, name)
+		}
+		if !slices.Contains(only, sub) {
+			only = append(only, sub)
+		}
+	}
+	return only, nil
+}
+
+// newRefresh registers a refresh, dropping those that finished long ago.
+// Called with refreshMu held.
+func (p *Poller) newRefresh(subsystems []string) *models.Refresh {
+	now := time.Now()
+	for id, r := range p.refreshes {
+		if r.FinishedAt != nil && now.Sub(*r.FinishedAt) > refreshRetention {
+			delete(p.refreshes, id)
+		}
+	}
+
+	refresh := &models.Refresh{
+		ID:          strings.ToLower(rand.Text()),
+		Subsystems:  subsystems,
+		State:       models.RefreshQueued,
+		RequestedAt: now,
+		Nodes:       []models.RefreshNode{},
+	}
+	p.refreshes[refresh.ID] = refresh
+	return refresh
+}
+
+// refreshWait returns how long until a node may be refreshed again. Called
+// with refreshMu held.
+func (p *Poller) refreshWait(key string) time.Duration {
+	last, ok := p.lastRefresh[key]
+	if !ok {
+		return 0
+	}
+	return p.refreshInterval - time.Since(last)
+}
+
+// queueRefresh adds a node to a refresh, merging it into a poll of the node
+// that is already queued. Called with refreshMu held.
+func (p *Poller) queueRefresh(refresh *models.Refresh, namespace, name string, only []redfish.Subsystem) {
+	key := models.NodeKey(namespace, name)
+	entry := models.RefreshNode{Namespace: namespace, Name: name, State: models.RefreshQueued}
+
+	if pending, ok := p.pendingRefreshes[key]; ok {
+		if len(pending.only) > 0 && len(only) > 0 {
+			for _, sub := range only {
+				if !slices.Contains(pending.only, sub) {
+					pending.only = append(pending.only, sub)
+				}
+			}
+		} else {
+			pending.only = nil
+		}
+		pending.ids = append(pending.ids, refresh.ID)
+		refresh.Nodes = append(refresh.Nodes, entry)
+		return
+	}
+
+	if wait := p.refreshWait(key); wait > 0 {
+		entry.State = models.RefreshSkipped
+		entry.Error = fmt.Sprintf("refreshed too recently, retry in %s", wait.Round(time.Second))
+		refresh.Nodes = append(refresh.Nodes, entry)
+		return
+	}
+
+	pending := &nodeRefresh{namespace: namespace, name: name, only: slices.Clone(only), ids: []string{refresh.ID}}
+	select {
+	case p.refreshQueue <- pending:
+		p.pendingRefreshes[key] = pending
+		p.lastRefresh[key] = time.Now()
+	default:
+		entry.State = models.RefreshSkipped
+		entry.Error = "refresh queue full"
+	}
+	refresh.Nodes = append(refresh.Nodes, entry)
+}
+
+// runRefreshes runs queued node refreshes one at a time until stopped. Start
+// runs refreshWorkers of them.
+func (p *Poller) runRefreshes(ctx context.Context, stopCh chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopCh:
+			return
+		case pending := <-p.refreshQueue:
+			p.runRefresh(ctx, pending)
+		}
+	}
+}
+
+// runRefresh polls a queued node and records the outcome on every refresh
+// waiting for it. If the node is already being polled, the refresh stays
+// queued until that poll finishes, so requests made meanwhile are merged into it.
+func (p *Poller) runRefresh(ctx context.Context, pending *nodeRefresh) {
+	key := models.NodeKey(pending.namespace, pending.name)
+	if !p.lockNode(ctx, key, false, false) {
+		p.refreshMu.Lock()
+		if p.pendingRefreshes[key] == pending {
+			delete(p.pendingRefreshes, key)
+		}
+		p.setRefreshNodes(pending.ids, pending, models.RefreshFailed, ctx.Err().Error())
+		p.refreshMu.Unlock()
+		return
+	}
+	defer p.unlockNode(key)
+
+	p.refreshMu.Lock()
+	if p.pendingRefreshes[key] == pending {
+		delete(p.pendingRefreshes, key)
+	}
+	only, ids := pending.only, pending.ids
+	p.setRefreshNodes(ids, pending, models.RefreshRunning, "")
+	p.refreshMu.Unlock()
+
+	state, message := models.RefreshDone, ""
+	host, err := p.getHost(ctx, pending.namespace, pending.name)
+	if err != nil {
+		state, message = models.RefreshFailed, err.Error()
+	} else {
+		log.Printf("Refreshing %s/%s", pending.namespace, pending.name)
+		p.pollSubsystems(ctx, *host, only)
+		if failed := p.failedSubsystems(pending.namespace, pending.name, only); failed != "" {
+			state, message = models.RefreshFailed, failed
+		}
+	}
+
+	p.refreshMu.Lock()
+	p.setRefreshNodes(ids, pending, state, message)
+	p.refreshMu.Unlock()
+}
+
+// failedSubsystems describes the polled subsystems that failed on the stored node
+func (p *Poller) failedSubsystems(namespace, name string, only []redfish.Subsystem) string {
+	node, ok := p.store.GetNode(namespace, name)
+	if !ok || node.PollReport == nil {
+		return "node no longer exists"
+	}
+
+	opts := redfish.CollectOptions{Events: p.eventStore != nil, Only: only}
+	var failed []string
+	for _, sub := range opts.Subsystems() {
+		if status, ok := node.PollReport.Subsystems[string(sub)]; ok && !status.OK {
+			failed = append(failed, fmt.Sprintf("%s: %s", sub, status.Error))
+		}
+	}
+	return strings.Join(failed, "; ")
+}
+
+// setRefreshNodes sets the state of a node in each refresh waiting on it.
+// Called with refreshMu held.
+func (p *Poller) setRefreshNodes(ids []string, pending *nodeRefresh, state models.RefreshState, message string) {
+	for _, id := range ids {
+		refresh, ok := p.refreshes[id]
+		if !ok {
+			continue
+		}
+		for i := range refresh.Nodes {
+			n := &refresh.Nodes[i]
+			if n.Namespace == pending.namespace && n.Name == pending.name {
+				n.State = state
+				n.Error = message
+			}
+		}
+		p.updateRefreshState(refresh)
+	}
+}
+
+// updateRefreshState derives a refresh's state from its nodes. A finished
+// refresh is done if every node was, failed if none was, and partial
+// otherwise. Called with refreshMu held.
+func (p *Poller) updateRefreshState(refresh *models.Refresh) {
+	state := models.RefreshDone
+	done, finished := 0, 0
+	for _, n := range refresh.Nodes {
+		switch n.State {
+		case models.RefreshRunning:
+			state = models.RefreshRunning
+		case models.RefreshQueued:
+			if state != models.RefreshRunning {
+				state = models.RefreshQueued
+			}
+		case models.RefreshDone:
+			done++
+			finished++
+		default:
+			finished++
+		}
+	}
+	if state == models.RefreshDone && done < finished {
+		state = models.RefreshPartial
+		if done == 0 {
+			state = models.RefreshFailed
+		}
+	}
+	refresh.State = state
+	if state != models.RefreshQueued && state != models.RefreshRunning && refresh.FinishedAt == nil {
+		now := time.Now()
+		refresh.FinishedAt = &now
+	}
+}
+
+func cloneRefresh(r *models.Refresh) *models.Refresh {
+	c := *r
+	c.Subsystems = slices.Clone(r.Subsystems)
+	c.Nodes = slices.Clone(r.Nodes)
+	return &c
+}
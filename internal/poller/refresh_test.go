@@ -0,0 +1,415 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/discovery"
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+)
+
+// newRefreshPoller returns a poller whose hosts all have an unreachable BMC
+func newRefreshPoller(s *store.Store) *Poller {
+	p := New(nil, redfish.NewClient(), s, nil, nil, 30*time.Minute)
+	p.getHost = func(ctx context.Context, namespace, name string) (*discovery.DiscoveredHost, error) {
+		return &discovery.DiscoveredHost{
+			Name:      name,
+			Namespace: namespace,
+			BMC:       models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"},
+		}, nil
+	}
+	return p
+}
+
+// runQueued runs every queued node refresh
+func runQueued(t *testing.T, p *Poller) int {
+	t.Helper()
+	ran := 0
+	for {
+		select {
+		case pending := <-p.refreshQueue:
+			p.runRefresh(context.Background(), pending)
+			ran++
+		default:
+			return ran
+		}
+	}
+}
+
+func TestPoller_RefreshNode(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a"})
+	p := newRefreshPoller(s)
+
+	first, err := p.RefreshNode("ns-a", "worker-0", []string{"thermal"})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if first.State != models.RefreshQueued || len(first.Nodes) != 1 {
+		t.Fatalf("unexpected refresh: %+v", first)
+	}
+
+	// A second request while the first is queued is merged into the same poll
+	second, err := p.RefreshNode("ns-a", "worker-0", []string{"Power"})
+	if err != nil {
+		t.Fatalf("coalesced refresh: %v", err)
+	}
+	if second.ID == first.ID {
+		t.Error("expected each request to get its own ID")
+	}
+	if ran := runQueued(t, p); ran != 1 {
+		t.Fatalf("expected one poll, got %d", ran)
+	}
+
+	for _, id := range []string{first.ID, second.ID} {
+		refresh, ok := p.Refresh(id)
+		if !ok {
+			t.Fatalf("refresh %s not found", id)
+		}
+		node := refresh.Nodes[0]
+		if refresh.State != models.RefreshFailed || refresh.FinishedAt == nil || node.State != models.RefreshFailed {
+			t.Errorf("unexpected refresh %s: %+v", id, refresh)
+		}
+		if !strings.Contains(node.Error, "thermal:") || !strings.Contains(node.Error, "power:") {
+			t.Errorf("expected thermal and power failures, got %q", node.Error)
+		}
+	}
+
+	// Only the selected subsystems were polled
+	node, _ := s.GetNode("ns-a", "worker-0")
+	if len(node.PollReport.Subsystems) != 2 {
+		t.Errorf("expected thermal and power in the report, got %+v", node.PollReport.Subsystems)
+	}
+
+	// Once polled, the node is rate limited
+	if _, err := p.RefreshNode("ns-a", "worker-0", nil); !errors.Is(err, ErrRefreshRateLimited) {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+	p.SetRefreshInterval(0)
+	if _, err := p.RefreshNode("ns-a", "worker-0", nil); err != nil {
+		t.Errorf("expected refresh without rate limit, got %v", err)
+	}
+
+	if _, err := p.RefreshNode("ns-a", "worker-0", []string{"fans"}); !errors.Is(err, ErrInvalidSubsystem) {
+		t.Errorf("expected invalid subsystem error, got %v", err)
+	}
+	// Events are only polled with an event store
+	if _, err := p.RefreshNode("ns-a", "worker-0", []string{"events"}); !errors.Is(err, ErrInvalidSubsystem) {
+		t.Errorf("expected events to be rejected without an event store, got %v", err)
+	}
+	if _, ok := p.Refresh("unknown"); ok {
+		t.Error("expected unknown refresh not to be found")
+	}
+}
+
+func TestPoller_RefreshAll(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a"})
+	s.SetNode(models.Node{Name: "worker-1", Namespace: "ns-a"})
+	p := newRefreshPoller(s)
+
+	if _, err := p.RefreshNode("ns-a", "worker-0", nil); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	runQueued(t, p)
+
+	// worker-0 was just refreshed, so only worker-1 is polled
+	refresh, err := p.RefreshAll(nil)
+	if err != nil {
+		t.Fatalf("refresh all: %v", err)
+	}
+	states := make(map[string]models.RefreshState)
+	for _, node := range refresh.Nodes {
+		states[node.Name] = node.State
+	}
+	if states["worker-0"] != models.RefreshSkipped || states["worker-1"] != models.RefreshQueued {
+		t.Fatalf("unexpected node states: %+v", refresh.Nodes)
+	}
+	if ran := runQueued(t, p); ran != 1 {
+		t.Fatalf("expected one poll, got %d", ran)
+	}
+	// Nothing was refreshed, as worker-0 was skipped and worker-1 unreachable
+	refresh, _ = p.Refresh(refresh.ID)
+	if refresh.State != models.RefreshFailed || refresh.FinishedAt == nil {
+		t.Errorf("expected refresh to have failed, got %+v", refresh)
+	}
+}
+
+func TestPoller_RefreshWorkersBounded(t *testing.T) {
+	s := store.New()
+	nodes := 2 * refreshWorkers
+	for i := range nodes {
+		s.SetNode(models.Node{Name: fmt.Sprintf("worker-%d", i), Namespace: "ns-a"})
+	}
+	p := newRefreshPoller(s)
+
+	// Hold every refresh in its host lookup until released
+	var inFlight atomic.Int32
+	release := make(chan struct{})
+	lookup := p.getHost
+	p.getHost = func(ctx context.Context, namespace, name string) (*discovery.DiscoveredHost, error) {
+		inFlight.Add(1)
+		<-release
+		return lookup(ctx, namespace, name)
+	}
+
+	refresh, err := p.RefreshAll(nil)
+	if err != nil {
+		t.Fatalf("refresh all: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopCh := make(chan struct{})
+	for range refreshWorkers {
+		go p.runRefreshes(ctx, stopCh)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for inFlight.Load() < refreshWorkers {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d refreshes to start, got %d", refreshWorkers, inFlight.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// Every worker is busy, so the other nodes are still queued
+	if queued := len(p.refreshQueue); queued != nodes-refreshWorkers {
+		t.Fatalf("expected %d queued refreshes, got %d", nodes-refreshWorkers, queued)
+	}
+
+	close(release)
+	for {
+		current, _ := p.Refresh(refresh.ID)
+		if current.FinishedAt != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("refresh did not finish: %+v", current)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := inFlight.Load(); got != int32(nodes) {
+		t.Errorf("expected %d refreshes, got %d", nodes, got)
+	}
+}
+
+func TestPoller_UpdateRefreshState(t *testing.T) {
+	p := newRefreshPoller(store.New())
+	tests := []struct {
+		nodes []models.RefreshState
+		want  models.RefreshState
+	}{
+		{nil, models.RefreshDone},
+		{[]models.RefreshState{models.RefreshDone, models.RefreshDone}, models.RefreshDone},
+		{[]models.RefreshState{models.RefreshDone, models.RefreshQueued}, models.RefreshQueued},
+		{[]models.RefreshState{models.RefreshQueued, models.RefreshRunning}, models.RefreshRunning},
+		{[]models.RefreshState{models.RefreshDone, models.RefreshFailed}, models.RefreshPartial},
+		{[]models.RefreshState{models.RefreshDone, models.RefreshSkipped}, models.RefreshPartial},
+		{[]models.RefreshState{models.RefreshFailed, models.RefreshSkipped}, models.RefreshFailed},
+	}
+	for _, tt := range tests {
+		refresh := &models.Refresh{}
+		for _, state := range tt.nodes {
+			refresh.Nodes = append(refresh.Nodes, models.RefreshNode{State: state})
+		}
+		p.updateRefreshState(refresh)
+		if refresh.State != tt.want {
+			t.Errorf("nodes %v: state = %s, want %s", tt.nodes, refresh.State, tt.want)
+		}
+		if finished := refresh.FinishedAt != nil; finished != (tt.want != models.RefreshQueued && tt.want != models.RefreshRunning) {
+			t.Errorf("nodes %v: finishedAt = %v", tt.nodes, refresh.FinishedAt)
+		}
+	}
+}
+
+func TestPollSubsystems_KeepsUnpolledData(t *testing.T) {
+	s := store.New()
+	p := New(nil, redfish.NewClient(), s, nil, nil, 30*time.Minute)
+
+	collected := time.Now().Add(-time.Hour)
+	report := models.NewPollReport(nil, collected)
+	for _, sub := range (redfish.CollectOptions{}).Subsystems() {
+		report.Record(string(sub), nil)
+	}
+	report.Finish(collected)
+	s.SetNode(models.Node{
+		Name:           "worker-0",
+		Namespace:      "ns-a",
+		Firmware:       []models.FirmwareComponent{{ID: "bios", Name: "BIOS", CurrentVersion: "1.2.3"}},
+		ThermalSummary: &models.ThermalSummary{InletTempC: 22},
+		PollReport:     report,
+	})
+
+	host := discovery.DiscoveredHost{
+		Name:      "worker-0",
+		Namespace: "ns-a",
+		BMC:       models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"},
+	}
+	p.pollSubsystems(context.Background(), host, []redfish.Subsystem{redfish.SubsystemThermal})
+
+	node, _ := s.GetNode("ns-a", "worker-0")
+	if len(node.Firmware) != 1 || node.ThermalSummary == nil {
+		t.Fatalf("expected firmware and thermal data to be kept, got %+v", node)
+	}
+	// Firmware was not polled, so it is neither stale nor failed
+	if _, ok := node.Stale["firmware"]; ok || !node.PollReport.Subsystems["firmware"].OK {
+		t.Errorf("expected firmware to keep its last outcome, got %+v", node.PollReport.Subsystems["firmware"])
+	}
+	if _, ok := node.Stale["thermal"]; !ok || node.PollReport.Subsystems["thermal"].OK {
+		t.Errorf("expected failed thermal data to be stale, got %+v", node.Stale)
+	}
+}
+
+func TestPoller_RefreshWaitsForRunningPoll(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a"})
+	p := newRefreshPoller(s)
+	ctx := context.Background()
+
+	// A periodic poll of the node is in progress
+	key := models.NodeKey("ns-a", "worker-0")
+	if !p.lockNode(ctx, key, true, true) {
+		t.Fatal("expected to lock an idle node")
+	}
+
+	first, err := p.RefreshNode("ns-a", "worker-0", []string{"thermal"})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		p.runRefresh(ctx, <-p.refreshQueue)
+		close(done)
+	}()
+
+	// The refresh stays queued, so a request made meanwhile is merged into it
+	time.Sleep(50 * time.Millisecond)
+	if refresh, _ := p.Refresh(first.ID); refresh.State != models.RefreshQueued {
+		t.Fatalf("expected the refresh to wait for the running poll, got %+v", refresh)
+	}
+	second, err := p.RefreshNode("ns-a", "worker-0", []string{"power"})
+	if err != nil {
+		t.Fatalf("merged refresh: %v", err)
+	}
+
+	p.unlockNode(key)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("refresh did not run after the poll finished")
+	}
+	if ran := runQueued(t, p); ran != 0 {
+		t.Errorf("expected the second request to be merged, got %d more polls", ran)
+	}
+	for _, id := range []string{first.ID, second.ID} {
+		if refresh, _ := p.Refresh(id); refresh.State != models.RefreshFailed {
+			t.Errorf("expected refresh %s to have run and failed, got %+v", id, refresh)
+		}
+	}
+	node, _ := s.GetNode("ns-a", "worker-0")
+	if len(node.PollReport.Subsystems) != 2 {
+		t.Errorf("expected thermal and power in one poll, got %+v", node.PollReport.Subsystems)
+	}
+}
+
+func TestPoller_LockNode(t *testing.T) {
+	p := New(nil, redfish.NewClient(), store.New(), nil, nil, 30*time.Minute)
+	ctx := context.Background()
+
+	// A periodic poll skips a node already being polled in full
+	p.lockNode(ctx, "ns-a/worker-0", true, false)
+	if p.lockNode(ctx, "ns-a/worker-0", true, true) {
+		t.Fatal("expected a node polled in full to be skipped")
+	}
+	p.unlockNode("ns-a/worker-0")
+
+	// but waits for a partial poll to finish
+	p.lockNode(ctx, "ns-a/worker-0", false, false)
+	locked := make(chan bool)
+	go func() { locked <- p.lockNode(ctx, "ns-a/worker-0", true, true) }()
+	select {
+	case <-locked:
+		t.Fatal("expected to wait for the running poll")
+	case <-time.After(50 * time.Millisecond):
+	}
+	p.unlockNode("ns-a/worker-0")
+	if !<-locked {
+		t.Error("expected to lock the node once the poll finished")
+	}
+	p.unlockNode("ns-a/worker-0")
+
+	// Waiting ends with the context
+	p.lockNode(ctx, "ns-a/worker-0", false, false)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if p.lockNode(cancelled, "ns-a/worker-0", false, false) {
+		t.Error("expected a cancelled wait not to lock the node")
+	}
+}
+
+func TestPoller_RefreshKeepsKubeNode(t *testing.T) {
+	const ns = "openshift-machine-api"
+	machineGVR := schema.GroupVersionResource{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machines"}
+	bmhGVR := schema.GroupVersionResource{Group: "metal3.io", Version: "v1alpha1", Resource: "baremetalhosts"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{bmhGVR: "BareMetalHostList", machineGVR: "MachineList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "metal3.io/v1alpha1",
+			"kind":       "BareMetalHost",
+			"metadata":   map[string]interface{}{"name": "worker-0", "namespace": ns},
+			"spec": map[string]interface{}{
+				"bmc":         map[string]interface{}{"address": "redfish://127.0.0.1:1/redfish/v1/Systems/1", "credentialsName": "bmc"},
+				"consumerRef": map[string]interface{}{"kind": "Machine", "name": "ocp-worker-0", "namespace": ns},
+			},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "machine.openshift.io/v1beta1",
+			"kind":       "Machine",
+			"metadata":   map[string]interface{}{"name": "ocp-worker-0", "namespace": ns},
+			"status":     map[string]interface{}{"nodeRef": map[string]interface{}{"kind": "Node", "name": "worker-0.example.com"}},
+		}},
+	)
+	kubeClient := fake.NewClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bmc", Namespace: ns}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "worker-0.example.com",
+			Labels: map[string]string{"node-role.kubernetes.io/worker": ""},
+		}},
+	)
+
+	s := store.New()
+	s.SetNode(models.Node{
+		Name:      "worker-0",
+		Namespace: ns,
+		KubeNode:  &models.KubeNode{Name: "worker-0.example.com", Roles: []string{"worker"}},
+	})
+	p := New(discovery.NewDiscoverer(dynamicClient, kubeClient, ns, false), redfish.NewClient(), s, nil, nil, 30*time.Minute)
+
+	if _, err := p.RefreshNode(ns, "worker-0", []string{"thermal"}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if ran := runQueued(t, p); ran != 1 {
+		t.Fatalf("expected one poll, got %d", ran)
+	}
+
+	// The refreshed node is still correlated with its Kubernetes Node
+	node, _ := s.GetNode(ns, "worker-0")
+	if node.PollReport == nil || node.KubeNode == nil || node.KubeNode.Name != "worker-0.example.com" || !node.HasRole("worker") {
+		t.Errorf("expected the Kubernetes Node to survive the refresh, got %+v", node.KubeNode)
+	}
+}
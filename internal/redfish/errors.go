@@ -0,0 +1,54 @@
+package redfish
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/stmcginnis/gofish/common"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// ClassifyError sorts an error from a BMC session into the kinds reported on
+// nodes. Errors that are not a network, TLS or authentication failure are
+// taken to be Redfish protocol errors.
+func ClassifyError(err error) models.ErrorKind {
+	var (
+		certErr      *CertificateError
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		redfishErr   *common.Error
+		netErr       net.Error
+		dnsErr       *net.DNSError
+		opErr        *net.OpError
+	)
+
+	switch {
+	case errors.As(err, &certErr), errors.As(err, &verifyErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return models.ErrorTLS
+	case errors.As(err, &redfishErr) &&
+		(redfishErr.HTTPReturnedStatusCode == http.StatusUnauthorized || redfishErr.HTTPReturnedStatusCode == http.StatusForbidden):
+		return models.ErrorAuth
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return models.ErrorTimeout
+	case errors.As(err, &dnsErr), errors.As(err, &opErr):
+		return models.ErrorUnreachable
+	default:
+		return models.ErrorProtocol
+	}
+}
+
+// isNotFound reports whether the BMC answered that a resource does not exist
+func isNotFound(err error) bool {
+	var redfishErr *common.Error
+	return errors.As(err, &redfishErr) && redfishErr.HTTPReturnedStatusCode == http.StatusNotFound
+}
@@ -0,0 +1,65 @@
+package redfish
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want models.ErrorKind
+	}{
+		{"session rejected", common.ConstructError(401, []byte(`{"error": {"message": "Invalid credentials"}}`)), models.ErrorAuth},
+		{"forbidden", fmt.Errorf("failed to get update service: %w", common.ConstructError(403, nil)), models.ErrorAuth},
+		{"certificate rejected", &CertificateError{Certificate: &models.BMCCertificate{Error: "x509: certificate signed by unknown authority"}}, models.ErrorTLS},
+		{"unknown authority", fmt.Errorf("failed to connect to BMC: %w", x509.UnknownAuthorityError{}), models.ErrorTLS},
+		{"deadline", fmt.Errorf("failed to connect to BMC: %w", context.DeadlineExceeded), models.ErrorTimeout},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}, models.ErrorTimeout},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, models.ErrorUnreachable},
+		{"no such host", &net.DNSError{Err: "no such host", Name: "bmc.invalid", IsNotFound: true}, models.ErrorUnreachable},
+		{"server error", common.ConstructError(500, []byte("internal error")), models.ErrorProtocol},
+		{"bad response", errors.New("invalid character '<' looking for beginning of value"), models.ErrorProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyError_Connect(t *testing.T) {
+	client := NewClient()
+	_, err := client.Connect(context.Background(), models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"}, "root", "calvin")
+	if got := ClassifyError(err); got != models.ErrorUnreachable {
+		t.Errorf("expected unreachable for a closed port, got %s (%v)", got, err)
+	}
+
+	bmc := newMockBMC(t, nil)
+	untrusted := bmc.endpoint()
+	untrusted.CABundle = ""
+	_, err = client.Connect(context.Background(), untrusted, "root", "calvin")
+	if got := ClassifyError(err); got != models.ErrorTLS {
+		t.Errorf("expected tls for an untrusted certificate, got %s (%v)", got, err)
+	}
+}
@@ -0,0 +1,235 @@
+package redfish
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stmcginnis/gofish/redfish"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// maxLogPages bounds how many pages of one log a poll reads
+const maxLogPages = 100
+
+// logEntry is a log entry with its parsed creation time
+type logEntry struct {
+	*redfish.LogEntry
+	created time.Time // zero if the BMC's timestamp could not be parsed
+}
+
+// Events fetches entries from the vendor's event logs under the manager and the
+// system, such as the SEL and, on Dell, the Lifecycle Controller log, and
+// resolves their MessageIds through the message registries. marks holds each
+// log's high-water mark from earlier polls, keyed as in eventMarkKey: entries
+// at or below it are skipped. The remaining entries are returned oldest first,
+// at most limit from each log, and the returned marks are moved forward only
+// past the entries returned, so entries beyond the limit are read by the next
+// poll rather than lost.
+func (s *Session) Events(limit int, marks map[string]models.EventMark) ([]models.HealthEvent, map[string]models.EventMark, error) {
+	logServices, err := s.eventLogServices()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make([]models.HealthEvent, 0)
+	var sources []*redfish.LogEntry
+	newMarks := maps.Clone(marks)
+	if newMarks == nil {
+		newMarks = make(map[string]models.EventMark)
+	}
+
+	for _, ls := range logServices {
+		markKey := eventMarkKey(s.identity, ls.ID)
+		mark := marks[markKey]
+
+		entries, err := s.logEntries(ls, mark)
+		if err != nil {
+			continue
+		}
+
+		read := 0
+		for _, entry := range entries {
+			parsed := !entry.created.IsZero()
+			if parsed && mark.Covers(entry.created, entry.ID) {
+				continue
+			}
+			// Entries without a time cannot be marked, so they do not count
+			// against the limit; the store drops those it already holds
+			timestamp := entry.created
+			if parsed {
+				if limit > 0 && read >= limit {
+					continue
+				}
+				read++
+				newMarks[markKey] = newMarks[markKey].Advance(timestamp, entry.ID)
+			} else {
+				timestamp = time.Now()
+			}
+
+			events = append(events, models.HealthEvent{
+				ID:         entry.ID,
+				Timestamp:  timestamp,
+				Severity:   parseSeverity(string(entry.Severity)),
+				Message:    entry.Message,
+				MessageID:  entry.MessageID,
+				Category:   s.Vendor().EventCategory(entry.LogEntry),
+				Component:  eventComponent(entry.LogEntry),
+				BMC:        s.identity,
+				LogService: ls.ID,
+				Created:    entry.Created,
+			})
+			sources = append(sources, entry.LogEntry)
+		}
+	}
+
+	s.resolveMessages(events, sources)
+	return events, newMarks, nil
+}
+
+// eventLogServices returns the vendor's event logs, from the manager first and
+// then the system. A log the system lists under the same ID as a manager log is
+// taken to be the same log. Failing to read either is only an error if no event
+// log was found on the other.
+func (s *Session) eventLogServices() ([]*redfish.LogService, error) {
+	var found []*redfish.LogService
+	var errs []error
+
+	if manager, err := s.Manager(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get manager: %w", err))
+	} else if logs, err := manager.LogServices(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get manager log services: %w", err))
+	} else {
+		found = append(found, logs...)
+	}
+
+	if sys, err := s.System(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get system: %w", err))
+	} else if logs, err := sys.LogServices(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get system log services: %w", err))
+	} else {
+		found = append(found, logs...)
+	}
+
+	wanted := s.Vendor().EventLogServices()
+	logServices := make([]*redfish.LogService, 0, len(wanted))
+	seen := make(map[string]bool)
+	for _, ls := range found {
+		if slices.Contains(wanted, ls.ID) && !seen[ls.ID] {
+			seen[ls.ID] = true
+			logServices = append(logServices, ls)
+		}
+	}
+	if len(logServices) == 0 && len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return logServices, nil
+}
+
+// logEntries reads a log's entries oldest first, following Members@odata.nextLink,
+// with entries whose time could not be parsed last. Most BMCs list entries
+// newest first; for those, paging stops at the first entry at or below the
+// mark. Other logs are read to the end, up to maxLogPages.
+func (s *Session) logEntries(ls *redfish.LogService, mark models.EventMark) ([]logEntry, error) {
+	var entries []logEntry
+	// The log is taken to be newest first once two entries were seen in that
+	// order and none out of it
+	newestFirst, compared := true, false
+	var previous time.Time
+
+	uri := strings.TrimSuffix(ls.ODataID, "/") + "/Entries"
+	for page := 0; uri != "" && page < maxLogPages; page++ {
+		members, next, err := s.logEntryPage(uri)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			break
+		}
+
+		reachedMark := false
+		for _, member := range members {
+			created := parseTimestamp(member.Created)
+			if created.IsZero() {
+				created = parseTimestamp(member.EventTimestamp)
+			}
+			if !created.IsZero() {
+				if !previous.IsZero() {
+					compared = true
+					newestFirst = newestFirst && !created.After(previous)
+				}
+				previous = created
+			}
+
+			if !created.IsZero() && mark.Covers(created, member.ID) {
+				reachedMark = true
+			}
+			entries = append(entries, logEntry{LogEntry: member, created: created})
+		}
+
+		if compared && newestFirst && reachedMark {
+			break
+		}
+		uri = next
+	}
+
+	// Order oldest first for the limit to take the entries next after the mark
+	slices.SortStableFunc(entries, func(a, b logEntry) int {
+		switch {
+		case a.created.IsZero() && b.created.IsZero():
+			return 0
+		case a.created.IsZero():
+			return 1
+		case b.created.IsZero():
+			return -1
+		}
+		return a.created.Compare(b.created)
+	})
+	return entries, nil
+}
+
+// logEntryPage reads one page of a LogEntry collection and the link to the next.
+// Members are normally expanded in the collection; those that are only a link
+// are fetched one by one.
+func (s *Session) logEntryPage(uri string) ([]*redfish.LogEntry, string, error) {
+	resp, err := s.api.Get(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var page struct {
+		Members  []json.RawMessage
+		NextLink string `json:"Members@odata.nextLink"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, "", fmt.Errorf("failed to decode log entries: %w", err)
+	}
+
+	entries := make([]*redfish.LogEntry, 0, len(page.Members))
+	for _, raw := range page.Members {
+		var entry redfish.LogEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			continue
+		}
+		if entry.ID == "" && entry.Created == "" && entry.ODataID != "" {
+			fetched, err := redfish.GetLogEntry(s.api, entry.ODataID)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, fetched)
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, page.NextLink, nil
+}
+
+// eventMarkKey is the key of a BMC log's high-water mark
+func eventMarkKey(bmc, logService string) string {
+	return bmc + "/" + logService
+}
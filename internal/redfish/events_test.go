@@ -0,0 +1,215 @@
+package redfish
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func lcEntry(id, created, messageID, category string) string {
+	return `{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Lclog/Entries/` + id + `", "Id": "` + id +
+		`", "Created": "` + created + `", "Severity": "OK", "Message": "entry ` + id + `", "MessageId": "` + messageID +
+		`", "Oem": {"Dell": {"Category": "` + category + `"}}}`
+}
+
+func TestSession_Events_LifecycleLogPages(t *testing.T) {
+	const (
+		manager = "/redfish/v1/Managers/iDRAC.Embedded.1"
+		lclog   = manager + "/LogServices/Lclog/Entries"
+	)
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/Systems":  `{"Members": []}`,
+		"/redfish/v1/Managers": `{"Members": [{"@odata.id": "` + manager + `"}]}`,
+		manager: `{
+			"@odata.id": "` + manager + `",
+			"Id": "iDRAC.Embedded.1",
+			"LogServices": {"@odata.id": "` + manager + `/LogServices"}
+		}`,
+		manager + "/LogServices": `{"Members": [
+			{"@odata.id": "` + manager + `/LogServices/Lclog"},
+			{"@odata.id": "` + manager + `/LogServices/FaultList"}
+		]}`,
+		manager + "/LogServices/Lclog":     `{"@odata.id": "` + manager + `/LogServices/Lclog", "Id": "Lclog"}`,
+		manager + "/LogServices/FaultList": `{"@odata.id": "` + manager + `/LogServices/FaultList", "Id": "FaultList"}`,
+		// Expanded members, newest first, as iDRAC returns them
+		lclog: `{"Members": [` +
+			lcEntry("4", "2024-03-01T12:00:00-06:00", "SUP0516", "Updates") + `,` +
+			lcEntry("3", "2024-03-01T17:30:00+0000", "SYS1003", "Audit") +
+			`], "Members@odata.nextLink": "` + lclog + `?$skip=2"}`,
+		lclog + "?$skip=2": `{"Members": [` +
+			lcEntry("2", "2024-03-01T10:00:00+05:30", "RAC0701", "Configuration") + `,` +
+			lcEntry("1", "2024-03-01T00:00:00", "PR7", "Configuration") +
+			`], "Members@odata.nextLink": "` + lclog + `?$skip=4"}`,
+		lclog + "?$skip=4": `{"Members": [` + lcEntry("0", "2024-02-01T00:00:00Z", "PR7", "Configuration") + `]}`,
+	})
+
+	s, err := NewClient().Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+
+	// The first read takes the oldest entries, so every page is read
+	events, marks, err := s.Events(3, nil)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if ids := eventIDs(events); !slices.Equal(ids, []string{"0", "1", "2"}) {
+		t.Fatalf("expected the oldest 3 entries, got %v", ids)
+	}
+	if want := time.Date(2024, 3, 1, 4, 30, 0, 0, time.UTC); !events[2].Timestamp.Equal(want) {
+		t.Errorf("offset timestamp = %v, want %v", events[2].Timestamp, want)
+	}
+
+	// The entries beyond the limit are read by the next poll, which stops at
+	// the mark on the second page
+	events, marks, err = s.Events(3, marks)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if ids := eventIDs(events); !slices.Equal(ids, []string{"3", "4"}) {
+		t.Fatalf("expected the remaining entries, got %v", ids)
+	}
+	newest := events[1]
+	if newest.LogService != "Lclog" || newest.MessageID != "SUP0516" || newest.Category != "Updates" {
+		t.Errorf("unexpected newest event: %+v", newest)
+	}
+	if want := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC); !newest.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", newest.Timestamp, want)
+	}
+
+	// Once caught up, a poll stops at the mark on the first page
+	events, _, err = s.Events(3, marks)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected no new events, got %+v (%v)", events, err)
+	}
+	bmc.mu.Lock()
+	if bmc.gets[lclog+"?$skip=2"] != 2 || bmc.gets[lclog+"?$skip=4"] != 1 {
+		t.Errorf("expected the second page read twice and the third once, got %d and %d", bmc.gets[lclog+"?$skip=2"], bmc.gets[lclog+"?$skip=4"])
+	}
+	bmc.mu.Unlock()
+}
+
+func TestSession_Events_BacklogBeyondLimit(t *testing.T) {
+	const (
+		manager = "/redfish/v1/Managers/iDRAC.Embedded.1"
+		sel     = manager + "/LogServices/Sel/Entries"
+	)
+	// Seven entries, newest first, two to a page
+	resources := map[string]string{
+		"/redfish/v1/Systems":  `{"Members": []}`,
+		"/redfish/v1/Managers": `{"Members": [{"@odata.id": "` + manager + `"}]}`,
+		manager: `{
+			"@odata.id": "` + manager + `",
+			"Id": "iDRAC.Embedded.1",
+			"LogServices": {"@odata.id": "` + manager + `/LogServices"}
+		}`,
+		manager + "/LogServices":     `{"Members": [{"@odata.id": "` + manager + `/LogServices/Sel"}]}`,
+		manager + "/LogServices/Sel": `{"@odata.id": "` + manager + `/LogServices/Sel", "Id": "Sel"}`,
+	}
+	for page := 0; page < 4; page++ {
+		uri := sel
+		if page > 0 {
+			uri += "?page=" + strconv.Itoa(page)
+		}
+		var members []string
+		for id := 7 - 2*page; id > 5-2*page && id > 0; id-- {
+			created := time.Date(2024, 1, 1, id, 0, 0, 0, time.UTC).Format(time.RFC3339)
+			members = append(members, selEntry(strconv.Itoa(id), created, "OK"))
+		}
+		next := ""
+		if page < 3 {
+			next = `, "Members@odata.nextLink": "` + sel + "?page=" + strconv.Itoa(page+1) + `"`
+		}
+		resources[uri] = `{"Members": [` + strings.Join(members, ",") + `]` + next + `}`
+	}
+	bmc := newMockBMC(t, resources)
+
+	s, err := NewClient().Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+
+	// Entry 1 was read by an earlier poll
+	marks := map[string]models.EventMark{
+		eventMarkKey(s.identity, "Sel"): models.EventMark{}.Advance(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), "1"),
+	}
+	var ids []string
+	for poll := 0; poll < 5; poll++ {
+		events, newMarks, err := s.Events(2, marks)
+		if err != nil {
+			t.Fatalf("events: %v", err)
+		}
+		if len(events) > 2 {
+			t.Fatalf("poll %d returned %d events, over the limit", poll, len(events))
+		}
+		ids = append(ids, eventIDs(events)...)
+		marks = newMarks
+	}
+	if want := []string{"2", "3", "4", "5", "6", "7"}; !slices.Equal(ids, want) {
+		t.Errorf("expected every new entry once in order, got %v, want %v", ids, want)
+	}
+}
+
+func eventIDs(events []models.HealthEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestSession_Events_SystemLogServices(t *testing.T) {
+	const (
+		system = "/redfish/v1/Systems/1"
+		iml    = system + "/LogServices/IML/Entries"
+	)
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/": `{
+			"@odata.id": "/redfish/v1/",
+			"Vendor": "HPE",
+			"Systems": {"@odata.id": "/redfish/v1/Systems"},
+			"Managers": {"@odata.id": "/redfish/v1/Managers"},
+			"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}
+		}`,
+		"/redfish/v1/Systems": `{"Members": [{"@odata.id": "` + system + `"}]}`,
+		system: `{
+			"@odata.id": "` + system + `",
+			"Id": "1",
+			"Manufacturer": "HPE",
+			"LogServices": {"@odata.id": "` + system + `/LogServices"}
+		}`,
+		system + "/LogServices":     `{"Members": [{"@odata.id": "` + system + `/LogServices/IML"}]}`,
+		system + "/LogServices/IML": `{"@odata.id": "` + system + `/LogServices/IML", "Id": "IML"}`,
+		// Oldest first and only linked, so every page is read and each entry fetched
+		iml:                    `{"Members": [{"@odata.id": "` + iml + `/1"}], "Members@odata.nextLink": "` + iml + `?page=2"}`,
+		iml + "?page=2":        `{"Members": [{"@odata.id": "` + iml + `/2"}]}`,
+		iml + "/1":             `{"@odata.id": "` + iml + `/1", "Id": "1", "Created": "2024-01-01T00:00:00Z", "Severity": "Warning", "Message": "Fan degraded"}`,
+		iml + "/2":             `{"@odata.id": "` + iml + `/2", "Id": "2", "Created": "2024-01-02T00:00:00Z", "Severity": "Critical", "Message": "Fan failed"}`,
+		"/redfish/v1/Managers": `{"Members": []}`,
+	})
+
+	s, err := NewClient().Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+
+	events, marks, err := s.Events(1, nil)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "1" || events[0].LogService != "IML" || events[0].Severity != models.HealthWarning {
+		t.Errorf("expected the oldest IML entry, got %+v", events)
+	}
+	events, _, err = s.Events(1, marks)
+	if err != nil || len(events) != 1 || events[0].ID != "2" || events[0].Severity != models.HealthCritical {
+		t.Errorf("expected the next IML entry, got %+v (%v)", events, err)
+	}
+}
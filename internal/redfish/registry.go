@@ -0,0 +1,285 @@
+package redfish
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stmcginnis/gofish/redfish"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// registryTTL is how long the registries a BMC offers are remembered before
+// its Registries collection is read again, e.g. after a firmware update
+const registryTTL = 24 * time.Hour
+
+// registryRetry is how long a registry a BMC failed to serve is left alone
+// before it is asked for again; the embedded copy is used meanwhile
+const registryRetry = time.Hour
+
+// embeddedRegistryFiles are fallback copies of the registries most log entries
+// use, for BMCs that do not serve their own. They hold a subset of each
+// registry's messages: the hardware faults and updates worth explaining.
+//
+//go:embed registries/*.json
+var embeddedRegistryFiles embed.FS
+
+// embeddedRegistries returns the embedded registries by prefix
+var embeddedRegistries = sync.OnceValue(func() map[string]*redfish.MessageRegistry {
+	registries := make(map[string]*redfish.MessageRegistry)
+	files, _ := fs.Glob(embeddedRegistryFiles, "registries/*.json")
+	for _, file := range files {
+		data, err := embeddedRegistryFiles.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var registry redfish.MessageRegistry
+		if err := json.Unmarshal(data, &registry); err != nil {
+			log.Printf("Invalid embedded message registry %s: %v", path.Base(file), err)
+			continue
+		}
+		registries[registry.RegistryPrefix] = &registry
+	}
+	return registries
+})
+
+// registryCache holds message registries read from BMCs. It is shared by all
+// sessions of a Client, so a registry is downloaded once per version rather
+// than on every poll.
+type registryCache struct {
+	mu     sync.Mutex
+	byID   map[string]*redfish.MessageRegistry // by registry ID, e.g. IDRAC.2.8
+	bmcs   map[string]bmcRegistries            // by BMC identity
+	failed map[registryFailure]time.Time       // when each failed fetch happened
+}
+
+// registryFailure identifies a registry a BMC failed to serve
+type registryFailure struct {
+	bmc string
+	id  string
+}
+
+// bmcRegistries are the registries a BMC offers, by prefix
+type bmcRegistries struct {
+	fetched time.Time
+	refs    map[string]registryRef
+}
+
+// registryRef locates a registry on a BMC
+type registryRef struct {
+	id  string
+	uri string
+}
+
+func newRegistryCache() *registryCache {
+	return &registryCache{
+		byID:   make(map[string]*redfish.MessageRegistry),
+		bmcs:   make(map[string]bmcRegistries),
+		failed: make(map[registryFailure]time.Time),
+	}
+}
+
+// messageRegistries returns registries by prefix for resolving the given
+// prefixes: the BMC's own where it serves them, else the embedded copies. A
+// registry the BMC failed to serve is not asked for again within registryRetry.
+func (s *Session) messageRegistries(prefixes []string) map[string]*redfish.MessageRegistry {
+	registries := maps.Clone(embeddedRegistries())
+	if s.registries == nil || len(prefixes) == 0 {
+		return registries
+	}
+
+	refs := s.registryRefs()
+	for _, prefix := range prefixes {
+		ref, ok := refs[prefix]
+		if !ok {
+			continue
+		}
+
+		failure := registryFailure{bmc: s.identity, id: ref.id}
+		s.registries.mu.Lock()
+		registry, cached := s.registries.byID[ref.id]
+		failedAt, failed := s.registries.failed[failure]
+		s.registries.mu.Unlock()
+		if !cached {
+			if failed && time.Since(failedAt) < registryRetry {
+				continue
+			}
+			fetched, err := redfish.GetMessageRegistry(s.api, ref.uri)
+			if err != nil {
+				log.Printf("Failed to read message registry %s from %s, retrying in %s: %v", ref.id, s.identity, registryRetry, err)
+				s.registries.mu.Lock()
+				s.registries.failed[failure] = time.Now()
+				s.registries.mu.Unlock()
+				continue
+			}
+			registry = fetched
+			s.registries.mu.Lock()
+			s.registries.byID[ref.id] = registry
+			delete(s.registries.failed, failure)
+			s.registries.mu.Unlock()
+		}
+		registries[prefix] = registry
+	}
+	return registries
+}
+
+// registryRefs returns the registries the BMC offers by prefix, reading its
+// Registries collection if not read within registryTTL
+func (s *Session) registryRefs() map[string]registryRef {
+	s.registries.mu.Lock()
+	known, ok := s.registries.bmcs[s.identity]
+	s.registries.mu.Unlock()
+	if ok && time.Since(known.fetched) < registryTTL {
+		return known.refs
+	}
+
+	refs := make(map[string]registryRef)
+	files, err := s.service.Registries()
+	if err != nil {
+		log.Printf("Failed to list message registries on %s: %v", s.identity, err)
+	}
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(file.Registry, ".")
+		if uri := registryURI(file); prefix != "" && uri != "" {
+			refs[prefix] = registryRef{id: file.Registry, uri: uri}
+		}
+	}
+
+	s.registries.mu.Lock()
+	s.registries.bmcs[s.identity] = bmcRegistries{fetched: time.Now(), refs: refs}
+	s.registries.mu.Unlock()
+	return refs
+}
+
+// registryURI returns where the BMC serves a registry file, preferring English
+func registryURI(file *redfish.MessageRegistryFile) string {
+	uri := ""
+	for _, loc := range file.Location {
+		if loc.URI == "" {
+			continue
+		}
+		if loc.Language == "en" {
+			return loc.URI
+		}
+		if uri == "" {
+			uri = loc.URI
+		}
+	}
+	return uri
+}
+
+// resolveMessages completes events from the message registries: the message
+// text where the BMC logged none, the resolution, and the registry's severity
+// where it is worse than the entry's, as BMCs log some faults as OK. entries
+// are the log entries the events were read from.
+func (s *Session) resolveMessages(events []models.HealthEvent, entries []*redfish.LogEntry) {
+	defaultPrefix := s.Vendor().MessageRegistryPrefix()
+	var prefixes []string
+	for _, entry := range entries {
+		if entry.MessageID == "" {
+			continue
+		}
+		if prefix, _ := messageKey(entry.MessageID, defaultPrefix); prefix != "" && !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	registries := s.messageRegistries(prefixes)
+
+	for i := range events {
+		e, entry := &events[i], entries[i]
+		if msg, ok := lookupMessage(registries, entry.MessageID, defaultPrefix); ok {
+			if e.Message == "" || e.Message == entry.MessageID {
+				e.Message = formatMessage(msg.Message, entry.MessageArgs)
+			}
+			if msg.Resolution != "None." {
+				e.Resolution = formatMessage(msg.Resolution, entry.MessageArgs)
+			}
+			severity := parseSeverity(msg.MessageSeverity)
+			if severity == "" {
+				severity = parseSeverity(msg.Severity)
+			}
+			if severity != "" {
+				e.Severity = aggregateHealth([]models.HealthStatus{e.Severity, severity})
+			}
+		}
+		if e.Severity == "" {
+			e.Severity = models.HealthOK
+		}
+	}
+}
+
+// messageKey splits a MessageId into its registry prefix and message key. IDs
+// are normally Prefix.Major.Minor.Key, but iDRAC logs bare keys such as
+// PSU0003, which belong to the vendor's registry.
+func messageKey(messageID, defaultPrefix string) (prefix, key string) {
+	parts := strings.Split(messageID, ".")
+	if len(parts) == 1 {
+		return defaultPrefix, messageID
+	}
+	return parts[0], parts[len(parts)-1]
+}
+
+// lookupMessage finds a MessageId in the registries. A bare key without a
+// vendor registry is looked up in each registry.
+func lookupMessage(registries map[string]*redfish.MessageRegistry, messageID, defaultPrefix string) (redfish.MessageRegistryMessage, bool) {
+	if messageID == "" {
+		return redfish.MessageRegistryMessage{}, false
+	}
+	prefix, key := messageKey(messageID, defaultPrefix)
+	if prefix != "" {
+		registry, ok := registries[prefix]
+		if !ok {
+			return redfish.MessageRegistryMessage{}, false
+		}
+		msg, ok := registry.Messages[key]
+		return msg, ok
+	}
+
+	for _, p := range slices.Sorted(maps.Keys(registries)) {
+		if msg, ok := registries[p].Messages[key]; ok {
+			return msg, true
+		}
+	}
+	return redfish.MessageRegistryMessage{}, false
+}
+
+// formatMessage substitutes the %1, %2, ... placeholders of a registry message
+func formatMessage(template string, args []string) string {
+	// Highest first, so %1 does not match the start of %10
+	for i := len(args); i >= 1; i-- {
+		template = strings.ReplaceAll(template, "%"+strconv.Itoa(i), args[i-1])
+	}
+	return template
+}
+
+// parseSeverity maps a Redfish severity onto a health status, or "" if unknown
+func parseSeverity(severity string) models.HealthStatus {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return models.HealthCritical
+	case "warning":
+		return models.HealthWarning
+	case "ok", "informational":
+		return models.HealthOK
+	default:
+		return ""
+	}
+}
+
+// eventComponent names the part a log entry is about: the resource its
+// OriginOfCondition links to, else its sensor type
+func eventComponent(entry *redfish.LogEntry) string {
+	if origin := strings.TrimSuffix(entry.OriginOfCondition, "/"); origin != "" {
+		return path.Base(origin)
+	}
+	return string(entry.SensorType)
+}
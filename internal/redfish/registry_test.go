@@ -0,0 +1,273 @@
+package redfish
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func TestMessageKey(t *testing.T) {
+	tests := []struct {
+		messageID, defaultPrefix string
+		wantPrefix, wantKey      string
+	}{
+		{"Base.1.16.Success", "", "Base", "Success"},
+		{"IDRAC.2.8.PSU0003", "IDRAC", "IDRAC", "PSU0003"},
+		{"PSU0003", "IDRAC", "IDRAC", "PSU0003"},
+		{"ResourceStatusChangedCritical", "", "", "ResourceStatusChangedCritical"},
+	}
+	for _, tt := range tests {
+		prefix, key := messageKey(tt.messageID, tt.defaultPrefix)
+		if prefix != tt.wantPrefix || key != tt.wantKey {
+			t.Errorf("messageKey(%q, %q) = %q, %q, want %q, %q", tt.messageID, tt.defaultPrefix, prefix, key, tt.wantPrefix, tt.wantKey)
+		}
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	args := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	if got := formatMessage("%1 then %10 then %2", args); got != "a then j then b" {
+		t.Errorf("got %q", got)
+	}
+	if got := formatMessage("Fan %1 failed.", nil); got != "Fan %1 failed." {
+		t.Errorf("missing args should leave placeholders, got %q", got)
+	}
+}
+
+func TestLookupMessage_Embedded(t *testing.T) {
+	registries := embeddedRegistries()
+	tests := []struct {
+		messageID, defaultPrefix string
+		wantSeverity             string
+		wantFound                bool
+	}{
+		{"PSU0003", "IDRAC", "Critical", true},
+		{"IDRAC.2.8.FAN0001", "", "Warning", true},
+		{"ResourceEvent.1.3.ResourceStatusChangedCritical", "", "Critical", true},
+		{"ResourceErrorsDetected", "", "Warning", true},
+		{"PSU0003", "HPE", "", false},
+		{"IDRAC.2.8.XYZ9999", "", "", false},
+	}
+	for _, tt := range tests {
+		msg, ok := lookupMessage(registries, tt.messageID, tt.defaultPrefix)
+		if ok != tt.wantFound {
+			t.Errorf("lookupMessage(%q) found = %v, want %v", tt.messageID, ok, tt.wantFound)
+			continue
+		}
+		if severity := msg.Severity + msg.MessageSeverity; severity != tt.wantSeverity {
+			t.Errorf("lookupMessage(%q) severity = %q, want %q", tt.messageID, severity, tt.wantSeverity)
+		}
+	}
+}
+
+func TestSession_Events_ResolvesMessages(t *testing.T) {
+	const (
+		manager  = "/redfish/v1/Managers/iDRAC.Embedded.1"
+		sel      = manager + "/LogServices/Sel/Entries"
+		registry = "/redfish/v1/Registries/IDRAC.2.9/IDRAC.json"
+	)
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/":           strings.Replace(mockServiceRoot, `"Systems"`, `"Registries": {"@odata.id": "/redfish/v1/Registries"}, "Systems"`, 1),
+		"/redfish/v1/Registries": `{"Members": [{"@odata.id": "/redfish/v1/Registries/IDRAC.2.9"}]}`,
+		"/redfish/v1/Registries/IDRAC.2.9": `{
+			"@odata.id": "/redfish/v1/Registries/IDRAC.2.9",
+			"Id": "IDRAC.2.9",
+			"Registry": "IDRAC.2.9",
+			"Location": [{"Language": "en", "Uri": "` + registry + `"}]
+		}`,
+		// The BMC's own registry wins over the embedded copy
+		registry: `{
+			"Id": "IDRAC.2.9",
+			"RegistryPrefix": "IDRAC",
+			"RegistryVersion": "2.9",
+			"Messages": {
+				"PSU0003": {"Message": "Power supply %1 has lost input power.", "Severity": "Critical", "NumberOfArgs": 1, "Resolution": "Check the power cord of %1."}
+			}
+		}`,
+		"/redfish/v1/Systems":  `{"Members": []}`,
+		"/redfish/v1/Managers": `{"Members": [{"@odata.id": "` + manager + `"}]}`,
+		manager: `{
+			"@odata.id": "` + manager + `",
+			"Id": "iDRAC.Embedded.1",
+			"LogServices": {"@odata.id": "` + manager + `/LogServices"}
+		}`,
+		manager + "/LogServices":     `{"Members": [{"@odata.id": "` + manager + `/LogServices/Sel"}]}`,
+		manager + "/LogServices/Sel": `{"@odata.id": "` + manager + `/LogServices/Sel", "Id": "Sel"}`,
+		sel: `{"Members": [
+			{"@odata.id": "` + sel + `/2", "Id": "2", "Created": "2024-01-01T01:00:00Z", "MessageId": "PSU0003", "MessageArgs": ["PS1"],
+			 "Links": {"OriginOfCondition": {"@odata.id": "/redfish/v1/Chassis/System.Embedded.1/Power/PowerSupplies/PSU.Slot.1"}}},
+			{"@odata.id": "` + sel + `/1", "Id": "1", "Created": "2024-01-01T00:00:00Z", "Message": "The health of fan 1 changed.",
+			 "MessageId": "ResourceEvent.1.3.ResourceStatusChangedWarning", "MessageArgs": ["Fan1", "Warning"], "SensorType": "Fan"}
+		]}`,
+	})
+
+	client := NewClient()
+	s, err := client.Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	events, _, err := s.Events(0, nil)
+	s.Close()
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+
+	psu := events[1]
+	if psu.Message != "Power supply PS1 has lost input power." || psu.Resolution != "Check the power cord of PS1." {
+		t.Errorf("expected the message from the BMC's registry, got %q / %q", psu.Message, psu.Resolution)
+	}
+	if psu.Severity != models.HealthCritical || psu.Component != "PSU.Slot.1" || psu.MessageID != "PSU0003" {
+		t.Errorf("unexpected PSU event: %+v", psu)
+	}
+
+	// Not served by the BMC, so resolved from the embedded copy; the logged
+	// message is kept and "None." is not a resolution
+	fan := events[0]
+	if fan.Message != "The health of fan 1 changed." || fan.Severity != models.HealthWarning || fan.Resolution != "" || fan.Component != "Fan" {
+		t.Errorf("unexpected fan event: %+v", fan)
+	}
+
+	// Registries are cached by the client across sessions
+	s, err = client.Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+	if _, _, err := s.Events(0, nil); err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	bmc.mu.Lock()
+	defer bmc.mu.Unlock()
+	if bmc.gets[registry] != 1 || bmc.gets["/redfish/v1/Registries"] != 1 {
+		t.Errorf("expected the registry to be read once, got %d reads and %d listings", bmc.gets[registry], bmc.gets["/redfish/v1/Registries"])
+	}
+}
+
+func TestSession_Events_RegistryFailureBacksOff(t *testing.T) {
+	const (
+		manager  = "/redfish/v1/Managers/iDRAC.Embedded.1"
+		sel      = manager + "/LogServices/Sel/Entries"
+		registry = "/redfish/v1/Registries/IDRAC.2.9/IDRAC.json"
+	)
+	// The registry is listed but its file is not served
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/":           strings.Replace(mockServiceRoot, `"Systems"`, `"Registries": {"@odata.id": "/redfish/v1/Registries"}, "Systems"`, 1),
+		"/redfish/v1/Registries": `{"Members": [{"@odata.id": "/redfish/v1/Registries/IDRAC.2.9"}]}`,
+		"/redfish/v1/Registries/IDRAC.2.9": `{
+			"@odata.id": "/redfish/v1/Registries/IDRAC.2.9",
+			"Id": "IDRAC.2.9",
+			"Registry": "IDRAC.2.9",
+			"Location": [{"Language": "en", "Uri": "` + registry + `"}]
+		}`,
+		"/redfish/v1/Systems":  `{"Members": []}`,
+		"/redfish/v1/Managers": `{"Members": [{"@odata.id": "` + manager + `"}]}`,
+		manager: `{
+			"@odata.id": "` + manager + `",
+			"Id": "iDRAC.Embedded.1",
+			"LogServices": {"@odata.id": "` + manager + `/LogServices"}
+		}`,
+		manager + "/LogServices":     `{"Members": [{"@odata.id": "` + manager + `/LogServices/Sel"}]}`,
+		manager + "/LogServices/Sel": `{"@odata.id": "` + manager + `/LogServices/Sel", "Id": "Sel"}`,
+		sel: `{"Members": [
+			{"@odata.id": "` + sel + `/1", "Id": "1", "Created": "2024-01-01T00:00:00Z", "MessageId": "PSU0003", "MessageArgs": ["PS1"]}
+		]}`,
+	})
+	registryReads := func() int {
+		bmc.mu.Lock()
+		defer bmc.mu.Unlock()
+		return bmc.gets[registry]
+	}
+
+	client := NewClient()
+	poll := func() {
+		t.Helper()
+		s, err := client.Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		defer s.Close()
+		events, _, err := s.Events(0, nil)
+		if err != nil {
+			t.Fatalf("events: %v", err)
+		}
+		// Resolved from the embedded copy meanwhile
+		if len(events) != 1 || events[0].Severity != models.HealthCritical {
+			t.Errorf("expected the embedded registry to resolve the event, got %+v", events)
+		}
+	}
+
+	poll()
+	poll()
+	if reads := registryReads(); reads != 1 {
+		t.Fatalf("expected the failed registry to be read once, got %d", reads)
+	}
+
+	// Once registryRetry has passed, the registry is asked for again
+	client.registries.mu.Lock()
+	for failure := range client.registries.failed {
+		client.registries.failed[failure] = time.Now().Add(-registryRetry)
+	}
+	client.registries.mu.Unlock()
+	poll()
+	if reads := registryReads(); reads != 2 {
+		t.Errorf("expected the registry to be retried, got %d reads", reads)
+	}
+}
+
+func TestSession_Events_RegistrySeverity(t *testing.T) {
+	const (
+		manager = "/redfish/v1/Managers/iDRAC.Embedded.1"
+		sel     = manager + "/LogServices/Sel/Entries"
+	)
+	entry := func(id, severity, messageID string) string {
+		return `{"@odata.id": "` + sel + `/` + id + `", "Id": "` + id + `", "Created": "2024-01-01T0` + id + `:00:00Z",
+			"Severity": "` + severity + `", "Message": "entry ` + id + `", "MessageId": "` + messageID + `"}`
+	}
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/Systems":  `{"Members": []}`,
+		"/redfish/v1/Managers": `{"Members": [{"@odata.id": "` + manager + `"}]}`,
+		manager: `{
+			"@odata.id": "` + manager + `",
+			"Id": "iDRAC.Embedded.1",
+			"LogServices": {"@odata.id": "` + manager + `/LogServices"}
+		}`,
+		manager + "/LogServices":     `{"Members": [{"@odata.id": "` + manager + `/LogServices/Sel"}]}`,
+		manager + "/LogServices/Sel": `{"@odata.id": "` + manager + `/LogServices/Sel", "Id": "Sel"}`,
+		sel: `{"Members": [` +
+			entry("3", "Warning", "SUP0516") + `,` +
+			entry("2", "Critical", "FAN0001") + `,` +
+			entry("1", "OK", "PSU0003") +
+			`]}`,
+	})
+
+	s, err := NewClient().Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+	events, _, err := s.Events(0, nil)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+
+	// The worse of the logged and the registry severity wins
+	want := map[string]models.HealthStatus{
+		"1": models.HealthCritical, // logged OK, registry Critical
+		"2": models.HealthCritical, // logged Critical, registry Warning
+		"3": models.HealthWarning,  // logged Warning, registry OK
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for _, e := range events {
+		if e.Severity != want[e.ID] {
+			t.Errorf("event %s severity = %s, want %s", e.ID, e.Severity, want[e.ID])
+		}
+	}
+}
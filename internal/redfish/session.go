@@ -0,0 +1,912 @@
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// Session is an authenticated connection to a single BMC. It caches the
+// Systems, Chassis and Managers collections so a full inventory walks each
+// of them once. A Session is not safe for concurrent use.
+type Session struct {
+	api       *gofish.APIClient
+	service   *gofish.Service
+	systemID  string // ComputerSystem to inspect; empty for the first
+	identity  string // service root UUID, or the BMC address if it has none
+	transport *http.Transport
+	check     *certificateCheck
+	// registries caches message registries across sessions; nil to use only
+	// the embedded copies
+	registries *registryCache
+
+	systems            []*redfish.ComputerSystem
+	chassis            []*redfish.Chassis
+	systemChassis      *redfish.Chassis
+	managers           []*redfish.Manager
+	storage            []*redfish.Storage
+	ethernetInterfaces []*redfish.EthernetInterface
+	vendor             Vendor
+}
+
+// Connect logs in to a BMC and returns a Session. Callers must Close it. If
+// the BMC's certificate fails verification the error is a *CertificateError.
+func (c *Client) Connect(ctx context.Context, bmc models.BMCEndpoint, username, password string) (*Session, error) {
+	check, err := newCertificateCheck(bmc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA bundle for BMC: %w", err)
+	}
+	// Each session gets its own transport, as TLS settings differ per host
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: check.tlsConfig(),
+	}
+
+	config := gofish.ClientConfig{
+		Endpoint: bmc.BaseURL(),
+		Username: username,
+		Password: password,
+		HTTPClient: &http.Client{
+			Timeout:   c.timeout,
+			Transport: transport,
+		},
+	}
+
+	client, err := gofish.ConnectContext(ctx, config)
+	if err != nil {
+		transport.CloseIdleConnections()
+		if cert := check.certificate(); cert != nil && cert.Status == models.CertificateRejected {
+			return nil, &CertificateError{Certificate: cert}
+		}
+		return nil, fmt.Errorf("failed to connect to BMC: %w", err)
+	}
+
+	service := client.GetService()
+	identity := service.UUID
+	if identity == "" {
+		identity = bmc.Address()
+	}
+
+	return &Session{
+		api:        client,
+		service:    service,
+		systemID:   bmc.SystemID,
+		identity:   identity,
+		transport:  transport,
+		check:      check,
+		registries: c.registries,
+	}, nil
+}
+
+// Close logs out of the BMC
+func (s *Session) Close() {
+	s.api.Logout()
+	s.transport.CloseIdleConnections()
+}
+
+// Certificate returns how the BMC's TLS certificate validated, or nil for
+// plain HTTP
+func (s *Session) Certificate() *models.BMCCertificate {
+	return s.check.certificate()
+}
+
+// Systems returns the ComputerSystem collection, fetching it once per session.
+// Members are fetched concurrently, so they are sorted by URI to keep "the
+// first system" stable between polls.
+func (s *Session) Systems() ([]*redfish.ComputerSystem, error) {
+	if s.systems == nil {
+		systems, err := s.service.Systems()
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(systems, func(a, b *redfish.ComputerSystem) int {
+			return strings.Compare(a.ODataID, b.ODataID)
+		})
+		s.systems = systems
+	}
+	return s.systems, nil
+}
+
+// errNoSystems is returned when the BMC lists no ComputerSystems
+var errNoSystems = errors.New("no systems found")
+
+// System returns the ComputerSystem this session inspects: the one named by
+// the endpoint's system ID or, if it names none, the first system
+func (s *Session) System() (*redfish.ComputerSystem, error) {
+	systems, err := s.Systems()
+	if err != nil {
+		return nil, err
+	}
+	if len(systems) == 0 {
+		return nil, errNoSystems
+	}
+	if s.systemID == "" {
+		return systems[0], nil
+	}
+	for _, sys := range systems {
+		if sys.ID == s.systemID {
+			return sys, nil
+		}
+	}
+	return nil, fmt.Errorf("system %s not found", s.systemID)
+}
+
+// Manager returns the manager of the inspected system, or the first manager
+// if the BMC address names no system or the system links none
+func (s *Session) Manager() (*redfish.Manager, error) {
+	if s.systemID != "" {
+		if sys, err := s.System(); err == nil {
+			if managers, err := sys.ManagedBy(); err == nil && len(managers) > 0 {
+				return managers[0], nil
+			}
+		}
+	}
+
+	managers, err := s.Managers()
+	if err != nil {
+		return nil, err
+	}
+	if len(managers) == 0 {
+		return nil, fmt.Errorf("no managers found")
+	}
+	return managers[0], nil
+}
+
+// Vendor returns the driver for this BMC, chosen from the inspected system's
+// Manufacturer or, failing that, the service root Vendor
+func (s *Session) Vendor() Vendor {
+	if s.vendor == nil {
+		manufacturer := ""
+		if sys, err := s.System(); err == nil {
+			manufacturer = sys.Manufacturer
+		}
+		if manufacturer == "" {
+			manufacturer = s.service.Vendor
+		}
+		s.vendor = VendorFor(manufacturer)
+	}
+	return s.vendor
+}
+
+// Chassis returns the Chassis collection, fetching it once per session and
+// sorting it by URI like Systems
+func (s *Session) Chassis() ([]*redfish.Chassis, error) {
+	if s.chassis == nil {
+		chassis, err := s.service.Chassis()
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(chassis, func(a, b *redfish.Chassis) int {
+			return strings.Compare(a.ODataID, b.ODataID)
+		})
+		s.chassis = chassis
+	}
+	return s.chassis, nil
+}
+
+// SystemChassis returns the chassis holding the inspected system's fans and
+// power supplies: the main one among those the system links, or among the
+// whole Chassis collection if it links none. Fetched once per session.
+func (s *Session) SystemChassis() (*redfish.Chassis, error) {
+	if s.systemChassis != nil {
+		return s.systemChassis, nil
+	}
+
+	var candidates []*redfish.Chassis
+	if sys, err := s.System(); err == nil {
+		for _, uri := range linkedChassis(sys) {
+			ch, err := redfish.GetChassis(s.api, uri)
+			if err != nil {
+				log.Printf("Failed to get chassis %s: %v", uri, err)
+				continue
+			}
+			candidates = append(candidates, ch)
+		}
+	}
+	if len(candidates) == 0 {
+		chassis, err := s.Chassis()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chassis: %w", err)
+		}
+		candidates = chassis
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no chassis found")
+	}
+
+	s.systemChassis = mainChassis(candidates)
+	return s.systemChassis, nil
+}
+
+// linkedChassis returns the URIs of the chassis a system links to, which
+// gofish parses but does not expose
+func linkedChassis(sys *redfish.ComputerSystem) []string {
+	var links struct {
+		Links struct {
+			Chassis common.Links
+		}
+	}
+	if err := json.Unmarshal(sys.RawData, &links); err != nil {
+		return nil
+	}
+	return links.Links.Chassis.ToStrings()
+}
+
+// mainChassis picks the system chassis (rack-mount, blade or stand-alone)
+// over enclosures and other types, else the first
+func mainChassis(chassis []*redfish.Chassis) *redfish.Chassis {
+	for _, ch := range chassis {
+		if ch.ChassisType == redfish.RackMountChassisType ||
+			ch.ChassisType == redfish.BladeChassisType ||
+			ch.ChassisType == redfish.StandAloneChassisType {
+			return ch
+		}
+	}
+	return chassis[0]
+}
+
+// Managers returns the Manager collection, fetching it once per session
+func (s *Session) Managers() ([]*redfish.Manager, error) {
+	if s.managers == nil {
+		managers, err := s.service.Managers()
+		if err != nil {
+			return nil, err
+		}
+		s.managers = managers
+	}
+	return s.managers, nil
+}
+
+// Storage returns the inspected system's Storage collection, fetching it once per session
+func (s *Session) Storage() ([]*redfish.Storage, error) {
+	if s.storage == nil {
+		sys, err := s.System()
+		if err != nil {
+			return nil, err
+		}
+		storage, err := sys.Storage()
+		if err != nil {
+			return nil, err
+		}
+		s.storage = storage
+	}
+	return s.storage, nil
+}
+
+// EthernetInterfaces returns the inspected system's EthernetInterfaces, fetching them once per session
+func (s *Session) EthernetInterfaces() ([]*redfish.EthernetInterface, error) {
+	if s.ethernetInterfaces == nil {
+		sys, err := s.System()
+		if err != nil {
+			return nil, err
+		}
+		interfaces, err := sys.EthernetInterfaces()
+		if err != nil {
+			return nil, err
+		}
+		s.ethernetInterfaces = interfaces
+	}
+	return s.ethernetInterfaces, nil
+}
+
+// FirmwareInventory fetches the firmware inventory and basic system info
+func (s *Session) FirmwareInventory() ([]models.FirmwareComponent, *models.Node, error) {
+	// Get system info; firmware is still reported for a BMC listing no systems
+	sys, err := s.System()
+	if err != nil && !errors.Is(err, errNoSystems) {
+		return nil, nil, fmt.Errorf("failed to get system: %w", err)
+	}
+
+	var node *models.Node
+	if sys != nil {
+		node = &models.Node{
+			Model:        sys.Model,
+			Manufacturer: sys.Manufacturer,
+			ServiceTag:   sys.SKU,
+			SystemID:     s.Vendor().SystemID(sys),
+			PowerState:   parsePowerState(sys.PowerState),
+		}
+	}
+
+	// Get firmware inventory
+	updateService, err := s.service.UpdateService()
+	if err != nil {
+		return nil, node, fmt.Errorf("failed to get update service: %w", err)
+	}
+
+	inventory, err := updateService.FirmwareInventories()
+	if err != nil {
+		return nil, node, fmt.Errorf("failed to get firmware inventory: %w", err)
+	}
+
+	components := parseFirmwareInventory(inventory, s.Vendor())
+	return components, node, nil
+}
+
+// SystemHealth fetches the health rollup from the Systems and Chassis endpoints
+func (s *Session) SystemHealth() (*models.HealthRollup, models.HealthStatus, error) {
+	sys, err := s.System()
+	if err != nil {
+		return nil, models.HealthUnknown, fmt.Errorf("failed to get system: %w", err)
+	}
+
+	overallHealth := parseHealthStatus(sys.Status.Health)
+
+	rollup := &models.HealthRollup{
+		Processors:    models.HealthUnknown,
+		Memory:        models.HealthUnknown,
+		PowerSupplies: models.HealthUnknown,
+		Fans:          models.HealthUnknown,
+		Storage:       models.HealthUnknown,
+		Network:       models.HealthUnknown,
+	}
+
+	// Get Processor health from /redfish/v1/Systems/{systemId}/Processors
+	processors, err := sys.Processors()
+	if err == nil && len(processors) > 0 {
+		var procStatuses []models.HealthStatus
+		for _, proc := range processors {
+			procStatuses = append(procStatuses, parseHealthStatus(proc.Status.Health))
+		}
+		rollup.Processors = aggregateHealth(procStatuses)
+	} else if err != nil {
+		log.Printf("Failed to get processors: %v", err)
+	}
+
+	// Get Memory health from /redfish/v1/Systems/{systemId}/Memory
+	memory, err := sys.Memory()
+	if err == nil && len(memory) > 0 {
+		var memStatuses []models.HealthStatus
+		for _, mem := range memory {
+			memStatuses = append(memStatuses, parseHealthStatus(mem.Status.Health))
+		}
+		rollup.Memory = aggregateHealth(memStatuses)
+	} else if err != nil {
+		log.Printf("Failed to get memory: %v", err)
+	}
+
+	// Get Storage health from /redfish/v1/Systems/{systemId}/Storage
+	storage, err := s.Storage()
+	if err == nil && len(storage) > 0 {
+		var storageStatuses []models.HealthStatus
+		for _, stor := range storage {
+			storageStatuses = append(storageStatuses, parseHealthStatus(stor.Status.Health))
+		}
+		rollup.Storage = aggregateHealth(storageStatuses)
+	} else if err != nil {
+		log.Printf("Failed to get storage: %v", err)
+	}
+
+	// Get Network health from EthernetInterfaces
+	ethInterfaces, err := s.EthernetInterfaces()
+	if err == nil && len(ethInterfaces) > 0 {
+		var netStatuses []models.HealthStatus
+		for _, eth := range ethInterfaces {
+			netStatuses = append(netStatuses, parseHealthStatus(eth.Status.Health))
+		}
+		rollup.Network = aggregateHealth(netStatuses)
+	} else if err != nil {
+		log.Printf("Failed to get ethernet interfaces: %v", err)
+	}
+
+	// Get the system's chassis for fans and power supplies
+	if ch, err := s.SystemChassis(); err == nil {
+
+		// Try to get fans from ThermalSubsystem first, then legacy Thermal
+		fans, err := ch.Fans()
+		if err == nil && len(fans) > 0 {
+			var fanStatuses []models.HealthStatus
+			for _, fan := range fans {
+				fanStatuses = append(fanStatuses, parseHealthStatus(fan.Status.Health))
+			}
+			rollup.Fans = aggregateHealth(fanStatuses)
+		} else {
+			// Fall back to legacy Thermal endpoint
+			thermal, err := ch.Thermal()
+			if err == nil && thermal != nil && len(thermal.Fans) > 0 {
+				var fanStatuses []models.HealthStatus
+				for _, fan := range thermal.Fans {
+					fanStatuses = append(fanStatuses, parseHealthStatus(fan.Status.Health))
+				}
+				rollup.Fans = aggregateHealth(fanStatuses)
+			}
+		}
+
+		// Try to get power supplies from PowerSubsystem first, then legacy Power
+		psus, err := ch.PowerSupplies()
+		if err == nil && len(psus) > 0 {
+			var psuStatuses []models.HealthStatus
+			for _, psu := range psus {
+				psuStatuses = append(psuStatuses, parseHealthStatus(psu.Status.Health))
+			}
+			rollup.PowerSupplies = aggregateHealth(psuStatuses)
+		} else {
+			// Fall back to legacy Power endpoint
+			power, err := ch.Power()
+			if err == nil && power != nil && len(power.PowerSupplies) > 0 {
+				var psuStatuses []models.HealthStatus
+				for _, psu := range power.PowerSupplies {
+					psuStatuses = append(psuStatuses, parseHealthStatus(psu.Status.Health))
+				}
+				rollup.PowerSupplies = aggregateHealth(psuStatuses)
+			}
+		}
+	}
+
+	return rollup, overallHealth, nil
+}
+
+// ThermalData fetches temperature and fan data from the system's chassis
+func (s *Session) ThermalData() (*models.ThermalDetail, *models.ThermalSummary, error) {
+	mainChassis, err := s.SystemChassis()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	detail := &models.ThermalDetail{
+		Temperatures: make([]models.ThermalReading, 0),
+		Fans:         make([]models.FanReading, 0),
+	}
+
+	var maxTemp, inletTemp int
+	fansHealthy := 0
+	totalFans := 0
+
+	// Try new ThermalSubsystem API first
+	thermalSub, err := mainChassis.ThermalSubsystem()
+	if err == nil && thermalSub != nil {
+		// Get thermal metrics for temperatures
+		metrics, err := thermalSub.ThermalMetrics()
+		if err == nil && metrics != nil {
+			for _, temp := range metrics.TemperatureReadingsCelsius {
+				reading := models.ThermalReading{
+					Name:   temp.DeviceName,
+					TempC:  int(temp.Reading),
+					Status: models.HealthOK, // ThermalMetrics doesn't have per-sensor status
+				}
+				detail.Temperatures = append(detail.Temperatures, reading)
+
+				if int(temp.Reading) > maxTemp {
+					maxTemp = int(temp.Reading)
+				}
+				if contains(temp.DeviceName, "Inlet", "Ambient", "System Board Inlet") {
+					inletTemp = int(temp.Reading)
+				}
+			}
+		}
+
+		// Get fans from ThermalSubsystem
+		fans, err := thermalSub.Fans()
+		if err == nil {
+			for _, fan := range fans {
+				status := parseHealthStatus(fan.Status.Health)
+				reading := models.FanReading{
+					Name:   fan.Name,
+					RPM:    int(fan.SpeedPercent.Reading),
+					Status: status,
+				}
+				detail.Fans = append(detail.Fans, reading)
+				totalFans++
+				if status == models.HealthOK {
+					fansHealthy++
+				}
+			}
+		}
+	}
+
+	// Fall back to legacy Thermal endpoint if no data from ThermalSubsystem
+	if len(detail.Temperatures) == 0 || len(detail.Fans) == 0 {
+		thermal, err := mainChassis.Thermal()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get thermal data: %w", err)
+		}
+		if thermal == nil {
+			return nil, nil, fmt.Errorf("thermal data not available")
+		}
+
+		// Get temperatures from legacy endpoint
+		if len(detail.Temperatures) == 0 {
+			for _, t := range thermal.Temperatures {
+				reading := models.ThermalReading{
+					Name:   t.Name,
+					TempC:  int(t.ReadingCelsius),
+					Status: parseHealthStatus(t.Status.Health),
+				}
+				detail.Temperatures = append(detail.Temperatures, reading)
+
+				if int(t.ReadingCelsius) > maxTemp {
+					maxTemp = int(t.ReadingCelsius)
+				}
+				if contains(t.Name, "Inlet", "Ambient", "System Board Inlet") {
+					inletTemp = int(t.ReadingCelsius)
+				}
+			}
+		}
+
+		// Get fans from legacy endpoint
+		if len(detail.Fans) == 0 {
+			for _, f := range thermal.Fans {
+				status := parseHealthStatus(f.Status.Health)
+				reading := models.FanReading{
+					Name:   f.Name,
+					RPM:    int(f.Reading),
+					Status: status,
+				}
+				detail.Fans = append(detail.Fans, reading)
+				totalFans++
+				if status == models.HealthOK {
+					fansHealthy++
+				}
+			}
+		}
+	}
+
+	summary := &models.ThermalSummary{
+		InletTempC:  inletTemp,
+		MaxTempC:    maxTemp,
+		FanCount:    totalFans,
+		FansHealthy: fansHealthy,
+		Status:      models.HealthOK,
+	}
+
+	if totalFans > 0 && fansHealthy < totalFans {
+		summary.Status = models.HealthWarning
+	}
+
+	return detail, summary, nil
+}
+
+// PowerData fetches power supply and consumption data from the system's chassis
+func (s *Session) PowerData() (*models.PowerDetail, *models.PowerSummary, error) {
+	mainChassis, err := s.SystemChassis()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	detail := &models.PowerDetail{
+		PSUs: make([]models.PSUReading, 0),
+	}
+
+	psusHealthy := 0
+	totalPSUs := 0
+
+	// Try new PowerSubsystem API first
+	powerSub, err := mainChassis.PowerSubsystem()
+	if err == nil && powerSub != nil {
+		// Get power supplies from PowerSubsystem
+		psus, err := mainChassis.PowerSupplies()
+		if err == nil {
+			for _, psu := range psus {
+				status := parseHealthStatus(psu.Status.Health)
+				reading := models.PSUReading{
+					Name:      psu.Name,
+					Status:    status,
+					CapacityW: int(psu.PowerCapacityWatts),
+				}
+				detail.PSUs = append(detail.PSUs, reading)
+				totalPSUs++
+				if status == models.HealthOK {
+					psusHealthy++
+				}
+			}
+		}
+
+		// Get power consumption from EnvironmentMetrics
+		envMetrics, err := mainChassis.EnvironmentMetrics()
+		if err == nil && envMetrics != nil {
+			detail.CurrentWatts = int(envMetrics.PowerWatts.Reading)
+		}
+	}
+
+	// Fall back to legacy Power endpoint if no data
+	if len(detail.PSUs) == 0 {
+		power, err := mainChassis.Power()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get power data: %w", err)
+		}
+		if power == nil {
+			return nil, nil, fmt.Errorf("power data not available")
+		}
+
+		// Get current power consumption from legacy endpoint
+		if detail.CurrentWatts == 0 && len(power.PowerControl) > 0 {
+			detail.CurrentWatts = int(power.PowerControl[0].PowerConsumedWatts)
+		}
+
+		// Get PSUs from legacy endpoint
+		for _, psu := range power.PowerSupplies {
+			status := parseHealthStatus(psu.Status.Health)
+			reading := models.PSUReading{
+				Name:      psu.Name,
+				Status:    status,
+				CapacityW: int(psu.PowerCapacityWatts),
+			}
+			detail.PSUs = append(detail.PSUs, reading)
+			totalPSUs++
+			if status == models.HealthOK {
+				psusHealthy++
+			}
+		}
+	}
+
+	redundancy := "Full"
+	if totalPSUs > 0 && psusHealthy < totalPSUs {
+		redundancy = "Lost"
+	}
+	detail.Redundancy = redundancy
+
+	summary := &models.PowerSummary{
+		CurrentWatts: detail.CurrentWatts,
+		PSUCount:     totalPSUs,
+		PSUsHealthy:  psusHealthy,
+		Redundancy:   redundancy,
+		Status:       models.HealthOK,
+	}
+
+	if totalPSUs > 0 && psusHealthy < totalPSUs {
+		summary.Status = models.HealthCritical
+	}
+
+	return detail, summary, nil
+}
+
+// NetworkAdapters fetches network interface details
+func (s *Session) NetworkAdapters() ([]models.NetworkAdapter, error) {
+	if _, err := s.System(); err != nil {
+		return nil, fmt.Errorf("failed to get system: %w", err)
+	}
+
+	adapters := make([]models.NetworkAdapter, 0)
+
+	// Get EthernetInterfaces from Systems endpoint
+	ethInterfaces, err := s.EthernetInterfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ethernet interfaces: %w", err)
+	}
+
+	for _, eth := range ethInterfaces {
+		adapter := models.NetworkAdapter{
+			Name:       eth.Name,
+			Port:       eth.ID,
+			MACAddress: eth.MACAddress,
+			LinkStatus: normalizeLinkStatus(string(eth.LinkStatus)),
+			LinkSpeed:  FormatLinkSpeed(eth.SpeedMbps),
+			Model:      eth.Name, // Default to name, will be enriched if NetworkAdapters available
+		}
+		adapters = append(adapters, adapter)
+	}
+
+	// Try to get detailed model info from Chassis NetworkAdapters
+	chassis, err := s.Chassis()
+	if err == nil && len(chassis) > 0 {
+		for _, ch := range chassis {
+			netAdapters, err := ch.NetworkAdapters()
+			if err != nil || len(netAdapters) == 0 {
+				continue
+			}
+			// Build model lookup from network adapters
+			for _, na := range netAdapters {
+				// Update adapters that match this network adapter's ports
+				for i := range adapters {
+					if contains(adapters[i].Port, na.ID) || contains(adapters[i].Name, na.ID) {
+						adapters[i].Model = na.Model
+					}
+				}
+			}
+		}
+	}
+
+	return adapters, nil
+}
+
+// StorageDetails fetches controller and disk information
+func (s *Session) StorageDetails() (*models.StorageDetail, error) {
+	if _, err := s.System(); err != nil {
+		return nil, fmt.Errorf("failed to get system: %w", err)
+	}
+
+	detail := &models.StorageDetail{
+		Controllers: make([]models.StorageController, 0),
+		Disks:       make([]models.Disk, 0),
+	}
+
+	// Get Storage subsystems
+	storageCollection, err := s.Storage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage: %w", err)
+	}
+
+	for _, storage := range storageCollection {
+		// Get controller info from StorageControllers
+		for _, sc := range storage.StorageControllers {
+			controller := models.StorageController{
+				Name:              storage.Name,
+				DeviceDescription: sc.Model,
+				FirmwareVersion:   sc.FirmwareVersion,
+				PCIeSlot:          "", // Will try to get from Location
+			}
+			if sc.Location.PartLocation.ServiceLabel != "" {
+				controller.PCIeSlot = sc.Location.PartLocation.ServiceLabel
+			}
+			detail.Controllers = append(detail.Controllers, controller)
+		}
+
+		// Get drives
+		drives, err := storage.Drives()
+		if err != nil {
+			log.Printf("Failed to get drives for %s: %v", storage.Name, err)
+			continue
+		}
+
+		for _, drive := range drives {
+			disk := models.Disk{
+				Name:        drive.Name,
+				State:       normalizeDriveState(string(drive.Status.State)),
+				SlotNumber:  "",
+				Size:        FormatCapacity(drive.CapacityBytes),
+				BusProtocol: string(drive.Protocol),
+				MediaType:   string(drive.MediaType),
+			}
+			if drive.PhysicalLocation.PartLocation.ServiceLabel != "" {
+				disk.SlotNumber = drive.PhysicalLocation.PartLocation.ServiceLabel
+			}
+			detail.Disks = append(detail.Disks, disk)
+		}
+	}
+
+	return detail, nil
+}
+
+// Subsystem identifies one area of a host's Redfish data
+type Subsystem string
+
+const (
+	SubsystemFirmware Subsystem = "firmware"
+	SubsystemHealth   Subsystem = "health"
+	SubsystemThermal  Subsystem = "thermal"
+	SubsystemPower    Subsystem = "power"
+	SubsystemNetwork  Subsystem = "network"
+	SubsystemStorage  Subsystem = "storage"
+	SubsystemEvents   Subsystem = "events"
+)
+
+// CollectOptions controls what Collect gathers
+type CollectOptions struct {
+	// Events enables reading the System Event Log
+	Events bool
+	// EventLimit caps the number of entries returned from each log, oldest
+	// first; the rest are returned by later polls (0 = no limit)
+	EventLimit int
+	// EventMarks are the high-water marks from earlier polls; only newer
+	// entries are returned
+	EventMarks map[string]models.EventMark
+	// Only restricts collection to these subsystems (empty = all)
+	Only []Subsystem
+}
+
+// Subsystems lists the subsystems Collect gathers with these options
+func (o CollectOptions) Subsystems() []Subsystem {
+	var subsystems []Subsystem
+	for _, sub := range []Subsystem{
+		SubsystemFirmware,
+		SubsystemHealth,
+		SubsystemThermal,
+		SubsystemPower,
+		SubsystemNetwork,
+		SubsystemStorage,
+		SubsystemEvents,
+	} {
+		if o.includes(sub) {
+			subsystems = append(subsystems, sub)
+		}
+	}
+	return subsystems
+}
+
+func (o CollectOptions) includes(sub Subsystem) bool {
+	if sub == SubsystemEvents && !o.Events {
+		return false
+	}
+	return len(o.Only) == 0 || slices.Contains(o.Only, sub)
+}
+
+// HostData is everything gathered from one BMC in a single session. A failure in
+// one subsystem is recorded in Errors and does not prevent collecting the others.
+type HostData struct {
+	Vendor          Vendor
+	System          *models.Node
+	Firmware        []models.FirmwareComponent
+	Health          models.HealthStatus
+	HealthRollup    *models.HealthRollup
+	Thermal         *models.ThermalDetail
+	ThermalSummary  *models.ThermalSummary
+	Power           *models.PowerDetail
+	PowerSummary    *models.PowerSummary
+	NetworkAdapters []models.NetworkAdapter
+	Storage         *models.StorageDetail
+	Events          []models.HealthEvent
+	EventMarks      map[string]models.EventMark
+	Certificate     *models.BMCCertificate
+	Errors          map[Subsystem]error
+}
+
+// Err returns the error recorded for a subsystem, if any
+func (d *HostData) Err(sub Subsystem) error {
+	return d.Errors[sub]
+}
+
+// Collect logs in to a BMC once and gathers firmware, health, thermal, power,
+// network, storage and (optionally) event data. The returned error is only set
+// when the session itself could not be established.
+func (c *Client) Collect(ctx context.Context, bmc models.BMCEndpoint, username, password string, opts CollectOptions) (*HostData, error) {
+	s, err := c.Connect(ctx, bmc, username, password)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.Collect(opts), nil
+}
+
+// Collect gathers the subsystems selected by opts over this session
+func (s *Session) Collect(opts CollectOptions) *HostData {
+	data := &HostData{
+		Health:      models.HealthUnknown,
+		Certificate: s.Certificate(),
+		Errors:      make(map[Subsystem]error),
+	}
+	record := func(sub Subsystem, err error) {
+		if err != nil {
+			data.Errors[sub] = err
+		}
+	}
+
+	var err error
+	if opts.includes(SubsystemFirmware) {
+		data.Firmware, data.System, err = s.FirmwareInventory()
+		record(SubsystemFirmware, err)
+	}
+	data.Vendor = s.Vendor()
+
+	if opts.includes(SubsystemHealth) {
+		data.HealthRollup, data.Health, err = s.SystemHealth()
+		record(SubsystemHealth, err)
+	}
+
+	if opts.includes(SubsystemThermal) {
+		data.Thermal, data.ThermalSummary, err = s.ThermalData()
+		record(SubsystemThermal, err)
+	}
+
+	if opts.includes(SubsystemPower) {
+		data.Power, data.PowerSummary, err = s.PowerData()
+		record(SubsystemPower, err)
+	}
+
+	if opts.includes(SubsystemNetwork) {
+		data.NetworkAdapters, err = s.NetworkAdapters()
+		record(SubsystemNetwork, err)
+	}
+
+	if opts.includes(SubsystemStorage) {
+		data.Storage, err = s.StorageDetails()
+		record(SubsystemStorage, err)
+	}
+
+	if opts.includes(SubsystemEvents) {
+		data.Events, data.EventMarks, err = s.Events(opts.EventLimit, opts.EventMarks)
+		record(SubsystemEvents, err)
+	}
+
+	return data
+}
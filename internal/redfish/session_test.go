@@ -0,0 +1,305 @@
+package redfish
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func TestClient_Collect_SingleSession(t *testing.T) {
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/Systems": `{"Members": [{"@odata.id": "/redfish/v1/Systems/System.Embedded.1"}]}`,
+		"/redfish/v1/Systems/System.Embedded.1": `{
+			"@odata.id": "/redfish/v1/Systems/System.Embedded.1",
+			"Id": "System.Embedded.1",
+			"Manufacturer": "Dell Inc.",
+			"Model": "PowerEdge R640",
+			"SKU": "ABC1234",
+			"PowerState": "On",
+			"Status": {"Health": "OK"}
+		}`,
+		"/redfish/v1/UpdateService": `{
+			"@odata.id": "/redfish/v1/UpdateService",
+			"FirmwareInventory": {"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory"}
+		}`,
+		"/redfish/v1/UpdateService/FirmwareInventory": `{"Members": [{"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BIOS"}]}`,
+		"/redfish/v1/UpdateService/FirmwareInventory/BIOS": `{
+			"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BIOS",
+			"Id": "BIOS",
+			"Name": "BIOS",
+			"Version": "2.18.1",
+			"Updateable": true
+		}`,
+		"/redfish/v1/Managers": `{"Members": []}`,
+	})
+
+	client := NewClient()
+	data, err := client.Collect(context.Background(), bmc.endpoint(), "root", "calvin", CollectOptions{Events: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bmc.sessions != 1 || bmc.logouts != 1 {
+		t.Errorf("expected 1 session and 1 logout, got %d and %d", bmc.sessions, bmc.logouts)
+	}
+
+	if data.Vendor == nil || data.Vendor.Name() != "dell" {
+		t.Errorf("expected dell vendor, got %v", data.Vendor)
+	}
+	if data.System == nil || data.System.Model != "PowerEdge R640" {
+		t.Errorf("unexpected system info: %+v", data.System)
+	}
+	if err := data.Err(SubsystemFirmware); err != nil {
+		t.Errorf("unexpected firmware error: %v", err)
+	}
+	if len(data.Firmware) != 1 || data.Firmware[0].CurrentVersion != "2.18.1" {
+		t.Errorf("unexpected firmware: %+v", data.Firmware)
+	}
+
+	// No chassis or managers: those subsystems fail without affecting the rest
+	if data.Err(SubsystemThermal) == nil {
+		t.Error("expected thermal error with no chassis")
+	}
+	if data.Err(SubsystemEvents) == nil {
+		t.Error("expected events error with no managers")
+	}
+
+	// Only the selected subsystems are gathered
+	data, err = client.Collect(context.Background(), bmc.endpoint(), "root", "calvin", CollectOptions{Events: true, Only: []Subsystem{SubsystemFirmware}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Firmware) != 1 || data.Err(SubsystemThermal) != nil || data.Err(SubsystemEvents) != nil {
+		t.Errorf("expected only firmware to be collected, got errors %v", data.Errors)
+	}
+}
+
+func TestCollectOptions_Subsystems(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CollectOptions
+		want int
+	}{
+		{"all without events", CollectOptions{}, 6},
+		{"all with events", CollectOptions{Events: true}, 7},
+		{"only events without event log", CollectOptions{Only: []Subsystem{SubsystemEvents}}, 0},
+		{"only health and power", CollectOptions{Only: []Subsystem{SubsystemHealth, SubsystemPower}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Subsystems(); len(got) != tt.want {
+				t.Errorf("expected %d subsystems, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClient_Collect_ConnectError(t *testing.T) {
+	client := NewClient()
+	_, err := client.Collect(context.Background(), models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"}, "root", "calvin", CollectOptions{})
+	if err == nil {
+		t.Error("expected error for unreachable BMC")
+	}
+}
+
+func TestClient_Collect_SystemID(t *testing.T) {
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/Systems": `{"Members": [
+			{"@odata.id": "/redfish/v1/Systems/System.Embedded.1"},
+			{"@odata.id": "/redfish/v1/Systems/System.Embedded.2"}
+		]}`,
+		"/redfish/v1/Systems/System.Embedded.1": `{
+			"@odata.id": "/redfish/v1/Systems/System.Embedded.1",
+			"Id": "System.Embedded.1",
+			"Manufacturer": "Dell Inc.",
+			"Model": "PowerEdge C6420",
+			"SKU": "NODE001",
+			"Status": {"Health": "OK"}
+		}`,
+		"/redfish/v1/Systems/System.Embedded.2": `{
+			"@odata.id": "/redfish/v1/Systems/System.Embedded.2",
+			"Id": "System.Embedded.2",
+			"Manufacturer": "Dell Inc.",
+			"Model": "PowerEdge C6420",
+			"SKU": "NODE002",
+			"Status": {"Health": "Critical"}
+		}`,
+		"/redfish/v1/Managers": `{"Members": []}`,
+	})
+	client := NewClient()
+
+	tests := []struct {
+		systemID string
+		wantTag  string
+		wantErr  bool
+	}{
+		{"", "NODE001", false},
+		{"System.Embedded.2", "NODE002", false},
+		{"System.Embedded.3", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.systemID, func(t *testing.T) {
+			endpoint := bmc.endpoint()
+			endpoint.SystemID = tt.systemID
+			data, err := client.Collect(context.Background(), endpoint, "root", "calvin", CollectOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if data.Err(SubsystemHealth) == nil || data.System != nil {
+					t.Errorf("expected an unknown system to fail, got %+v", data.System)
+				}
+				return
+			}
+			if data.System == nil || data.System.ServiceTag != tt.wantTag {
+				t.Errorf("expected system %s, got %+v", tt.wantTag, data.System)
+			}
+		})
+	}
+}
+
+func TestSession_SystemChassis(t *testing.T) {
+	resources := map[string]string{
+		"/redfish/v1/Systems": `{"Members": [
+			{"@odata.id": "/redfish/v1/Systems/System.Embedded.1"},
+			{"@odata.id": "/redfish/v1/Systems/System.Embedded.2"}
+		]}`,
+		// The shared enclosure sorts first and is rack-mount, but holds neither
+		// system's sensors
+		"/redfish/v1/Chassis": `{"Members": [
+			{"@odata.id": "/redfish/v1/Chassis/Enclosure.1"},
+			{"@odata.id": "/redfish/v1/Chassis/Sled.1"},
+			{"@odata.id": "/redfish/v1/Chassis/Sled.2"}
+		]}`,
+		"/redfish/v1/Chassis/Enclosure.1": `{"@odata.id": "/redfish/v1/Chassis/Enclosure.1", "Id": "Enclosure.1", "ChassisType": "RackMount"}`,
+		"/redfish/v1/Managers":            `{"Members": []}`,
+	}
+	for i, health := range []string{"OK", "Critical"} {
+		n := strconv.Itoa(i + 1)
+		system, chassis := "/redfish/v1/Systems/System.Embedded."+n, "/redfish/v1/Chassis/Sled."+n
+		resources[system] = `{
+			"@odata.id": "` + system + `",
+			"Id": "System.Embedded.` + n + `",
+			"Status": {"Health": "OK"},
+			"Links": {"Chassis": [{"@odata.id": "` + chassis + `"}]}
+		}`
+		resources[chassis] = `{
+			"@odata.id": "` + chassis + `",
+			"Id": "Sled.` + n + `",
+			"ChassisType": "Sled",
+			"Thermal": {"@odata.id": "` + chassis + `/Thermal"},
+			"Power": {"@odata.id": "` + chassis + `/Power"}
+		}`
+		resources[chassis+"/Thermal"] = `{
+			"@odata.id": "` + chassis + `/Thermal",
+			"Temperatures": [{"Name": "System Board Inlet Temp", "ReadingCelsius": 2` + n + `, "Status": {"Health": "OK"}}],
+			"Fans": [{"Name": "Sled ` + n + ` Fan", "Reading": 5000, "Status": {"Health": "` + health + `"}}]
+		}`
+		resources[chassis+"/Power"] = `{
+			"@odata.id": "` + chassis + `/Power",
+			"PowerSupplies": [{"Name": "Sled ` + n + ` PSU", "PowerCapacityWatts": 1100, "Status": {"Health": "OK"}}]
+		}`
+	}
+	bmc := newMockBMC(t, resources)
+
+	endpoint := bmc.endpoint()
+	endpoint.SystemID = "System.Embedded.2"
+	s, err := NewClient().Connect(context.Background(), endpoint, "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+
+	_, thermal, err := s.ThermalData()
+	if err != nil || thermal.InletTempC != 22 {
+		t.Errorf("expected the second sled's inlet temperature, got %+v (%v)", thermal, err)
+	}
+	power, _, err := s.PowerData()
+	if err != nil || len(power.PSUs) != 1 || power.PSUs[0].Name != "Sled 2 PSU" {
+		t.Errorf("expected the second sled's PSU, got %+v (%v)", power, err)
+	}
+	rollup, _, err := s.SystemHealth()
+	if err != nil || rollup.Fans != models.HealthCritical {
+		t.Errorf("expected the second sled's fan health, got %+v (%v)", rollup, err)
+	}
+
+	// A system that links no chassis falls back to the main one in the collection
+	bmc.mu.Lock()
+	bmc.resources["/redfish/v1/Systems/System.Embedded.2"] = `{"@odata.id": "/redfish/v1/Systems/System.Embedded.2", "Id": "System.Embedded.2"}`
+	bmc.mu.Unlock()
+	s.systems, s.systemChassis = nil, nil
+	if ch, err := s.SystemChassis(); err != nil || ch.ID != "Enclosure.1" {
+		t.Errorf("expected the rack-mount chassis, got %+v (%v)", ch, err)
+	}
+}
+
+func selEntry(id, created, severity string) string {
+	return `{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel/Entries/` + id + `", "Id": "` + id +
+		`", "Created": "` + created + `", "Severity": "` + severity + `", "Message": "entry ` + id + `"}`
+}
+
+func TestSession_Events_HighWaterMark(t *testing.T) {
+	const entries = "/redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel/Entries"
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/Systems":  `{"Members": []}`,
+		"/redfish/v1/Managers": `{"Members": [{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1"}]}`,
+		"/redfish/v1/Managers/iDRAC.Embedded.1": `{
+			"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1",
+			"Id": "iDRAC.Embedded.1",
+			"LogServices": {"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/LogServices"}
+		}`,
+		"/redfish/v1/Managers/iDRAC.Embedded.1/LogServices": `{"Members": [{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel"}]}`,
+		"/redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel": `{
+			"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel",
+			"Id": "Sel",
+			"Entries": {"@odata.id": "` + entries + `"}
+		}`,
+		entries:        `{"Members": [{"@odata.id": "` + entries + `/1"}, {"@odata.id": "` + entries + `/2"}]}`,
+		entries + "/1": selEntry("1", "2024-01-01T00:00:00Z", "OK"),
+		entries + "/2": selEntry("2", "2024-01-01T01:00:00Z", "Critical"),
+	})
+
+	s, err := NewClient().Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+
+	events, marks, err := s.Events(0, nil)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	// Oldest first
+	if len(events) != 2 || events[1].ID != "2" || events[1].LogService != "Sel" || events[1].BMC == "" || events[1].Created != "2024-01-01T01:00:00Z" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	// The limit takes the oldest unread entries and the mark stops past them
+	limited, limitedMarks, _ := s.Events(1, nil)
+	if len(limited) != 1 || limited[0].ID != "1" {
+		t.Errorf("expected only the oldest entry, got %+v", limited)
+	}
+	if limited, _, _ = s.Events(1, limitedMarks); len(limited) != 1 || limited[0].ID != "2" {
+		t.Errorf("expected the entry beyond the limit next, got %+v", limited)
+	}
+
+	// Entries at or below the mark are not read again
+	events, marks, err = s.Events(0, marks)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected no new events, got %+v (%v)", events, err)
+	}
+
+	// A new entry logged in the same second as the newest one is still read
+	bmc.mu.Lock()
+	bmc.resources[entries] = `{"Members": [{"@odata.id": "` + entries + `/1"}, {"@odata.id": "` + entries + `/2"}, {"@odata.id": "` + entries + `/3"}]}`
+	bmc.resources[entries+"/3"] = selEntry("3", "2024-01-01T01:00:00Z", "Warning")
+	bmc.mu.Unlock()
+	s.managers = nil
+
+	events, _, err = s.Events(0, marks)
+	if err != nil || len(events) != 1 || events[0].ID != "3" || events[0].Severity != models.HealthWarning {
+		t.Fatalf("expected only entry 3, got %+v (%v)", events, err)
+	}
+}
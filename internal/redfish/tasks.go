@@ -0,0 +1,165 @@
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// TaskStatus is the current state of a BMC task or job
+type TaskStatus struct {
+	ID              string
+	State           models.TaskState
+	PercentComplete int
+	Message         string
+	EndTime         time.Time
+	// Missing is set if the BMC answered that it knows no such task or job,
+	// e.g. after its job queue was cleared; the other fields are then empty
+	Missing bool
+}
+
+// dellJob is the subset of the iDRAC job queue entry (Oem/Dell/Jobs/{id}) we use
+type dellJob struct {
+	ID              string `json:"Id"`
+	JobState        string
+	PercentComplete int
+	Message         string
+	CompletionTime  string
+}
+
+// GetTaskStatuses fetches the state of the given tasks from the BMC TaskService, falling
+// back to the vendor's OEM job queue (the iDRAC job queue on Dell) for IDs the TaskService
+// does not know. Tasks that neither knows are returned as Missing; those that could
+// not be read for another reason are omitted from the result.
+func (c *Client) GetTaskStatuses(ctx context.Context, bmc models.BMCEndpoint, username, password string, taskIDs []string) (map[string]TaskStatus, error) {
+	s, err := c.Connect(ctx, bmc, username, password)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	// OEM job queues live under the system's manager
+	jobsURI := ""
+	if manager, err := s.Manager(); err == nil {
+		jobsURI = s.Vendor().JobQueueURI(manager.ODataID)
+	}
+
+	statuses := make(map[string]TaskStatus, len(taskIDs))
+	for _, id := range taskIDs {
+		task, err := redfish.GetTask(s.api, "/redfish/v1/TaskService/Tasks/"+id)
+		if err == nil {
+			statuses[id] = taskStatusFromTask(id, task)
+			continue
+		}
+		missing := isNotFound(err)
+
+		if jobsURI == "" {
+			if missing {
+				statuses[id] = TaskStatus{ID: id, Missing: true}
+			}
+			continue
+		}
+		resp, err := s.api.Get(jobsURI + id)
+		if err != nil {
+			if missing && isNotFound(err) {
+				statuses[id] = TaskStatus{ID: id, Missing: true}
+			}
+			continue
+		}
+		var job dellJob
+		err = json.NewDecoder(resp.Body).Decode(&job)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		statuses[id] = taskStatusFromDellJob(id, job)
+	}
+
+	return statuses, nil
+}
+
+func taskStatusFromTask(id string, task *redfish.Task) TaskStatus {
+	status := TaskStatus{
+		ID:              id,
+		State:           parseTaskState(task.TaskState),
+		PercentComplete: task.PercentComplete,
+		EndTime:         parseTimestamp(task.EndTime),
+	}
+	if len(task.Messages) > 0 {
+		status.Message = task.Messages[len(task.Messages)-1].Message
+	}
+	if status.State == models.TaskCompleted && task.TaskStatus != "" && task.TaskStatus != common.OKHealth {
+		status.State = models.TaskFailed
+	}
+	return status
+}
+
+func taskStatusFromDellJob(id string, job dellJob) TaskStatus {
+	return TaskStatus{
+		ID:              id,
+		State:           parseDellJobState(job.JobState),
+		PercentComplete: job.PercentComplete,
+		Message:         job.Message,
+		EndTime:         parseTimestamp(job.CompletionTime),
+	}
+}
+
+// parseTaskState maps a Redfish TaskState onto the states we track
+func parseTaskState(state redfish.TaskState) models.TaskState {
+	switch state {
+	case redfish.NewTaskState, redfish.StartingTaskState, redfish.PendingTaskState, redfish.SuspendedTaskState:
+		return models.TaskPending
+	case redfish.RunningTaskState, redfish.StoppingTaskState, redfish.CancellingTaskState, redfish.ServiceTaskState:
+		return models.TaskRunning
+	case redfish.CompletedTaskState:
+		return models.TaskCompleted
+	case redfish.ExceptionTaskState, redfish.KilledTaskState, redfish.CancelledTaskState, redfish.InterruptedTaskState:
+		return models.TaskFailed
+	default:
+		return models.TaskPending
+	}
+}
+
+// parseDellJobState maps an iDRAC JobState onto the states we track
+func parseDellJobState(state string) models.TaskState {
+	switch strings.ToLower(state) {
+	case "completed":
+		return models.TaskCompleted
+	case "failed", "completedwitherrors", "cancelled", "canceled":
+		return models.TaskFailed
+	case "running", "downloading", "downloaded", "readyforexecution", "pendingactivation":
+		return models.TaskRunning
+	default:
+		// New, Scheduled, Scheduling, Waiting, Paused
+		return models.TaskPending
+	}
+}
+
+// timestampLayouts are the forms BMCs use for Redfish timestamps: RFC 3339, an
+// offset without a colon as some iDRAC and Supermicro firmware logs, or no zone
+// at all, which is taken as UTC
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// parseTimestamp parses a Redfish timestamp, returning the zero time if absent or invalid
+func parseTimestamp(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
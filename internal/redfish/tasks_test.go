@@ -0,0 +1,108 @@
+package redfish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/redfish"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func TestParseTaskState(t *testing.T) {
+	tests := []struct {
+		input redfish.TaskState
+		want  models.TaskState
+	}{
+		{redfish.NewTaskState, models.TaskPending},
+		{redfish.RunningTaskState, models.TaskRunning},
+		{redfish.CompletedTaskState, models.TaskCompleted},
+		{redfish.ExceptionTaskState, models.TaskFailed},
+		{redfish.CancelledTaskState, models.TaskFailed},
+	}
+	for _, tt := range tests {
+		if got := parseTaskState(tt.input); got != tt.want {
+			t.Errorf("parseTaskState(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseDellJobState(t *testing.T) {
+	tests := []struct {
+		input string
+		want  models.TaskState
+	}{
+		{"Scheduled", models.TaskPending},
+		{"Downloaded", models.TaskRunning},
+		{"Running", models.TaskRunning},
+		{"Completed", models.TaskCompleted},
+		{"CompletedWithErrors", models.TaskFailed},
+		{"Failed", models.TaskFailed},
+	}
+	for _, tt := range tests {
+		if got := parseDellJobState(tt.input); got != tt.want {
+			t.Errorf("parseDellJobState(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2024-03-01T12:00:00Z", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"2024-03-01T12:00:00-06:00", time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)},
+		{"2024-03-01T12:00:00.250+05:30", time.Date(2024, 3, 1, 6, 30, 0, 250e6, time.UTC)},
+		{"2024-03-01T12:00:00-0600", time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)},
+		{"2024-03-01T12:00:00", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"", time.Time{}},
+		{"yesterday", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseTimestamp(tt.input); !got.Equal(tt.want) {
+			t.Errorf("parseTimestamp(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestClient_GetTaskStatuses(t *testing.T) {
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/Managers":                  `{"Members": [{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1"}]}`,
+		"/redfish/v1/Managers/iDRAC.Embedded.1": `{"@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1", "Id": "iDRAC.Embedded.1"}`,
+		"/redfish/v1/TaskService/Tasks/JID_1": `{
+			"@odata.id": "/redfish/v1/TaskService/Tasks/JID_1",
+			"Id": "JID_1",
+			"TaskState": "Running",
+			"PercentComplete": 50,
+			"Messages": [{"Message": "Downloading package."}]
+		}`,
+		"/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/Jobs/JID_2": `{
+			"Id": "JID_2",
+			"JobState": "Completed",
+			"PercentComplete": 100,
+			"Message": "Job completed successfully.",
+			"CompletionTime": "2025-01-02T03:04:05"
+		}`,
+	})
+
+	client := NewClient()
+	statuses, err := client.GetTaskStatuses(context.Background(), bmc.endpoint(), "root", "calvin", []string{"JID_1", "JID_2", "JID_3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+	if s := statuses["JID_3"]; !s.Missing {
+		t.Errorf("expected JID_3 to be missing, got %+v", s)
+	}
+	if s := statuses["JID_1"]; s.State != models.TaskRunning || s.PercentComplete != 50 || s.Message != "Downloading package." {
+		t.Errorf("unexpected JID_1 status: %+v", s)
+	}
+	if s := statuses["JID_2"]; s.State != models.TaskCompleted || s.Message != "Job completed successfully." {
+		t.Errorf("unexpected JID_2 status: %+v", s)
+	}
+}
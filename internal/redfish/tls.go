@@ -0,0 +1,107 @@
+package redfish
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// CertificateError is returned when a BMC's certificate fails verification
+type CertificateError struct {
+	Certificate *models.BMCCertificate
+}
+
+func (e *CertificateError) Error() string {
+	return fmt.Sprintf("BMC certificate rejected: %s", e.Certificate.Error)
+}
+
+// certificateCheck validates the certificate a BMC presents and records the
+// outcome. It does its own verification rather than leaving it to crypto/tls so
+// that certificates are still checked, and reported, when verification is disabled.
+type certificateCheck struct {
+	bmc   models.BMCEndpoint
+	roots *x509.CertPool
+
+	mu     sync.Mutex
+	result *models.BMCCertificate
+}
+
+// newCertificateCheck trusts the system roots plus the endpoint's CA bundle
+func newCertificateCheck(bmc models.BMCEndpoint) (*certificateCheck, error) {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if bmc.CABundle != "" && !roots.AppendCertsFromPEM([]byte(bmc.CABundle)) {
+		return nil, fmt.Errorf("no certificates found in CA bundle")
+	}
+	return &certificateCheck{bmc: bmc, roots: roots}, nil
+}
+
+// tlsConfig returns a client TLS configuration that verifies through the check
+func (c *certificateCheck) tlsConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true, // verified in VerifyConnection instead
+		VerifyConnection:   c.verify,
+	}
+}
+
+// verify is the tls.Config VerifyConnection callback
+func (c *certificateCheck) verify(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("BMC presented no certificate")
+	}
+	leaf := state.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       c.bmc.Host,
+		Roots:         c.roots,
+		Intermediates: intermediates,
+	})
+
+	result := &models.BMCCertificate{
+		Status:               models.CertificateVerified,
+		VerificationDisabled: c.bmc.DisableCertificateVerification,
+		SelfSigned:           isSelfSigned(leaf),
+		Subject:              leaf.Subject.String(),
+		Issuer:               leaf.Issuer.String(),
+		NotAfter:             leaf.NotAfter,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = models.CertificateRejected
+		if c.bmc.DisableCertificateVerification {
+			result.Status = models.CertificateUntrusted
+		}
+	}
+
+	c.mu.Lock()
+	c.result = result
+	c.mu.Unlock()
+
+	if result.Status == models.CertificateRejected {
+		return &CertificateError{Certificate: result}
+	}
+	return nil
+}
+
+// certificate returns the outcome of the last handshake, or nil if none completed
+func (c *certificateCheck) certificate() *models.BMCCertificate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result
+}
+
+// isSelfSigned reports whether a certificate is signed by its own key
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
@@ -0,0 +1,70 @@
+package redfish
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func TestClient_Connect_Certificates(t *testing.T) {
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/Systems":  `{"Members": []}`,
+		"/redfish/v1/Managers": `{"Members": []}`,
+	})
+	trusted := bmc.endpoint()
+	untrusted := trusted
+	untrusted.CABundle = ""
+
+	tests := []struct {
+		name         string
+		endpoint     models.BMCEndpoint
+		disabled     bool
+		wantStatus   models.CertificateStatus
+		wantRejected bool
+	}{
+		{"trusted CA bundle", trusted, false, models.CertificateVerified, false},
+		{"self-signed with verification", untrusted, false, models.CertificateRejected, true},
+		{"self-signed without verification", untrusted, true, models.CertificateUntrusted, false},
+	}
+
+	client := NewClient()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.endpoint.DisableCertificateVerification = tt.disabled
+			data, err := client.Collect(context.Background(), tt.endpoint, "root", "calvin", CollectOptions{})
+
+			var cert *models.BMCCertificate
+			var certErr *CertificateError
+			if tt.wantRejected {
+				if !errors.As(err, &certErr) {
+					t.Fatalf("expected a CertificateError, got %v", err)
+				}
+				cert = certErr.Certificate
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				cert = data.Certificate
+			}
+
+			if cert == nil || cert.Status != tt.wantStatus {
+				t.Fatalf("expected status %s, got %+v", tt.wantStatus, cert)
+			}
+			if cert.VerificationDisabled != tt.disabled || !cert.SelfSigned {
+				t.Errorf("unexpected certificate details: %+v", cert)
+			}
+			if (cert.Error != "") != (tt.wantStatus != models.CertificateVerified) {
+				t.Errorf("unexpected error %q for status %s", cert.Error, cert.Status)
+			}
+		})
+	}
+}
+
+func TestClient_Connect_InvalidCABundle(t *testing.T) {
+	endpoint := models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1", CABundle: "not a certificate"}
+	if _, err := NewClient().Connect(context.Background(), endpoint, "root", "calvin"); err == nil {
+		t.Error("expected error for invalid CA bundle")
+	}
+}
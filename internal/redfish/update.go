@@ -0,0 +1,73 @@
+package redfish
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ApplyTimeOnReset stages an update so the BMC applies it on the next host reboot
+const ApplyTimeOnReset = "OnReset"
+
+// simpleUpdatePayload is the UpdateService.SimpleUpdate request body, including the
+// @Redfish.OperationApplyTime annotation that gofish's parameters struct does not carry
+type simpleUpdatePayload struct {
+	ImageURI           string `json:"ImageURI"`
+	TransferProtocol   string `json:"TransferProtocol,omitempty"`
+	OperationApplyTime string `json:"@Redfish.OperationApplyTime,omitempty"`
+}
+
+// ScheduleFirmwareUpdate submits an UpdateService.SimpleUpdate for imageURI and
+// returns the ID of the task (on iDRAC, the JID) that the BMC created to track
+// it. Several updates to one BMC can share the session's login.
+func (s *Session) ScheduleFirmwareUpdate(imageURI, applyTime string) (string, error) {
+	updateService, err := s.service.UpdateService()
+	if err != nil {
+		return "", fmt.Errorf("failed to get update service: %w", err)
+	}
+
+	payload := simpleUpdatePayload{
+		ImageURI:           imageURI,
+		OperationApplyTime: applyTime,
+	}
+	if strings.HasPrefix(strings.ToLower(imageURI), "https://") {
+		payload.TransferProtocol = "HTTPS"
+	} else if strings.HasPrefix(strings.ToLower(imageURI), "http://") {
+		payload.TransferProtocol = "HTTP"
+	}
+
+	target := updateService.ODataID + "/Actions/UpdateService.SimpleUpdate"
+	resp, err := s.api.Post(target, payload)
+	if err != nil {
+		return "", fmt.Errorf("SimpleUpdate failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	taskID := TaskIDFromLocation(resp.Header.Get("Location"))
+	if taskID == "" {
+		return "", fmt.Errorf("SimpleUpdate accepted but no task location returned")
+	}
+
+	return taskID, nil
+}
+
+// TaskIDFromLocation extracts the task ID from a task monitor or task URI, e.g.
+// /redfish/v1/TaskService/Tasks/JID_123456789012 -> JID_123456789012
+func TaskIDFromLocation(location string) string {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return ""
+	}
+	// Some BMCs return an absolute URL
+	if i := strings.Index(location, "/redfish/"); i > 0 {
+		location = location[i:]
+	}
+	location = strings.TrimSuffix(location, "/")
+	// Task monitors live under the task URI on some implementations
+	location = strings.TrimSuffix(location, "/Monitor")
+	id := path.Base(location)
+	if id == "." || id == "/" {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,161 @@
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+const mockServiceRoot = `{
+	"@odata.id": "/redfish/v1/",
+	"Vendor": "Dell",
+	"Systems": {"@odata.id": "/redfish/v1/Systems"},
+	"Chassis": {"@odata.id": "/redfish/v1/Chassis"},
+	"Managers": {"@odata.id": "/redfish/v1/Managers"},
+	"UpdateService": {"@odata.id": "/redfish/v1/UpdateService"},
+	"TaskService": {"@odata.id": "/redfish/v1/TaskService"},
+	"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}
+}`
+
+// mockBMC is a minimal Redfish service for exercising the client
+type mockBMC struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	resources map[string]string
+	posts     map[string][]byte
+	gets      map[string]int // by path and query
+	sessions  int
+	logouts   int
+	onPost    func(w http.ResponseWriter, path string, body []byte) bool
+}
+
+func newMockBMC(t *testing.T, resources map[string]string) *mockBMC {
+	t.Helper()
+	m := &mockBMC{
+		resources: map[string]string{"/redfish/v1/": mockServiceRoot},
+		posts:     make(map[string][]byte),
+		gets:      make(map[string]int),
+	}
+	for k, v := range resources {
+		m.resources[k] = v
+	}
+	m.Server = httptest.NewTLSServer(http.HandlerFunc(m.serve))
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockBMC) serve(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := r.URL.Path
+	switch r.Method {
+	case http.MethodPost:
+		body, _ := io.ReadAll(r.Body)
+		if path == "/redfish/v1/SessionService/Sessions" {
+			m.sessions++
+			w.Header().Set("X-Auth-Token", "token")
+			w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		m.posts[path] = body
+		if m.onPost != nil && m.onPost(w, path, body) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if strings.HasPrefix(path, "/redfish/v1/SessionService/Sessions/") {
+			m.logouts++
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+		m.gets[path]++
+		body, ok := m.resources[path]
+		if !ok {
+			body, ok = m.resources[strings.TrimSuffix(path, "/")]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}
+}
+
+// endpoint returns the mock's address, trusting its self-signed certificate
+func (m *mockBMC) endpoint() models.BMCEndpoint {
+	host, port, _ := net.SplitHostPort(m.Listener.Addr().String())
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: m.Certificate().Raw})
+	return models.BMCEndpoint{Scheme: "https", Host: host, Port: port, CABundle: string(ca)}
+}
+
+func TestTaskIDFromLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{"/redfish/v1/TaskService/Tasks/JID_123456789012", "JID_123456789012"},
+		{"https://10.0.0.1/redfish/v1/TaskService/Tasks/JID_1/", "JID_1"},
+		{"/redfish/v1/TaskService/Tasks/7/Monitor", "7"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := TaskIDFromLocation(tt.location); got != tt.want {
+			t.Errorf("TaskIDFromLocation(%q) = %q, want %q", tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestSession_ScheduleFirmwareUpdate(t *testing.T) {
+	bmc := newMockBMC(t, map[string]string{
+		"/redfish/v1/UpdateService": `{
+			"@odata.id": "/redfish/v1/UpdateService",
+			"Id": "UpdateService",
+			"Actions": {"#UpdateService.SimpleUpdate": {"target": "/redfish/v1/UpdateService/Actions/UpdateService.SimpleUpdate"}}
+		}`,
+	})
+	bmc.onPost = func(w http.ResponseWriter, path string, body []byte) bool {
+		w.Header().Set("Location", "/redfish/v1/TaskService/Tasks/JID_123")
+		w.WriteHeader(http.StatusAccepted)
+		return true
+	}
+
+	s, err := NewClient().Connect(context.Background(), bmc.endpoint(), "root", "calvin")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer s.Close()
+	taskID, err := s.ScheduleFirmwareUpdate("https://downloads.dell.com/FOLDER/BIOS.EXE", ApplyTimeOnReset)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if taskID != "JID_123" {
+		t.Errorf("taskID = %q, want JID_123", taskID)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(bmc.posts["/redfish/v1/UpdateService/Actions/UpdateService.SimpleUpdate"], &payload); err != nil {
+		t.Fatalf("failed to decode SimpleUpdate payload: %v", err)
+	}
+	if payload["ImageURI"] != "https://downloads.dell.com/FOLDER/BIOS.EXE" {
+		t.Errorf("ImageURI = %q", payload["ImageURI"])
+	}
+	if payload["@Redfish.OperationApplyTime"] != "OnReset" {
+		t.Errorf("OperationApplyTime = %q, want OnReset", payload["@Redfish.OperationApplyTime"])
+	}
+}
@@ -0,0 +1,224 @@
+package redfish
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stmcginnis/gofish/redfish"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// Vendor abstracts the manufacturer-specific parts of a BMC: how firmware
+// components are named, where OEM resources live and which firmware catalog
+// applies. Standard Redfish handling is shared by all vendors.
+type Vendor interface {
+	// Name is a short identifier such as "dell" or "hpe"
+	Name() string
+	// ClassifyComponent maps a firmware inventory name to a component type
+	ClassifyComponent(name string) string
+	// EventLogServices lists the IDs of the LogServices, under the manager or the
+	// system, that hold event logs
+	EventLogServices() []string
+	// EventCategory returns the vendor's category for a log entry, or "" if none
+	EventCategory(entry *redfish.LogEntry) string
+	// MessageRegistryPrefix is the registry of MessageIds logged without a
+	// prefix, or "" to search every registry
+	MessageRegistryPrefix() string
+	// JobQueueURI returns the OEM job queue collection under a manager, or "" if
+	// the vendor only tracks jobs through the standard TaskService
+	JobQueueURI(managerURI string) string
+	// CatalogProvider names the firmware catalog for this vendor, or "" if none
+	CatalogProvider() string
+	// ComponentIdentity extracts the IDs used to match an installed component to a catalog package
+	ComponentIdentity(fw *redfish.SoftwareInventory) models.DeviceIdentity
+	// SystemID returns the vendor system ID used by the catalog, or "" if unknown
+	SystemID(sys *redfish.ComputerSystem) string
+}
+
+// VendorFor returns the driver for a Redfish Manufacturer string, falling back to
+// a generic standards-only driver for unrecognised manufacturers
+func VendorFor(manufacturer string) Vendor {
+	m := strings.ToLower(manufacturer)
+	switch {
+	case strings.Contains(m, "dell"):
+		return dellVendor{}
+	case strings.Contains(m, "hpe"), strings.Contains(m, "hewlett"):
+		return hpeVendor{}
+	case strings.Contains(m, "lenovo"):
+		return lenovoVendor{}
+	case strings.Contains(m, "supermicro"), strings.Contains(m, "super micro"):
+		return supermicroVendor{}
+	default:
+		return genericVendor{}
+	}
+}
+
+// genericVendor uses only standard Redfish resources
+type genericVendor struct{}
+
+func (genericVendor) Name() string { return "generic" }
+
+func (genericVendor) ClassifyComponent(name string) string { return classifyComponent(name) }
+
+func (genericVendor) EventLogServices() []string { return []string{"SEL", "Sel", "EventLog", "Log1"} }
+
+func (genericVendor) EventCategory(*redfish.LogEntry) string { return "" }
+
+func (genericVendor) MessageRegistryPrefix() string { return "" }
+
+func (genericVendor) JobQueueURI(string) string { return "" }
+
+func (genericVendor) CatalogProvider() string { return "" }
+
+func (genericVendor) ComponentIdentity(fw *redfish.SoftwareInventory) models.DeviceIdentity {
+	return models.DeviceIdentity{ComponentID: fw.SoftwareID}
+}
+
+func (genericVendor) SystemID(*redfish.ComputerSystem) string { return "" }
+
+// dellVendor handles iDRAC
+type dellVendor struct{ genericVendor }
+
+func (dellVendor) Name() string { return "dell" }
+
+// iDRAC keeps hardware events in the SEL and firmware updates, configuration
+// changes and part replacements in the Lifecycle Controller log
+func (dellVendor) EventLogServices() []string { return []string{"Sel", "SEL", "Lclog", "LC"} }
+
+func (dellVendor) MessageRegistryPrefix() string { return "IDRAC" }
+
+// EventCategory reads Oem.Dell.Category, such as "Updates" or "Configuration"
+// on Lifecycle Controller entries
+func (dellVendor) EventCategory(entry *redfish.LogEntry) string {
+	var oem struct {
+		Dell struct {
+			Category string
+		}
+	}
+	if len(entry.OEM) == 0 || json.Unmarshal(entry.OEM, &oem) != nil {
+		return ""
+	}
+	return oem.Dell.Category
+}
+
+func (dellVendor) JobQueueURI(managerURI string) string { return managerURI + "/Oem/Dell/Jobs/" }
+
+func (dellVendor) CatalogProvider() string { return models.CatalogProviderDell }
+
+// dellSoftwareInventory is the Oem.Dell.DellSoftwareInventory block on iDRAC firmware inventory
+type dellSoftwareInventory struct {
+	ComponentID string
+	VendorID    string
+	DeviceID    string
+	SubVendorID string
+	SubDeviceID string
+}
+
+// ComponentIdentity reads the Dell component and PCI IDs from the OEM block, which
+// iDRAC nests under DellSoftwareInventory on newer firmware. If the OEM block has no
+// component ID it falls back to SoftwareId, then the ID in "Installed-<componentID>-<version>".
+func (dellVendor) ComponentIdentity(fw *redfish.SoftwareInventory) models.DeviceIdentity {
+	var oem struct {
+		Dell struct {
+			dellSoftwareInventory
+			DellSoftwareInventory *dellSoftwareInventory
+		}
+	}
+	var inv dellSoftwareInventory
+	if len(fw.OEM) > 0 && json.Unmarshal(fw.OEM, &oem) == nil {
+		inv = oem.Dell.dellSoftwareInventory
+		if oem.Dell.DellSoftwareInventory != nil {
+			inv = *oem.Dell.DellSoftwareInventory
+		}
+	}
+
+	id := models.DeviceIdentity{
+		ComponentID: inv.ComponentID,
+		VendorID:    strings.ToUpper(inv.VendorID),
+		DeviceID:    strings.ToUpper(inv.DeviceID),
+		SubVendorID: strings.ToUpper(inv.SubVendorID),
+		SubDeviceID: strings.ToUpper(inv.SubDeviceID),
+	}
+	if id.ComponentID == "" && fw.SoftwareID != "" && fw.SoftwareID != "0" {
+		id.ComponentID = fw.SoftwareID
+	}
+	if id.ComponentID == "" {
+		if parts := strings.SplitN(fw.ID, "-", 3); len(parts) == 3 && parts[1] != "0" {
+			id.ComponentID = parts[1]
+		}
+	}
+	return id
+}
+
+// SystemID converts the decimal Oem.Dell.DellSystem.SystemID into the 4-digit hex
+// form the catalog uses, e.g. 1814 -> "0716"
+func (dellVendor) SystemID(sys *redfish.ComputerSystem) string {
+	var oem struct {
+		Dell struct {
+			DellSystem struct {
+				SystemID int
+			}
+		}
+	}
+	if len(sys.OEM) == 0 || json.Unmarshal(sys.OEM, &oem) != nil || oem.Dell.DellSystem.SystemID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%04X", oem.Dell.DellSystem.SystemID)
+}
+
+// hpeVendor handles iLO
+type hpeVendor struct{ genericVendor }
+
+func (hpeVendor) Name() string { return "hpe" }
+
+func (hpeVendor) ClassifyComponent(name string) string {
+	switch {
+	case contains(name, "System ROM", "Redundant System ROM"):
+		return "BIOS"
+	case contains(name, "iLO"):
+		return "BMC"
+	case contains(name, "Smart Array", "SmartRAID", "HPE MR"):
+		return "Storage"
+	case contains(name, "Power Management Controller"):
+		return "Power"
+	case contains(name, "System Programmable Logic Device"):
+		return "CPLD"
+	default:
+		return classifyComponent(name)
+	}
+}
+
+// iLO exposes the Integrated Event Log under the manager and the Integrated
+// Management Log under the system
+func (hpeVendor) EventLogServices() []string { return []string{"IEL", "IML"} }
+
+// lenovoVendor handles XClarity Controller
+type lenovoVendor struct{ genericVendor }
+
+func (lenovoVendor) Name() string { return "lenovo" }
+
+func (lenovoVendor) ClassifyComponent(name string) string {
+	switch {
+	case contains(name, "UEFI"):
+		return "BIOS"
+	case contains(name, "XCC", "BMC"):
+		return "BMC"
+	case contains(name, "LXPM"):
+		return "Other"
+	case contains(name, "ThinkSystem RAID"):
+		return "Storage"
+	default:
+		return classifyComponent(name)
+	}
+}
+
+func (lenovoVendor) EventLogServices() []string { return []string{"PlatformLog", "EventLog"} }
+
+// supermicroVendor handles Supermicro BMCs
+type supermicroVendor struct{ genericVendor }
+
+func (supermicroVendor) Name() string { return "supermicro" }
+
+func (supermicroVendor) EventLogServices() []string { return []string{"Log1", "SEL"} }
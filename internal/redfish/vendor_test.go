@@ -0,0 +1,125 @@
+package redfish
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func TestVendorFor(t *testing.T) {
+	tests := []struct {
+		manufacturer string
+		expected     string
+	}{
+		{"Dell Inc.", "dell"},
+		{"HPE", "hpe"},
+		{"Hewlett Packard Enterprise", "hpe"},
+		{"Lenovo", "lenovo"},
+		{"Supermicro", "supermicro"},
+		{"Super Micro Computer, Inc.", "supermicro"},
+		{"", "generic"},
+		{"Contoso", "generic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.manufacturer, func(t *testing.T) {
+			if got := VendorFor(tt.manufacturer).Name(); got != tt.expected {
+				t.Errorf("VendorFor(%q) = %q, want %q", tt.manufacturer, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVendor_ClassifyComponent(t *testing.T) {
+	tests := []struct {
+		vendor   string
+		name     string
+		expected string
+	}{
+		{"Dell Inc.", "Integrated Dell Remote Access Controller (iDRAC)", "BMC"},
+		{"Dell Inc.", "PERC H740P Mini", "Storage"},
+		{"HPE", "System ROM", "BIOS"},
+		{"HPE", "iLO 5", "BMC"},
+		{"HPE", "HPE Smart Array P408i-a SR Gen10", "Storage"},
+		{"HPE", "Power Management Controller Firmware", "Power"},
+		{"Lenovo", "UEFI", "BIOS"},
+		{"Lenovo", "XCC Primary", "BMC"},
+		{"Lenovo", "ThinkSystem RAID 930-8i", "Storage"},
+		{"Supermicro", "BIOS", "BIOS"},
+		{"Supermicro", "BMC", "BMC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vendor+"/"+tt.name, func(t *testing.T) {
+			if got := VendorFor(tt.vendor).ClassifyComponent(tt.name); got != tt.expected {
+				t.Errorf("ClassifyComponent(%q) = %q, want %q", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVendor_JobQueueURI(t *testing.T) {
+	manager := "/redfish/v1/Managers/iDRAC.Embedded.1"
+	if got := VendorFor("Dell Inc.").JobQueueURI(manager); got != manager+"/Oem/Dell/Jobs/" {
+		t.Errorf("unexpected Dell job queue: %q", got)
+	}
+	if got := VendorFor("HPE").JobQueueURI("/redfish/v1/Managers/1"); got != "" {
+		t.Errorf("expected no HPE job queue, got %q", got)
+	}
+	if VendorFor("Lenovo").CatalogProvider() != "" {
+		t.Error("expected no catalog provider for Lenovo")
+	}
+}
+
+func TestDellVendor_ComponentIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		fw   redfish.SoftwareInventory
+		want models.DeviceIdentity
+	}{
+		{
+			name: "nested oem block",
+			fw: redfish.SoftwareInventory{
+				OEM: json.RawMessage(`{"Dell": {"DellSoftwareInventory": {"ComponentID": "104999", "VendorID": "8086", "DeviceID": "1572", "SubVendorID": "8086", "SubDeviceID": "0006"}}}`),
+			},
+			want: models.DeviceIdentity{ComponentID: "104999", VendorID: "8086", DeviceID: "1572", SubVendorID: "8086", SubDeviceID: "0006"},
+		},
+		{
+			name: "flat oem block",
+			fw:   redfish.SoftwareInventory{OEM: json.RawMessage(`{"Dell": {"ComponentID": "159"}}`)},
+			want: models.DeviceIdentity{ComponentID: "159"},
+		},
+		{
+			name: "software id",
+			fw:   redfish.SoftwareInventory{SoftwareID: "25227"},
+			want: models.DeviceIdentity{ComponentID: "25227"},
+		},
+		{
+			name: "installed id",
+			fw:   redfish.SoftwareInventory{Entity: common.Entity{ID: "Installed-159-2.19.1"}, SoftwareID: "0"},
+			want: models.DeviceIdentity{ComponentID: "159"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (dellVendor{}).ComponentIdentity(&tt.fw); got != tt.want {
+				t.Errorf("ComponentIdentity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDellVendor_SystemID(t *testing.T) {
+	sys := &redfish.ComputerSystem{OEM: json.RawMessage(`{"Dell": {"DellSystem": {"SystemID": 1814}}}`)}
+	if got := (dellVendor{}).SystemID(sys); got != "0716" {
+		t.Errorf("SystemID() = %q, want 0716", got)
+	}
+	if got := (dellVendor{}).SystemID(&redfish.ComputerSystem{}); got != "" {
+		t.Errorf("SystemID() without OEM = %q, want empty", got)
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+// ChangeStore provides thread-safe storage for change events, oldest first
+type ChangeStore struct {
+	mu      sync.RWMutex
+	changes []models.ChangeEvent
+	nextID  uint64
+	maxSize int
+}
+
+// NewChangeStore creates a new ChangeStore with max capacity
+func NewChangeStore(maxSize int) *ChangeStore {
+	return &ChangeStore{
+		changes: make([]models.ChangeEvent, 0),
+		maxSize: maxSize,
+	}
+}
+
+// AddChanges appends change events, assigning each an increasing ID
+func (s *ChangeStore) AddChanges(changes []models.ChangeEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range changes {
+		s.nextID++
+		c.ID = strconv.FormatUint(s.nextID, 10)
+		s.changes = append(s.changes, c)
+	}
+
+	// Trim to max size, keeping newest
+	if len(s.changes) > s.maxSize {
+		s.changes = slices.Clone(s.changes[len(s.changes)-s.maxSize:])
+	}
+}
+
+// DeleteNode removes all change events for a node
+func (s *ChangeStore) DeleteNode(namespace, nodeName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.changes = slices.DeleteFunc(s.changes, func(c models.ChangeEvent) bool {
+		return c.Namespace == namespace && c.NodeName == nodeName
+	})
+}
+
+// ListChanges returns change events newest first, optionally filtered by
+// namespace, node and type
+func (s *ChangeStore) ListChanges(limit int, namespace, nodeName string, changeType models.ChangeType) []models.ChangeEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.ChangeEvent, 0)
+	for i := len(s.changes) - 1; i >= 0; i-- {
+		c := s.changes[i]
+		if namespace != "" && c.Namespace != namespace {
+			continue
+		}
+		if nodeName != "" && c.NodeName != nodeName {
+			continue
+		}
+		if changeType != "" && c.Type != changeType {
+			continue
+		}
+		result = append(result, c)
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result
+}
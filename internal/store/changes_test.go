@@ -0,0 +1,53 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+)
+
+func TestChangeStore(t *testing.T) {
+	s := NewChangeStore(3)
+
+	s.AddChanges([]models.ChangeEvent{
+		{Type: models.ChangeHealth, NodeName: "worker-0", Namespace: "ns-a"},
+		{Type: models.ChangeFirmwareVersion, NodeName: "worker-0", Namespace: "ns-a"},
+	})
+	s.AddChanges([]models.ChangeEvent{
+		{Type: models.ChangeHealth, NodeName: "worker-0", Namespace: "ns-b"},
+		{Type: models.ChangeHealth, NodeName: "worker-1", Namespace: "ns-a"},
+	})
+
+	// The oldest change is trimmed; the rest are listed newest first
+	changes := s.ListChanges(0, "", "", "")
+	if len(changes) != 3 || changes[0].ID != "4" || changes[2].ID != "2" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	tests := []struct {
+		name       string
+		limit      int
+		namespace  string
+		node       string
+		changeType models.ChangeType
+		want       int
+	}{
+		{"by namespace", 0, "ns-a", "", "", 2},
+		{"by node", 0, "", "worker-0", "", 2},
+		{"by type", 0, "", "", models.ChangeHealth, 2},
+		{"by namespace and node", 0, "ns-b", "worker-0", "", 1},
+		{"limited", 1, "", "", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.ListChanges(tt.limit, tt.namespace, tt.node, tt.changeType); len(got) != tt.want {
+				t.Errorf("expected %d changes, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	s.DeleteNode("ns-a", "worker-0")
+	if changes := s.ListChanges(0, "", "worker-0", ""); len(changes) != 1 || changes[0].Namespace != "ns-b" {
+		t.Errorf("delete should only remove ns-a/worker-0, left %+v", changes)
+	}
+}
@@ -1,24 +1,84 @@
 package store
 
 import (
+	"container/heap"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
 
-// EventStore provides thread-safe storage for health events
+// DefaultNodeQuota is the default number of events kept per node
+const DefaultNodeQuota = 200
+
+// ErrInvalidCursor is returned for a page cursor the store did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EventStore provides thread-safe storage for health events. Each node's
+// events are kept in time order and limited to a quota, so a chatty node
+// cannot evict every other node's history; the store as a whole is limited to
+// its max size by dropping the oldest events of the nodes holding the most.
+// An event read from the same BMC log entry more than once is only stored the
+// first time.
 type EventStore struct {
-	mu      sync.RWMutex
-	events  []models.HealthEvent
-	maxSize int
+	mu        sync.RWMutex
+	nodes     map[string]*nodeEvents // by node key
+	total     int
+	nextSeq   uint64
+	keys      map[string]struct{}
+	marks     map[string]map[string]models.EventMark // by node key, then log
+	maxSize   int
+	nodeQuota int
+}
+
+// storedEvent is an event with the insertion sequence that orders events
+// logged at the same time
+type storedEvent struct {
+	event models.HealthEvent
+	seq   uint64
+}
+
+// before orders events by timestamp, then insertion
+func (e storedEvent) before(ts time.Time, seq uint64) bool {
+	if c := e.event.Timestamp.Compare(ts); c != 0 {
+		return c < 0
+	}
+	return e.seq < seq
+}
+
+// nodeEvents holds one node's events, oldest first
+type nodeEvents struct {
+	namespace string
+	name      string
+	events    []storedEvent
 }
 
 // NewEventStore creates a new EventStore with max capacity
 func NewEventStore(maxSize int) *EventStore {
 	return &EventStore{
-		events:  make([]models.HealthEvent, 0),
-		maxSize: maxSize,
+		nodes:     make(map[string]*nodeEvents),
+		keys:      make(map[string]struct{}),
+		marks:     make(map[string]map[string]models.EventMark),
+		maxSize:   maxSize,
+		nodeQuota: DefaultNodeQuota,
+	}
+}
+
+// SetNodeQuota sets the number of events kept per node
+func (s *EventStore) SetNodeQuota(quota int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodeQuota = quota
+	for _, n := range s.nodes {
+		s.trimNode(n)
 	}
 }
 
@@ -27,52 +87,249 @@ func (s *EventStore) AddEvent(event models.HealthEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.events = append(s.events, event)
-
-	// Trim to max size, keeping newest
-	if len(s.events) > s.maxSize {
-		s.events = s.events[len(s.events)-s.maxSize:]
-	}
+	s.add(event)
+	s.trim()
 }
 
-// AddEvents adds multiple events to the store
-func (s *EventStore) AddEvents(nodeName string, events []models.HealthEvent) {
+// AddEvents adds multiple events from one node to the store
+func (s *EventStore) AddEvents(namespace, nodeName string, events []models.HealthEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	for _, e := range events {
 		e.NodeName = nodeName
-		s.events = append(s.events, e)
+		e.Namespace = namespace
+		s.add(e)
+	}
+	s.trim()
+}
+
+// add inserts an event in time order unless it is already stored
+func (s *EventStore) add(e models.HealthEvent) {
+	key := e.Key()
+	if _, ok := s.keys[key]; ok {
+		return
+	}
+	s.keys[key] = struct{}{}
+
+	nodeKey := models.NodeKey(e.Namespace, e.NodeName)
+	n, ok := s.nodes[nodeKey]
+	if !ok {
+		n = &nodeEvents{namespace: e.Namespace, name: e.NodeName}
+		s.nodes[nodeKey] = n
 	}
 
-	// Trim to max size
-	if len(s.events) > s.maxSize {
-		s.events = s.events[len(s.events)-s.maxSize:]
+	s.nextSeq++
+	stored := storedEvent{event: e, seq: s.nextSeq}
+	i := sort.Search(len(n.events), func(i int) bool {
+		return !n.events[i].before(e.Timestamp, stored.seq)
+	})
+	n.events = slices.Insert(n.events, i, stored)
+	s.total++
+	s.trimNode(n)
+}
+
+// trimNode drops a node's oldest events beyond its quota
+func (s *EventStore) trimNode(n *nodeEvents) {
+	if s.nodeQuota > 0 && len(n.events) > s.nodeQuota {
+		s.dropOldest(n, len(n.events)-s.nodeQuota)
+	}
+}
+
+// trim drops events beyond max size, oldest first from the nodes holding the most
+func (s *EventStore) trim() {
+	for s.total > s.maxSize {
+		var largest *nodeEvents
+		for _, n := range s.nodes {
+			if largest == nil || len(n.events) > len(largest.events) ||
+				len(n.events) == len(largest.events) && n.events[0].before(largest.events[0].event.Timestamp, largest.events[0].seq) {
+				largest = n
+			}
+		}
+		s.dropOldest(largest, 1)
+	}
+}
+
+func (s *EventStore) dropOldest(n *nodeEvents, count int) {
+	for _, e := range n.events[:count] {
+		delete(s.keys, e.event.Key())
+	}
+	n.events = slices.Delete(n.events, 0, count)
+	s.total -= count
+	if len(n.events) == 0 {
+		delete(s.nodes, models.NodeKey(n.namespace, n.name))
 	}
 }
 
-// ListEvents returns events, optionally filtered by node
-func (s *EventStore) ListEvents(limit int, nodeName string) []models.HealthEvent {
+// Marks returns the high-water marks of a node's BMC logs, see redfish.Session.Events
+func (s *EventStore) Marks(namespace, nodeName string) map[string]models.EventMark {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return maps.Clone(s.marks[models.NodeKey(namespace, nodeName)])
+}
+
+// SetMarks records the high-water marks of a node's BMC logs after a poll
+func (s *EventStore) SetMarks(namespace, nodeName string, marks map[string]models.EventMark) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marks[models.NodeKey(namespace, nodeName)] = maps.Clone(marks)
+}
 
-	result := make([]models.HealthEvent, 0)
+// DeleteNode removes all events and log marks for a node
+func (s *EventStore) DeleteNode(namespace, nodeName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n, ok := s.nodes[models.NodeKey(namespace, nodeName)]; ok {
+		s.dropOldest(n, len(n.events))
+	}
+	delete(s.marks, models.NodeKey(namespace, nodeName))
+}
 
-	for _, e := range s.events {
-		if nodeName != "" && e.NodeName != nodeName {
+// EventQuery selects events from an EventStore. Zero values do not filter.
+type EventQuery struct {
+	Namespace  string
+	NodeName   string
+	Since      time.Time // events at or after
+	Until      time.Time // events at or before
+	Severities []models.HealthStatus
+	Limit      int
+	Cursor     string // NextCursor of the previous page
+}
+
+// EventPage is one page of events, newest first
+type EventPage struct {
+	Events []models.HealthEvent `json:"events"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListEvents returns events, optionally filtered by namespace and node
+func (s *EventStore) ListEvents(limit int, namespace, nodeName string) []models.HealthEvent {
+	page, _ := s.QueryEvents(EventQuery{Namespace: namespace, NodeName: nodeName, Limit: limit})
+	return page.Events
+}
+
+// QueryEvents returns a page of events newest first. Each node's events are
+// searched by time and the nodes merged, so the store is never sorted as a whole.
+func (s *EventStore) QueryEvents(q EventQuery) (EventPage, error) {
+	var after *cursor
+	if q.Cursor != "" {
+		c, err := parseCursor(q.Cursor)
+		if err != nil {
+			return EventPage{}, err
+		}
+		after = &c
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	merge := &eventMerge{}
+	for _, n := range s.nodes {
+		if q.Namespace != "" && n.namespace != q.Namespace || q.NodeName != "" && n.name != q.NodeName {
 			continue
 		}
-		result = append(result, e)
+		if lo, hi := n.window(q.Since, q.Until, after); lo < hi {
+			merge.ranges = append(merge.ranges, nodeRange{events: n.events, lo: lo, next: hi - 1})
+		}
 	}
+	heap.Init(merge)
 
-	// Sort by timestamp descending (newest first)
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Timestamp.After(result[j].Timestamp)
-	})
+	page := EventPage{Events: make([]models.HealthEvent, 0)}
+	var last storedEvent
+	for merge.Len() > 0 {
+		e := merge.pop()
+		if len(q.Severities) > 0 && !slices.Contains(q.Severities, e.event.Severity) {
+			continue
+		}
+		// Only a matching event past the limit makes another page
+		if q.Limit > 0 && len(page.Events) == q.Limit {
+			page.NextCursor = cursor{ts: last.event.Timestamp, seq: last.seq}.String()
+			break
+		}
+		page.Events = append(page.Events, e.event)
+		last = e
+	}
+	return page, nil
+}
 
-	if limit > 0 && len(result) > limit {
-		result = result[:limit]
+// window returns the index range of a node's events within the query bounds
+func (n *nodeEvents) window(since, until time.Time, after *cursor) (lo, hi int) {
+	hi = len(n.events)
+	if !since.IsZero() {
+		lo = sort.Search(len(n.events), func(i int) bool { return !n.events[i].event.Timestamp.Before(since) })
+	}
+	if !until.IsZero() {
+		hi = sort.Search(len(n.events), func(i int) bool { return n.events[i].event.Timestamp.After(until) })
+	}
+	if after != nil {
+		hi = min(hi, sort.Search(len(n.events), func(i int) bool { return !n.events[i].before(after.ts, after.seq) }))
 	}
+	return lo, hi
+}
+
+// cursor is the position of the last event of a page
+type cursor struct {
+	ts  time.Time
+	seq uint64
+}
+
+func (c cursor) String() string {
+	raw := strconv.FormatInt(c.ts.UnixNano(), 10) + "." + strconv.FormatUint(c.seq, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func parseCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	tsPart, seqPart, ok := strings.Cut(string(raw), ".")
+	ts, tsErr := strconv.ParseInt(tsPart, 10, 64)
+	seq, seqErr := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil || !ok || tsErr != nil || seqErr != nil {
+		return cursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	return cursor{ts: time.Unix(0, ts), seq: seq}, nil
+}
+
+// nodeRange walks one node's events in a window, newest first
+type nodeRange struct {
+	events []storedEvent
+	lo     int
+	next   int
+}
 
-	return result
+// eventMerge is a max-heap of node ranges by their next event, merging the
+// nodes' events newest first
+type eventMerge struct {
+	ranges []nodeRange
+}
+
+func (m *eventMerge) Len() int { return len(m.ranges) }
+
+func (m *eventMerge) Less(i, j int) bool {
+	a, b := m.ranges[i].events[m.ranges[i].next], m.ranges[j].events[m.ranges[j].next]
+	return b.before(a.event.Timestamp, a.seq)
+}
+
+func (m *eventMerge) Swap(i, j int) { m.ranges[i], m.ranges[j] = m.ranges[j], m.ranges[i] }
+
+func (m *eventMerge) Push(x any) { m.ranges = append(m.ranges, x.(nodeRange)) }
+
+func (m *eventMerge) Pop() any {
+	last := m.ranges[len(m.ranges)-1]
+	m.ranges = m.ranges[:len(m.ranges)-1]
+	return last
+}
+
+// pop returns the newest remaining event
+func (m *eventMerge) pop() storedEvent {
+	r := &m.ranges[0]
+	e := r.events[r.next]
+	r.next--
+	if r.next < r.lo {
+		heap.Pop(m)
+	} else {
+		heap.Fix(m, 0)
+	}
+	return e
 }
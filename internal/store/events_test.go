@@ -1,6 +1,9 @@
 package store
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -19,7 +22,7 @@ func TestEventStore_AddAndList(t *testing.T) {
 	}
 
 	s.AddEvent(event)
-	events := s.ListEvents(10, "")
+	events := s.ListEvents(10, "", "")
 
 	if len(events) != 1 {
 		t.Errorf("expected 1 event, got %d", len(events))
@@ -36,13 +39,31 @@ func TestEventStore_FilterByNode(t *testing.T) {
 	s.AddEvent(models.HealthEvent{ID: "2", NodeName: "worker-1", Message: "Event 2"})
 	s.AddEvent(models.HealthEvent{ID: "3", NodeName: "worker-0", Message: "Event 3"})
 
-	events := s.ListEvents(10, "worker-0")
+	events := s.ListEvents(10, "", "worker-0")
 
 	if len(events) != 2 {
 		t.Errorf("expected 2 events for worker-0, got %d", len(events))
 	}
 }
 
+func TestEventStore_SameNameInNamespaces(t *testing.T) {
+	s := NewEventStore(100)
+
+	s.AddEvents("cluster-a", "master-0", []models.HealthEvent{{ID: "1", Message: "Fan failed"}})
+	s.AddEvents("cluster-b", "master-0", []models.HealthEvent{{ID: "1", Message: "PSU failed"}})
+
+	events := s.ListEvents(10, "cluster-b", "master-0")
+	if len(events) != 1 || events[0].Message != "PSU failed" || events[0].Namespace != "cluster-b" {
+		t.Errorf("unexpected events for cluster-b/master-0: %+v", events)
+	}
+
+	s.DeleteNode("cluster-a", "master-0")
+	events = s.ListEvents(10, "", "master-0")
+	if len(events) != 1 || events[0].Namespace != "cluster-b" {
+		t.Errorf("delete should only remove cluster-a/master-0, left %+v", events)
+	}
+}
+
 func TestEventStore_MaxSize(t *testing.T) {
 	s := NewEventStore(2)
 
@@ -50,7 +71,7 @@ func TestEventStore_MaxSize(t *testing.T) {
 	s.AddEvent(models.HealthEvent{ID: "2", Message: "Second"})
 	s.AddEvent(models.HealthEvent{ID: "3", Message: "Third"})
 
-	events := s.ListEvents(10, "")
+	events := s.ListEvents(10, "", "")
 
 	if len(events) != 2 {
 		t.Errorf("expected 2 events (max size), got %d", len(events))
@@ -62,3 +83,195 @@ func TestEventStore_MaxSize(t *testing.T) {
 		}
 	}
 }
+
+func TestEventStore_Deduplicates(t *testing.T) {
+	s := NewEventStore(100)
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sel := []models.HealthEvent{
+		{ID: "1", Timestamp: created, Message: "Fan failed", BMC: "bmc-0", LogService: "Sel", Created: "2024-01-01T00:00:00Z"},
+		{ID: "2", Timestamp: created, Message: "PSU failed", BMC: "bmc-0", LogService: "Sel", Created: "2024-01-01T00:00:00Z"},
+	}
+
+	// Repeated polls reading the same SEL entries leave the store unchanged
+	for range 3 {
+		s.AddEvents("ns-a", "worker-0", sel)
+	}
+	if events := s.ListEvents(0, "", ""); len(events) != 2 {
+		t.Fatalf("expected 2 events after repeated polls, got %+v", events)
+	}
+
+	// The same entry ID from another node, BMC or creation time is a different event
+	s.AddEvents("ns-a", "worker-1", sel[:1])
+	reused := sel[0]
+	reused.Created = "2024-02-01T00:00:00Z"
+	other := sel[0]
+	other.BMC = "bmc-1"
+	s.AddEvents("ns-a", "worker-0", []models.HealthEvent{reused, other})
+	if events := s.ListEvents(0, "", ""); len(events) != 5 {
+		t.Errorf("expected 5 events, got %+v", events)
+	}
+}
+
+func TestEventStore_Marks(t *testing.T) {
+	s := NewEventStore(100)
+	marks := map[string]models.EventMark{"bmc-0/Sel": {Created: time.Now(), IDs: []string{"2"}}}
+
+	s.SetMarks("ns-a", "worker-0", marks)
+	if got := s.Marks("ns-a", "worker-0"); len(got) != 1 || got["bmc-0/Sel"].IDs[0] != "2" {
+		t.Fatalf("unexpected marks: %+v", got)
+	}
+	if got := s.Marks("ns-b", "worker-0"); len(got) != 0 {
+		t.Errorf("expected no marks for another namespace, got %+v", got)
+	}
+
+	s.AddEvents("ns-a", "worker-0", []models.HealthEvent{{ID: "1", BMC: "bmc-0", LogService: "Sel"}})
+	s.DeleteNode("ns-a", "worker-0")
+	if got := s.Marks("ns-a", "worker-0"); len(got) != 0 {
+		t.Errorf("expected marks to be removed with the node, got %+v", got)
+	}
+	// A node that comes back starts over
+	s.AddEvents("ns-a", "worker-0", []models.HealthEvent{{ID: "1", BMC: "bmc-0", LogService: "Sel"}})
+	if events := s.ListEvents(0, "", ""); len(events) != 1 {
+		t.Errorf("expected the re-added event to be stored, got %+v", events)
+	}
+}
+
+func TestEventStore_NodeQuota(t *testing.T) {
+	s := NewEventStore(10)
+	s.SetNodeQuota(3)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.AddEvents("ns", "quiet", []models.HealthEvent{{ID: "q1", Timestamp: base}})
+	for i := range 20 {
+		s.AddEvents("ns", "chatty", []models.HealthEvent{{ID: fmt.Sprint(i), Timestamp: base.Add(time.Duration(i+1) * time.Minute)}})
+	}
+
+	if events := s.ListEvents(0, "ns", "quiet"); len(events) != 1 {
+		t.Errorf("a chatty node evicted the quiet node's history: %+v", events)
+	}
+	events := s.ListEvents(0, "ns", "chatty")
+	if len(events) != 3 || events[0].ID != "19" || events[2].ID != "17" {
+		t.Errorf("expected the newest 3 chatty events, got %+v", events)
+	}
+}
+
+func TestEventStore_GlobalCapEvictsLargestNode(t *testing.T) {
+	s := NewEventStore(4)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.AddEvents("ns", "a", []models.HealthEvent{{ID: "a1", Timestamp: base}})
+	s.AddEvents("ns", "b", []models.HealthEvent{
+		{ID: "b1", Timestamp: base.Add(time.Minute)},
+		{ID: "b2", Timestamp: base.Add(2 * time.Minute)},
+		{ID: "b3", Timestamp: base.Add(3 * time.Minute)},
+		{ID: "b4", Timestamp: base.Add(4 * time.Minute)},
+	})
+
+	if events := s.ListEvents(0, "", "a"); len(events) != 1 {
+		t.Errorf("expected node a to keep its only event, got %+v", events)
+	}
+	events := s.ListEvents(0, "", "b")
+	if len(events) != 3 || events[2].ID != "b2" {
+		t.Errorf("expected b's oldest event to be dropped, got %+v", events)
+	}
+}
+
+func TestEventStore_QueryEvents(t *testing.T) {
+	s := NewEventStore(100)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+
+	// Added out of order to check events are indexed by time
+	s.AddEvents("ns", "worker-0", []models.HealthEvent{
+		{ID: "w0-3", Timestamp: at(3), Severity: models.HealthCritical},
+		{ID: "w0-1", Timestamp: at(1), Severity: models.HealthWarning},
+		{ID: "w0-5", Timestamp: at(5), Severity: models.HealthOK},
+	})
+	s.AddEvents("ns", "worker-1", []models.HealthEvent{
+		{ID: "w1-2", Timestamp: at(2), Severity: models.HealthCritical},
+		{ID: "w1-4", Timestamp: at(4), Severity: models.HealthWarning},
+	})
+	s.AddEvents("other", "worker-0", []models.HealthEvent{{ID: "o-6", Timestamp: at(6), Severity: models.HealthCritical}})
+
+	tests := []struct {
+		name  string
+		query EventQuery
+		want  []string
+	}{
+		{name: "all newest first", query: EventQuery{}, want: []string{"o-6", "w0-5", "w1-4", "w0-3", "w1-2", "w0-1"}},
+		{name: "namespace", query: EventQuery{Namespace: "ns"}, want: []string{"w0-5", "w1-4", "w0-3", "w1-2", "w0-1"}},
+		{name: "node", query: EventQuery{Namespace: "ns", NodeName: "worker-0"}, want: []string{"w0-5", "w0-3", "w0-1"}},
+		{name: "since and until inclusive", query: EventQuery{Since: at(2), Until: at(4)}, want: []string{"w1-4", "w0-3", "w1-2"}},
+		{name: "severity", query: EventQuery{Severities: []models.HealthStatus{models.HealthCritical}}, want: []string{"o-6", "w0-3", "w1-2"}},
+		{name: "severity and time", query: EventQuery{Since: at(3), Severities: []models.HealthStatus{models.HealthCritical, models.HealthWarning}}, want: []string{"o-6", "w1-4", "w0-3"}},
+		{name: "nothing in range", query: EventQuery{Since: at(10)}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.QueryEvents(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := eventIDs(page.Events); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("expected no next cursor without a limit, got %q", page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestEventStore_QueryEventsPages(t *testing.T) {
+	s := NewEventStore(100)
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Events logged at the same time still page in a stable order
+	for i := range 5 {
+		s.AddEvents("ns", fmt.Sprintf("worker-%d", i%2), []models.HealthEvent{{ID: fmt.Sprint(i), Timestamp: ts}})
+	}
+
+	var ids []string
+	query := EventQuery{Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("paging did not end")
+		}
+		page, err := s.QueryEvents(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, eventIDs(page.Events)...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	if want := []string{"4", "3", "2", "1", "0"}; !slices.Equal(ids, want) {
+		t.Errorf("paged %v, want %v", ids, want)
+	}
+
+	// A page ending on the last matching event has no next page, even with
+	// events of other severities after it
+	s.AddEvents("ns", "worker-2", []models.HealthEvent{{ID: "critical", Timestamp: ts.Add(time.Minute), Severity: models.HealthCritical}})
+	page, err := s.QueryEvents(EventQuery{Limit: 1, Severities: []models.HealthStatus{models.HealthCritical}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := eventIDs(page.Events); !slices.Equal(got, []string{"critical"}) || page.NextCursor != "" {
+		t.Errorf("expected only the critical event and no next cursor, got %v and %q", got, page.NextCursor)
+	}
+
+	if _, err := s.QueryEvents(EventQuery{Cursor: "not-a-cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func eventIDs(events []models.HealthEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
@@ -1,15 +1,26 @@
 package store
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
 
+var (
+	// ErrNodeNotFound is returned when no node matches a reference
+	ErrNodeNotFound = errors.New("node not found")
+	// ErrAmbiguousNode is returned when a bare node name exists in several namespaces
+	ErrAmbiguousNode = errors.New("node name is ambiguous")
+)
+
 // Store provides thread-safe in-memory storage for node firmware data
 type Store struct {
 	mu    sync.RWMutex
-	nodes map[string]models.Node
+	nodes map[string]models.Node // keyed by models.NodeKey
 }
 
 // New creates a new Store
@@ -23,17 +34,57 @@ func New() *Store {
 func (s *Store) SetNode(node models.Node) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.nodes[node.Name] = node
+	s.nodes[node.Key()] = node
 }
 
-// GetNode retrieves a node by name
-func (s *Store) GetNode(name string) (models.Node, bool) {
+// GetNode retrieves a node by namespace and name
+func (s *Store) GetNode(namespace, name string) (models.Node, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	node, ok := s.nodes[name]
+	node, ok := s.nodes[models.NodeKey(namespace, name)]
 	return node, ok
 }
 
+// FindNodes returns the nodes with the given name in any namespace
+func (s *Store) FindNodes(name string) []models.Node {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var nodes []models.Node
+	for _, node := range s.nodes {
+		if node.Name == name {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// ResolveNode looks up a node by "namespace/name", or by bare name as long as
+// only one namespace has a node with that name
+func (s *Store) ResolveNode(ref string) (models.Node, error) {
+	if namespace, name, ok := strings.Cut(ref, "/"); ok {
+		node, found := s.GetNode(namespace, name)
+		if !found {
+			return models.Node{}, fmt.Errorf("%w: %s", ErrNodeNotFound, ref)
+		}
+		return node, nil
+	}
+
+	nodes := s.FindNodes(ref)
+	switch len(nodes) {
+	case 0:
+		return models.Node{}, fmt.Errorf("%w: %s", ErrNodeNotFound, ref)
+	case 1:
+		return nodes[0], nil
+	}
+	namespaces := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		namespaces = append(namespaces, node.Namespace)
+	}
+	sort.Strings(namespaces)
+	return models.Node{}, fmt.Errorf("%w: %s exists in namespaces %s", ErrAmbiguousNode, ref, strings.Join(namespaces, ", "))
+}
+
 // ListNodes returns all nodes
 func (s *Store) ListNodes() []models.Node {
 	s.mu.RLock()
@@ -47,10 +98,10 @@ func (s *Store) ListNodes() []models.Node {
 }
 
 // DeleteNode removes a node from the store
-func (s *Store) DeleteNode(name string) {
+func (s *Store) DeleteNode(namespace, name string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	delete(s.nodes, name)
+	delete(s.nodes, models.NodeKey(namespace, name))
 }
 
 // ListNodesByNamespace returns nodes, optionally filtered by namespace
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -20,7 +21,7 @@ func TestStore_SetAndGetNode(t *testing.T) {
 
 	s.SetNode(node)
 
-	got, ok := s.GetNode("worker-0")
+	got, ok := s.GetNode("", "worker-0")
 	if !ok {
 		t.Fatal("expected to find node")
 	}
@@ -46,14 +47,63 @@ func TestStore_DeleteNode(t *testing.T) {
 	s := New()
 
 	s.SetNode(models.Node{Name: "worker-0"})
-	s.DeleteNode("worker-0")
+	s.DeleteNode("", "worker-0")
 
-	_, ok := s.GetNode("worker-0")
+	_, ok := s.GetNode("", "worker-0")
 	if ok {
 		t.Error("expected node to be deleted")
 	}
 }
 
+func TestStore_SameNameInNamespaces(t *testing.T) {
+	s := New()
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-a", Model: "R640"})
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-b", Model: "R650"})
+
+	if n := len(s.ListNodes()); n != 2 {
+		t.Fatalf("expected 2 nodes, got %d", n)
+	}
+	got, ok := s.GetNode("cluster-b", "master-0")
+	if !ok || got.Model != "R650" {
+		t.Errorf("GetNode(cluster-b, master-0) = %+v, %v", got, ok)
+	}
+
+	s.DeleteNode("cluster-a", "master-0")
+	if _, ok := s.GetNode("cluster-b", "master-0"); !ok {
+		t.Error("deleting cluster-a/master-0 removed cluster-b/master-0")
+	}
+}
+
+func TestStore_ResolveNode(t *testing.T) {
+	s := New()
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-a"})
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-b"})
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "cluster-a"})
+
+	tests := []struct {
+		ref       string
+		namespace string
+		err       error
+	}{
+		{"worker-0", "cluster-a", nil},
+		{"cluster-b/master-0", "cluster-b", nil},
+		{"master-0", "", ErrAmbiguousNode},
+		{"missing", "", ErrNodeNotFound},
+		{"cluster-b/worker-0", "", ErrNodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			node, err := s.ResolveNode(tt.ref)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ResolveNode(%q) error = %v, want %v", tt.ref, err, tt.err)
+			}
+			if node.Namespace != tt.namespace {
+				t.Errorf("ResolveNode(%q) namespace = %q, want %q", tt.ref, node.Namespace, tt.namespace)
+			}
+		})
+	}
+}
+
 func TestStore_ListNodesByNamespace(t *testing.T) {
 	s := New()
 	s.SetNode(models.Node{Name: "node-1", Namespace: "ns-a"})
@@ -2,6 +2,7 @@ package store
 
 import (
 	"sync"
+	"time"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
@@ -9,7 +10,7 @@ import (
 // TaskStore provides thread-safe storage for Redfish tasks
 type TaskStore struct {
 	mu    sync.RWMutex
-	tasks map[string]models.Task
+	tasks map[string]models.Task // keyed by taskKey
 }
 
 // NewTaskStore creates a new TaskStore
@@ -23,14 +24,14 @@ func NewTaskStore() *TaskStore {
 func (ts *TaskStore) SetTask(task models.Task) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
-	ts.tasks[task.TaskID] = task
+	ts.tasks[taskKey(task.Namespace, task.Node, task.TaskID)] = task
 }
 
-// GetTask retrieves a task by ID
-func (ts *TaskStore) GetTask(id string) (models.Task, bool) {
+// GetTask retrieves a node's task by ID
+func (ts *TaskStore) GetTask(namespace, node, id string) (models.Task, bool) {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
-	task, ok := ts.tasks[id]
+	task, ok := ts.tasks[taskKey(namespace, node, id)]
 	return task, ok
 }
 
@@ -48,11 +49,17 @@ func (ts *TaskStore) ListTasks(namespace string) []models.Task {
 	return result
 }
 
-// DeleteTask removes a task by ID
-func (ts *TaskStore) DeleteTask(id string) {
+// DeleteTask removes a node's task by ID
+func (ts *TaskStore) DeleteTask(namespace, node, id string) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
-	delete(ts.tasks, id)
+	delete(ts.tasks, taskKey(namespace, node, id))
+}
+
+// taskKey qualifies a task ID with its node, since BMCs number their jobs
+// independently and the same ID can exist on many hosts
+func taskKey(namespace, node, id string) string {
+	return models.NodeKey(namespace, node) + "/" + id
 }
 
 // ClearCompleted removes all completed tasks
@@ -65,3 +72,50 @@ func (ts *TaskStore) ClearCompleted() {
 		}
 	}
 }
+
+// PruneCompleted removes completed tasks that finished before the cutoff and
+// returns how many were removed
+func (ts *TaskStore) PruneCompleted(cutoff time.Time) int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	removed := 0
+	for id, task := range ts.tasks {
+		if !task.IsComplete() {
+			continue
+		}
+		finished := task.EndTime
+		if finished.IsZero() {
+			finished = task.StartTime
+		}
+		if finished.Before(cutoff) {
+			delete(ts.tasks, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Summary counts tasks by state, optionally filtered by namespace
+func (ts *TaskStore) Summary(namespace string) models.JobsSummary {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	var summary models.JobsSummary
+	for _, task := range ts.tasks {
+		if namespace != "" && task.Namespace != namespace {
+			continue
+		}
+		switch task.TaskState {
+		case models.TaskPending:
+			summary.Pending++
+		case models.TaskRunning:
+			summary.InProgress++
+		case models.TaskCompleted:
+			summary.Completed++
+		case models.TaskFailed:
+			summary.Failed++
+		}
+	}
+	return summary
+}
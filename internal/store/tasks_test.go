@@ -2,6 +2,7 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cragr/openshift-baremetal-insights/internal/models"
 )
@@ -15,7 +16,7 @@ func TestTaskStore_SetAndGet(t *testing.T) {
 	}
 	ts.SetTask(task)
 
-	got, ok := ts.GetTask("JID_123")
+	got, ok := ts.GetTask("", "node-1", "JID_123")
 	if !ok {
 		t.Fatal("GetTask returned false, expected true")
 	}
@@ -24,6 +25,20 @@ func TestTaskStore_SetAndGet(t *testing.T) {
 	}
 }
 
+func TestTaskStore_SameIDOnDifferentNodes(t *testing.T) {
+	ts := NewTaskStore()
+	ts.SetTask(models.Task{TaskID: "JID_1", Node: "master-0", Namespace: "cluster-a", TaskState: models.TaskRunning})
+	ts.SetTask(models.Task{TaskID: "JID_1", Node: "master-0", Namespace: "cluster-b", TaskState: models.TaskCompleted})
+
+	if tasks := ts.ListTasks(""); len(tasks) != 2 {
+		t.Fatalf("ListTasks() = %d tasks, want 2", len(tasks))
+	}
+	got, ok := ts.GetTask("cluster-a", "master-0", "JID_1")
+	if !ok || got.TaskState != models.TaskRunning {
+		t.Errorf("GetTask(cluster-a) = %+v, %v", got, ok)
+	}
+}
+
 func TestTaskStore_ListTasks(t *testing.T) {
 	ts := NewTaskStore()
 	ts.SetTask(models.Task{TaskID: "JID_1", Node: "node-1", Namespace: "ns-a"})
@@ -45,9 +60,9 @@ func TestTaskStore_ListTasks(t *testing.T) {
 func TestTaskStore_DeleteTask(t *testing.T) {
 	ts := NewTaskStore()
 	ts.SetTask(models.Task{TaskID: "JID_1"})
-	ts.DeleteTask("JID_1")
+	ts.DeleteTask("", "", "JID_1")
 
-	_, ok := ts.GetTask("JID_1")
+	_, ok := ts.GetTask("", "", "JID_1")
 	if ok {
 		t.Error("GetTask returned true after delete")
 	}
@@ -66,3 +81,40 @@ func TestTaskStore_ClearCompleted(t *testing.T) {
 		t.Errorf("After ClearCompleted: got %d tasks, want 1", len(tasks))
 	}
 }
+
+func TestTaskStore_PruneCompleted(t *testing.T) {
+	ts := NewTaskStore()
+	now := time.Now()
+	ts.SetTask(models.Task{TaskID: "JID_1", TaskState: models.TaskCompleted, EndTime: now.Add(-48 * time.Hour)})
+	ts.SetTask(models.Task{TaskID: "JID_2", TaskState: models.TaskCompleted, EndTime: now.Add(-time.Hour)})
+	ts.SetTask(models.Task{TaskID: "JID_3", TaskState: models.TaskRunning, StartTime: now.Add(-48 * time.Hour)})
+
+	removed := ts.PruneCompleted(now.Add(-24 * time.Hour))
+	if removed != 1 {
+		t.Errorf("PruneCompleted() removed %d, want 1", removed)
+	}
+	if _, ok := ts.GetTask("", "", "JID_1"); ok {
+		t.Error("expected JID_1 to be pruned")
+	}
+	if _, ok := ts.GetTask("", "", "JID_3"); !ok {
+		t.Error("running task should not be pruned")
+	}
+}
+
+func TestTaskStore_Summary(t *testing.T) {
+	ts := NewTaskStore()
+	ts.SetTask(models.Task{TaskID: "JID_1", Namespace: "ns-a", TaskState: models.TaskPending})
+	ts.SetTask(models.Task{TaskID: "JID_2", Namespace: "ns-a", TaskState: models.TaskRunning})
+	ts.SetTask(models.Task{TaskID: "JID_3", Namespace: "ns-b", TaskState: models.TaskCompleted})
+	ts.SetTask(models.Task{TaskID: "JID_4", Namespace: "ns-b", TaskState: models.TaskFailed})
+
+	summary := ts.Summary("")
+	if summary.Pending != 1 || summary.InProgress != 1 || summary.Completed != 1 || summary.Failed != 1 {
+		t.Errorf("Summary() = %+v", summary)
+	}
+
+	summary = ts.Summary("ns-a")
+	if summary.Completed != 0 || summary.Pending != 1 {
+		t.Errorf("Summary(ns-a) = %+v", summary)
+	}
+}
@@ -0,0 +1,190 @@
+package updates
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+)
+
+// maxTaskMisses is how many checks in a row a task may be missing from its BMC,
+// e.g. after the job queue was cleared, before it is marked failed
+const maxTaskMisses = 3
+
+// Monitor periodically refreshes tracked tasks from each BMC's TaskService
+type Monitor struct {
+	taskStore *store.TaskStore
+	redfish   *redfish.Client
+	hosts     HostResolver
+	interval  time.Duration
+	retention time.Duration
+
+	missesMu sync.Mutex
+	misses   map[string]int // checks in a row a task was missing, by node key and task ID
+
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+}
+
+// NewMonitor creates a new task Monitor. Completed tasks are pruned once they
+// have been finished for longer than retention.
+func NewMonitor(ts *store.TaskStore, redfishClient *redfish.Client, hosts HostResolver, interval, retention time.Duration) *Monitor {
+	return &Monitor{
+		taskStore: ts,
+		redfish:   redfishClient,
+		hosts:     hosts,
+		interval:  interval,
+		retention: retention,
+		misses:    make(map[string]int),
+	}
+}
+
+// Start begins the monitoring loop
+func (m *Monitor) Start(ctx context.Context) {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = true
+	m.stopCh = make(chan struct{})
+	m.mu.Unlock()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// Stop stops the monitoring loop
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		close(m.stopCh)
+		m.running = false
+	}
+}
+
+func (m *Monitor) check(ctx context.Context) {
+	if removed := m.taskStore.PruneCompleted(time.Now().Add(-m.retention)); removed > 0 {
+		log.Printf("Pruned %d completed tasks", removed)
+	}
+
+	// Group active tasks by node so each BMC is contacted once. Tasks still
+	// being submitted have no BMC task to check yet.
+	byNode := make(map[string][]models.Task)
+	for _, task := range m.taskStore.ListTasks("") {
+		if task.IsComplete() || task.BMCTaskID == "" {
+			continue
+		}
+		key := models.NodeKey(task.Namespace, task.Node)
+		byNode[key] = append(byNode[key], task)
+	}
+
+	var wg sync.WaitGroup
+	for _, tasks := range byNode {
+		wg.Add(1)
+		go func(t []models.Task) {
+			defer wg.Done()
+			m.checkNode(ctx, t)
+		}(tasks)
+	}
+	wg.Wait()
+}
+
+func (m *Monitor) checkNode(ctx context.Context, tasks []models.Task) {
+	node, namespace := tasks[0].Node, tasks[0].Namespace
+
+	host, err := m.hosts.GetHost(ctx, namespace, node)
+	if err != nil {
+		log.Printf("Error resolving BMC for %s: %v", node, err)
+		return
+	}
+
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.BMCTaskID)
+	}
+
+	statuses, err := m.redfish.GetTaskStatuses(
+		ctx,
+		host.BMC,
+		host.Credentials.Username,
+		host.Credentials.Password,
+		ids,
+	)
+	if err != nil {
+		log.Printf("Error getting task status for %s: %v", node, err)
+		return
+	}
+
+	now := time.Now()
+	for _, t := range tasks {
+		status, ok := statuses[t.BMCTaskID]
+		if !ok {
+			continue
+		}
+		if misses := m.countMiss(models.NodeKey(namespace, node)+"/"+t.TaskID, status.Missing); misses > 0 {
+			if misses < maxTaskMisses {
+				continue
+			}
+			log.Printf("Task %s on %s is no longer on the BMC, marking it failed", t.BMCTaskID, node)
+			t.TaskState = models.TaskFailed
+			t.EndTime = now
+			t.Message = "task no longer found on the BMC"
+			m.taskStore.SetTask(t)
+			continue
+		}
+		m.taskStore.SetTask(applyStatus(t, status, now))
+	}
+}
+
+// countMiss records whether a task was missing from its BMC and returns how
+// many checks in a row it has been, forgetting it once it reaches maxTaskMisses
+func (m *Monitor) countMiss(key string, missing bool) int {
+	m.missesMu.Lock()
+	defer m.missesMu.Unlock()
+
+	if !missing {
+		delete(m.misses, key)
+		return 0
+	}
+	m.misses[key]++
+	misses := m.misses[key]
+	if misses >= maxTaskMisses {
+		delete(m.misses, key)
+	}
+	return misses
+}
+
+// applyStatus merges the BMC-reported status into a tracked task
+func applyStatus(task models.Task, status redfish.TaskStatus, now time.Time) models.Task {
+	task.TaskState = status.State
+	task.PercentComplete = status.PercentComplete
+	if status.Message != "" {
+		task.Message = status.Message
+	}
+	if task.IsComplete() && task.EndTime.IsZero() {
+		task.EndTime = status.EndTime
+		if task.EndTime.IsZero() {
+			task.EndTime = now
+		}
+	}
+	return task
+}
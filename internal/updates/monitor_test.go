@@ -0,0 +1,83 @@
+package updates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+)
+
+func TestApplyStatus(t *testing.T) {
+	now := time.Now()
+	task := models.Task{TaskID: "JID_1", TaskState: models.TaskPending, Message: "scheduled"}
+
+	running := applyStatus(task, redfish.TaskStatus{State: models.TaskRunning, PercentComplete: 40}, now)
+	if running.TaskState != models.TaskRunning || running.PercentComplete != 40 {
+		t.Errorf("unexpected running task: %+v", running)
+	}
+	if running.Message != "scheduled" {
+		t.Errorf("empty status message should keep existing message, got %q", running.Message)
+	}
+	if !running.EndTime.IsZero() {
+		t.Error("running task should not have an end time")
+	}
+
+	done := applyStatus(running, redfish.TaskStatus{State: models.TaskCompleted, PercentComplete: 100, Message: "Job completed successfully."}, now)
+	if !done.IsComplete() || done.Message != "Job completed successfully." {
+		t.Errorf("unexpected completed task: %+v", done)
+	}
+	if !done.EndTime.Equal(now) {
+		t.Errorf("EndTime = %v, want %v", done.EndTime, now)
+	}
+}
+
+func TestMonitor_Check(t *testing.T) {
+	bmc := newMockBMC(t)
+	bmc.tasks["JID_1"] = `{
+		"@odata.id": "/redfish/v1/TaskService/Tasks/JID_1",
+		"Id": "JID_1",
+		"TaskState": "Completed",
+		"TaskStatus": "OK",
+		"PercentComplete": 100,
+		"Messages": [{"Message": "Job completed successfully."}]
+	}`
+
+	ts := store.NewTaskStore()
+	started := time.Now().Add(-time.Hour)
+	ts.SetTask(models.Task{Node: "worker-0", Namespace: "ns-a", TaskID: "done", BMCTaskID: "JID_1", TaskState: models.TaskPending, StartTime: started})
+	ts.SetTask(models.Task{Node: "worker-0", Namespace: "ns-a", TaskID: "gone", BMCTaskID: "JID_2", TaskState: models.TaskPending, StartTime: started})
+	ts.SetTask(models.Task{Node: "worker-0", Namespace: "ns-a", TaskID: "submitting", TaskState: models.TaskPending, StartTime: started})
+	ts.SetTask(models.Task{Node: "worker-0", Namespace: "ns-a", TaskID: "old", TaskState: models.TaskCompleted, StartTime: started, EndTime: time.Now().Add(-48 * time.Hour)})
+
+	hosts := staticHosts{"ns-a/worker-0": bmc.host("ns-a", "worker-0")}
+	m := NewMonitor(ts, redfish.NewClient(), hosts, time.Minute, 24*time.Hour)
+	ctx := context.Background()
+
+	m.check(ctx)
+	if task, _ := ts.GetTask("ns-a", "worker-0", "done"); task.TaskState != models.TaskCompleted || task.PercentComplete != 100 || task.EndTime.IsZero() {
+		t.Errorf("expected the task to complete, got %+v", task)
+	}
+	if _, ok := ts.GetTask("ns-a", "worker-0", "old"); ok {
+		t.Error("expected the task finished before the retention to be pruned")
+	}
+
+	// A task missing from the BMC is only failed after several checks
+	for i := 1; i < maxTaskMisses; i++ {
+		if task, _ := ts.GetTask("ns-a", "worker-0", "gone"); task.TaskState != models.TaskPending {
+			t.Fatalf("expected the task to stay pending after %d misses, got %+v", i, task)
+		}
+		m.check(ctx)
+	}
+	task, _ := ts.GetTask("ns-a", "worker-0", "gone")
+	if task.TaskState != models.TaskFailed || task.EndTime.IsZero() || task.Message != "task no longer found on the BMC" {
+		t.Errorf("expected the missing task to fail, got %+v", task)
+	}
+
+	// A task still being submitted is not checked
+	if task, _ := ts.GetTask("ns-a", "worker-0", "submitting"); task.TaskState != models.TaskPending {
+		t.Errorf("expected the task being submitted to be left alone, got %+v", task)
+	}
+}
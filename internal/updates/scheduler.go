@@ -0,0 +1,242 @@
+package updates
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/catalog"
+	"github.com/cragr/openshift-baremetal-insights/internal/discovery"
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+	"github.com/cragr/openshift-baremetal-insights/internal/version"
+)
+
+// ModeOnReboot stages updates so they are applied on the next server reboot
+const ModeOnReboot = "OnReboot"
+
+// TaskTypeFirmwareUpdate is the task type recorded for scheduled firmware updates
+const TaskTypeFirmwareUpdate = "FirmwareUpdate"
+
+// submitTimeout bounds how long submitting one request's updates may take
+const submitTimeout = 10 * time.Minute
+
+var (
+	// ErrInvalidRequest is returned when a schedule request fails validation
+	ErrInvalidRequest = errors.New("invalid update request")
+	// ErrNoUpdates is returned when none of the requested components have an applicable update
+	ErrNoUpdates = errors.New("no applicable firmware updates found")
+	// ErrCatalogUnavailable is returned when no firmware catalog is configured
+	ErrCatalogUnavailable = errors.New("firmware catalog not available")
+)
+
+// Request describes which node components to update and how. Nodes are given
+// as "namespace/name", or by name alone where that is unambiguous.
+type Request struct {
+	Nodes      []string `json:"nodes"`
+	Components []string `json:"components,omitempty"`
+	Mode       string   `json:"mode"`
+}
+
+// Result lists the tasks created for a schedule request. Each update is
+// submitted to its BMC in the background; its task records the BMC's task ID
+// once accepted, or the failure.
+type Result struct {
+	TaskIDs []string `json:"taskIds"`
+}
+
+// HostResolver looks up BMC connection details for a node
+type HostResolver interface {
+	GetHost(ctx context.Context, namespace, name string) (*discovery.DiscoveredHost, error)
+}
+
+// Scheduler stages firmware updates on BMCs and records the resulting tasks
+type Scheduler struct {
+	store     *store.Store
+	taskStore *store.TaskStore
+	catalog   *catalog.Service
+	redfish   *redfish.Client
+	hosts     HostResolver
+}
+
+// NewScheduler creates a new update Scheduler
+func NewScheduler(s *store.Store, ts *store.TaskStore, catalogSvc *catalog.Service, redfishClient *redfish.Client, hosts HostResolver) *Scheduler {
+	return &Scheduler{
+		store:     s,
+		taskStore: ts,
+		catalog:   catalogSvc,
+		redfish:   redfishClient,
+		hosts:     hosts,
+	}
+}
+
+// plannedUpdate pairs an installed component with the catalog package that updates it
+type plannedUpdate struct {
+	Component models.FirmwareComponent
+	Entry     models.CatalogEntry
+}
+
+// Schedule resolves the requested components against the catalog and records
+// a pending task for each update, returning their IDs. The updates are then
+// submitted as SimpleUpdates in the background, over one session per node.
+func (s *Scheduler) Schedule(ctx context.Context, req Request) (*Result, error) {
+	if len(req.Nodes) == 0 {
+		return nil, fmt.Errorf("%w: at least one node is required", ErrInvalidRequest)
+	}
+	if req.Mode == "" {
+		req.Mode = ModeOnReboot
+	}
+	if req.Mode != ModeOnReboot {
+		return nil, fmt.Errorf("%w: unsupported mode %q", ErrInvalidRequest, req.Mode)
+	}
+	if s.catalog == nil {
+		return nil, ErrCatalogUnavailable
+	}
+
+	// Validate every node before touching any BMC
+	nodes := make([]models.Node, 0, len(req.Nodes))
+	plans := make(map[string][]plannedUpdate)
+	total := 0
+	for _, ref := range req.Nodes {
+		node, err := s.store.ResolveNode(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		if _, seen := plans[node.Key()]; seen {
+			continue
+		}
+		plan := planUpdates(node, req.Components, s.catalog.Match)
+		if len(plan) == 0 {
+			continue
+		}
+		nodes = append(nodes, node)
+		plans[node.Key()] = plan
+		total += len(plan)
+	}
+	if total == 0 {
+		return nil, ErrNoUpdates
+	}
+
+	result := &Result{TaskIDs: make([]string, 0, total)}
+	tasks := make(map[string][]models.Task, len(nodes))
+	now := time.Now()
+	for _, node := range nodes {
+		for _, u := range plans[node.Key()] {
+			task := models.Task{
+				Node:      node.Name,
+				Namespace: node.Namespace,
+				TaskID:    strings.ToLower(rand.Text()),
+				TaskType:  TaskTypeFirmwareUpdate,
+				TaskState: models.TaskPending,
+				StartTime: now,
+				Message:   fmt.Sprintf("%s submitting to the BMC", describeUpdate(u)),
+			}
+			s.taskStore.SetTask(task)
+			tasks[node.Key()] = append(tasks[node.Key()], task)
+			result.TaskIDs = append(result.TaskIDs, task.TaskID)
+		}
+	}
+
+	// The request may end long before the BMCs have accepted every update
+	submitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), submitTimeout)
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(n models.Node) {
+			defer wg.Done()
+			s.submitNode(submitCtx, n, plans[n.Key()], tasks[n.Key()])
+		}(node)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
+	return result, nil
+}
+
+// submitNode submits a node's planned updates over one BMC session, recording
+// the BMC's task ID or the failure on each update's task
+func (s *Scheduler) submitNode(ctx context.Context, node models.Node, plan []plannedUpdate, tasks []models.Task) {
+	host, err := s.hosts.GetHost(ctx, node.Namespace, node.Name)
+	if err != nil {
+		log.Printf("Error resolving BMC for %s: %v", node.Key(), err)
+		s.failTasks(tasks, err)
+		return
+	}
+
+	session, err := s.redfish.Connect(ctx, host.BMC, host.Credentials.Username, host.Credentials.Password)
+	if err != nil {
+		log.Printf("Error connecting to BMC of %s: %v", node.Key(), err)
+		s.failTasks(tasks, err)
+		return
+	}
+	defer session.Close()
+
+	for i, u := range plan {
+		task := tasks[i]
+		bmcTaskID, err := session.ScheduleFirmwareUpdate(u.Entry.DownloadURL, redfish.ApplyTimeOnReset)
+		if err != nil {
+			log.Printf("Error scheduling %s update on %s: %v", u.Component.Name, node.Key(), err)
+			s.failTasks([]models.Task{task}, err)
+			continue
+		}
+
+		task.BMCTaskID = bmcTaskID
+		task.Message = fmt.Sprintf("%s scheduled, applies on next reboot", describeUpdate(u))
+		s.taskStore.SetTask(task)
+		log.Printf("Scheduled %s %s on %s as task %s", u.Component.Name, u.Entry.Version, node.Key(), bmcTaskID)
+	}
+}
+
+// failTasks records that tasks could not be submitted to the BMC
+func (s *Scheduler) failTasks(tasks []models.Task, err error) {
+	now := time.Now()
+	for _, task := range tasks {
+		task.TaskState = models.TaskFailed
+		task.EndTime = now
+		task.Message = fmt.Sprintf("failed to schedule: %v", err)
+		s.taskStore.SetTask(task)
+	}
+}
+
+// describeUpdate names an update and its versions for task messages
+func describeUpdate(u plannedUpdate) string {
+	return fmt.Sprintf("%s %s -> %s", u.Component.Name, u.Component.CurrentVersion, u.Entry.Version)
+}
+
+// planUpdates selects the node's updateable components that need an update, optionally
+// restricted to the given component IDs, and pairs each with its catalog package
+func planUpdates(node models.Node, components []string, lookup func(systemID, systemModel string, id models.DeviceIdentity) (models.CatalogEntry, bool)) []plannedUpdate {
+	wanted := make(map[string]bool, len(components))
+	for _, id := range components {
+		wanted[id] = true
+	}
+
+	var plan []plannedUpdate
+	for _, fw := range node.Firmware {
+		if len(wanted) > 0 && !wanted[fw.ID] {
+			continue
+		}
+		if !fw.Updateable || !node.NeedsUpdate(&fw) {
+			continue
+		}
+		entry, ok := lookup(node.SystemID, node.Model, fw.Identity)
+		if !ok || entry.DownloadURL == "" {
+			continue
+		}
+		// The catalog only offers the latest package, which may not be the
+		// version a baseline pins
+		if node.Baseline != "" && version.Compare(entry.Version, fw.TargetVersion) != version.Equal {
+			continue
+		}
+		plan = append(plan, plannedUpdate{Component: fw, Entry: entry})
+	}
+	return plan
+}
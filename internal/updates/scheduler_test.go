@@ -0,0 +1,270 @@
+package updates
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cragr/openshift-baremetal-insights/internal/catalog"
+	"github.com/cragr/openshift-baremetal-insights/internal/discovery"
+	"github.com/cragr/openshift-baremetal-insights/internal/models"
+	"github.com/cragr/openshift-baremetal-insights/internal/redfish"
+	"github.com/cragr/openshift-baremetal-insights/internal/store"
+)
+
+// mockBMC is a minimal Redfish service that accepts SimpleUpdates and reports tasks
+type mockBMC struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	sessions int
+	updates  []string          // ImageURIs of accepted SimpleUpdates
+	tasks    map[string]string // task resources by ID
+}
+
+func newMockBMC(t *testing.T) *mockBMC {
+	t.Helper()
+	m := &mockBMC{tasks: make(map[string]string)}
+	m.Server = httptest.NewTLSServer(http.HandlerFunc(m.serve))
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockBMC) serve(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+	case r.Method == http.MethodPost && path == "/redfish/v1/SessionService/Sessions":
+		m.sessions++
+		w.Header().Set("X-Auth-Token", "token")
+		w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPost && path == "/redfish/v1/UpdateService/Actions/UpdateService.SimpleUpdate":
+		var payload struct{ ImageURI string }
+		json.NewDecoder(r.Body).Decode(&payload)
+		m.updates = append(m.updates, payload.ImageURI)
+		w.Header().Set("Location", fmt.Sprintf("/redfish/v1/TaskService/Tasks/JID_%d", len(m.updates)))
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/redfish/v1":
+		io.WriteString(w, `{
+			"@odata.id": "/redfish/v1/",
+			"Vendor": "Dell",
+			"UpdateService": {"@odata.id": "/redfish/v1/UpdateService"},
+			"TaskService": {"@odata.id": "/redfish/v1/TaskService"},
+			"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}
+		}`)
+	case path == "/redfish/v1/UpdateService":
+		io.WriteString(w, `{"@odata.id": "/redfish/v1/UpdateService", "Id": "UpdateService"}`)
+	case strings.HasPrefix(path, "/redfish/v1/TaskService/Tasks/") && m.tasks[strings.TrimPrefix(path, "/redfish/v1/TaskService/Tasks/")] != "":
+		io.WriteString(w, m.tasks[strings.TrimPrefix(path, "/redfish/v1/TaskService/Tasks/")])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// host returns a BareMetalHost whose BMC is the mock, trusting its certificate
+func (m *mockBMC) host(namespace, name string) *discovery.DiscoveredHost {
+	addr, port, _ := net.SplitHostPort(m.Listener.Addr().String())
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: m.Certificate().Raw})
+	return &discovery.DiscoveredHost{
+		Name:        name,
+		Namespace:   namespace,
+		BMC:         models.BMCEndpoint{Scheme: "https", Host: addr, Port: port, CABundle: string(ca)},
+		Credentials: models.BMCCredentials{Username: "root", Password: "calvin"},
+	}
+}
+
+// staticHosts resolves nodes to fixed hosts, see HostResolver
+type staticHosts map[string]*discovery.DiscoveredHost
+
+func (h staticHosts) GetHost(ctx context.Context, namespace, name string) (*discovery.DiscoveredHost, error) {
+	host, ok := h[models.NodeKey(namespace, name)]
+	if !ok {
+		return nil, fmt.Errorf("host %s/%s not found", namespace, name)
+	}
+	return host, nil
+}
+
+func testLookup(systemID, systemModel string, id models.DeviceIdentity) (models.CatalogEntry, bool) {
+	if systemID == "08B4" && id.ComponentID == "159" {
+		return models.CatalogEntry{Version: "2.19.1", DownloadURL: "https://downloads.dell.com/BIOS.EXE"}, true
+	}
+	return models.CatalogEntry{}, false
+}
+
+func TestPlanUpdates(t *testing.T) {
+	node := models.Node{
+		Name:     "worker-0",
+		Model:    "PowerEdge R640",
+		SystemID: "08B4",
+		Firmware: []models.FirmwareComponent{
+			{ID: "bios", Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.18.1", AvailableVersion: "2.19.1", Updateable: true, Identity: models.DeviceIdentity{ComponentID: "159"}},
+			{ID: "cpld", Name: "CPLD", ComponentType: "CPLD", CurrentVersion: "1.0", AvailableVersion: "1.1", Updateable: true, Identity: models.DeviceIdentity{ComponentID: "27763"}},
+			{ID: "locked", Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.18.1", AvailableVersion: "2.19.1", Updateable: false, Identity: models.DeviceIdentity{ComponentID: "159"}},
+			{ID: "current", Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.19.1", AvailableVersion: "2.19.1", Updateable: true, Identity: models.DeviceIdentity{ComponentID: "159"}},
+		},
+	}
+
+	plan := planUpdates(node, nil, testLookup)
+	if len(plan) != 1 {
+		t.Fatalf("expected 1 planned update, got %d", len(plan))
+	}
+	if plan[0].Component.ID != "bios" || plan[0].Entry.DownloadURL == "" {
+		t.Errorf("unexpected plan: %+v", plan[0])
+	}
+
+	if plan := planUpdates(node, []string{"cpld"}, testLookup); len(plan) != 0 {
+		t.Errorf("expected no updates for cpld without catalog entry, got %d", len(plan))
+	}
+}
+
+func TestPlanUpdates_Baseline(t *testing.T) {
+	bios := models.FirmwareComponent{ID: "bios", Name: "BIOS", ComponentType: "BIOS", CurrentVersion: "2.17.0", AvailableVersion: "2.19.1", Updateable: true, Identity: models.DeviceIdentity{ComponentID: "159"}}
+
+	// The baseline pins 2.18.1 but the catalog only offers 2.19.1
+	pinned := bios
+	pinned.TargetVersion, pinned.Compliance = "2.18.1", models.ComplianceBehind
+	node := models.Node{Name: "worker-0", SystemID: "08B4", Baseline: "ocp-4.16", Firmware: []models.FirmwareComponent{pinned}}
+	if plan := planUpdates(node, nil, testLookup); len(plan) != 0 {
+		t.Errorf("expected no update past the baseline target, got %d", len(plan))
+	}
+
+	matching := bios
+	matching.TargetVersion, matching.Compliance = "2.19.1", models.ComplianceBehind
+	node.Firmware = []models.FirmwareComponent{matching}
+	if plan := planUpdates(node, nil, testLookup); len(plan) != 1 {
+		t.Errorf("expected update to the baseline target, got %d", len(plan))
+	}
+}
+
+func TestScheduler_Validation(t *testing.T) {
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Model: "PowerEdge R640"})
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-a", Model: "PowerEdge R640"})
+	s.SetNode(models.Node{Name: "master-0", Namespace: "cluster-b", Model: "PowerEdge R640"})
+	sched := NewScheduler(s, store.NewTaskStore(), catalog.NewService("http://example.com", time.Hour), nil, nil)
+
+	tests := []struct {
+		name string
+		req  Request
+		want error
+	}{
+		{"no nodes", Request{Mode: ModeOnReboot}, ErrInvalidRequest},
+		{"bad mode", Request{Nodes: []string{"worker-0"}, Mode: "Immediate"}, ErrInvalidRequest},
+		{"unknown node", Request{Nodes: []string{"missing"}, Mode: ModeOnReboot}, ErrInvalidRequest},
+		{"nothing to update", Request{Nodes: []string{"worker-0"}, Mode: ModeOnReboot}, ErrNoUpdates},
+		{"ambiguous node", Request{Nodes: []string{"master-0"}, Mode: ModeOnReboot}, store.ErrAmbiguousNode},
+		{"namespaced node", Request{Nodes: []string{"cluster-a/master-0"}, Mode: ModeOnReboot}, ErrNoUpdates},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sched.Schedule(context.Background(), tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Schedule() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Schedule(t *testing.T) {
+	catalogXML := `<?xml version="1.0"?>
+<Manifest version="2.0" baseLocation="downloads.dell.com">
+	<SoftwareComponent packageID="BIOS1" vendorVersion="2.19.1" path="FOLDER1/BIOS.EXE">
+		<ComponentType value="BIOS"/>
+		<SupportedSystems><Brand><Model systemID="08B4">PowerEdge R640</Model></Brand></SupportedSystems>
+		<SupportedDevices><Device componentID="159"/></SupportedDevices>
+	</SoftwareComponent>
+	<SoftwareComponent packageID="IDRAC1" vendorVersion="7.00.00.00" path="FOLDER2/iDRAC.EXE">
+		<ComponentType value="FRMW"/>
+		<SupportedSystems><Brand><Model systemID="08B4">PowerEdge R640</Model></Brand></SupportedSystems>
+		<SupportedDevices><Device componentID="25227"/></SupportedDevices>
+	</SoftwareComponent>
+</Manifest>`
+	catalogServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz := gzip.NewWriter(w)
+		io.WriteString(gz, catalogXML)
+		gz.Close()
+	}))
+	defer catalogServer.Close()
+	catalogSvc := catalog.NewService(catalogServer.URL, time.Hour)
+	if err := catalogSvc.Sync(context.Background()); err != nil {
+		t.Fatalf("catalog sync: %v", err)
+	}
+
+	firmware := []models.FirmwareComponent{
+		{ID: "bios", Name: "BIOS", CurrentVersion: "2.18.1", AvailableVersion: "2.19.1", Updateable: true, Identity: models.DeviceIdentity{ComponentID: "159"}},
+		{ID: "idrac", Name: "iDRAC", CurrentVersion: "6.10.30.00", AvailableVersion: "7.00.00.00", Updateable: true, Identity: models.DeviceIdentity{ComponentID: "25227"}},
+	}
+	s := store.New()
+	s.SetNode(models.Node{Name: "worker-0", Namespace: "ns-a", SystemID: "08B4", Firmware: firmware})
+	s.SetNode(models.Node{Name: "worker-1", Namespace: "ns-a", SystemID: "08B4", Firmware: firmware})
+
+	bmc := newMockBMC(t)
+	unreachable := &discovery.DiscoveredHost{Name: "worker-1", Namespace: "ns-a", BMC: models.BMCEndpoint{Scheme: "https", Host: "127.0.0.1", Port: "1"}}
+	hosts := staticHosts{"ns-a/worker-0": bmc.host("ns-a", "worker-0"), "ns-a/worker-1": unreachable}
+	ts := store.NewTaskStore()
+	sched := NewScheduler(s, ts, catalogSvc, redfish.NewClient(), hosts)
+
+	// Tasks are recorded before any BMC is contacted
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err := sched.Schedule(ctx, Request{Nodes: []string{"ns-a/worker-0", "ns-a/worker-1"}})
+	cancel()
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if len(result.TaskIDs) != 4 {
+		t.Fatalf("expected 4 tasks, got %v", result.TaskIDs)
+	}
+
+	// The updates are submitted after the request ended
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		pending := 0
+		for _, task := range ts.ListTasks("") {
+			if task.BMCTaskID == "" && !task.IsComplete() {
+				pending++
+			}
+		}
+		if pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("updates were not submitted: %+v", ts.ListTasks(""))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, id := range result.TaskIDs[:2] {
+		task, ok := ts.GetTask("ns-a", "worker-0", id)
+		if !ok || task.TaskState != models.TaskPending || !strings.HasPrefix(task.BMCTaskID, "JID_") || !strings.Contains(task.Message, "applies on next reboot") {
+			t.Errorf("unexpected worker-0 task %s: %+v", id, task)
+		}
+	}
+	for _, id := range result.TaskIDs[2:] {
+		task, ok := ts.GetTask("ns-a", "worker-1", id)
+		if !ok || task.TaskState != models.TaskFailed || task.EndTime.IsZero() || !strings.Contains(task.Message, "failed to schedule") {
+			t.Errorf("expected worker-1 task %s to fail, got %+v", id, task)
+		}
+	}
+
+	// Both updates went over one session
+	bmc.mu.Lock()
+	defer bmc.mu.Unlock()
+	if bmc.sessions != 1 || len(bmc.updates) != 2 || bmc.updates[0] != "https://downloads.dell.com/FOLDER1/BIOS.EXE" {
+		t.Errorf("expected both updates over one session, got %d sessions and %v", bmc.sessions, bmc.updates)
+	}
+}
@@ -0,0 +1,125 @@
+// Package version compares firmware version strings as published by Dell and
+// reported by BMCs, e.g. "2.19.1", "A05", "22.5.7", "7.00.00.00" and "1.5.6.0-X01".
+package version
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Ordering is the result of comparing two versions
+type Ordering int
+
+const (
+	// Unknown means the versions could not be meaningfully compared
+	Unknown Ordering = iota
+	Less
+	Equal
+	Greater
+)
+
+func (o Ordering) String() string {
+	switch o {
+	case Less:
+		return "less"
+	case Equal:
+		return "equal"
+	case Greater:
+		return "greater"
+	default:
+		return "unknown"
+	}
+}
+
+// segment is one dot- or dash-separated part of a version, split into an
+// optional letter prefix and a number: "A05" -> {"A", 5}, "19" -> {"", 19}
+type segment struct {
+	prefix string
+	number uint64
+}
+
+// parse splits a version into segments, returning false if any part is not of
+// the form [letters][digits]
+func parse(v string) ([]segment, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, false
+	}
+
+	parts := strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' })
+	if len(parts) == 0 {
+		return nil, false
+	}
+
+	segments := make([]segment, 0, len(parts))
+	for _, part := range parts {
+		i := strings.IndexFunc(part, unicode.IsDigit)
+		if i < 0 {
+			return nil, false
+		}
+		prefix, digits := part[:i], part[i:]
+		for _, r := range prefix {
+			if !unicode.IsLetter(r) {
+				return nil, false
+			}
+		}
+		n, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		segments = append(segments, segment{prefix: strings.ToUpper(prefix), number: n})
+	}
+	return segments, true
+}
+
+// Compare orders a relative to b. Numeric segments compare numerically, so
+// "9.10.0" is greater than "9.2.0", and missing trailing segments count as
+// zero, so "7.00.00.00" equals "7.0". Segments with different letter prefixes,
+// a suffix present on only one side, or unparseable input yield Unknown.
+func Compare(a, b string) Ordering {
+	if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b)) && strings.TrimSpace(a) != "" {
+		return Equal
+	}
+
+	sa, ok := parse(a)
+	if !ok {
+		return Unknown
+	}
+	sb, ok := parse(b)
+	if !ok {
+		return Unknown
+	}
+
+	n := max(len(sa), len(sb))
+	for i := 0; i < n; i++ {
+		var x, y segment
+		if i < len(sa) {
+			x = sa[i]
+		} else if sb[i].prefix != "" {
+			return Unknown
+		}
+		if i < len(sb) {
+			y = sb[i]
+		} else if sa[i].prefix != "" {
+			return Unknown
+		}
+
+		if x.prefix != y.prefix {
+			return Unknown
+		}
+		switch {
+		case x.number < y.number:
+			return Less
+		case x.number > y.number:
+			return Greater
+		}
+	}
+	return Equal
+}
+
+// Newer reports whether candidate is strictly newer than current. Versions
+// that cannot be compared are never considered newer.
+func Newer(candidate, current string) bool {
+	return Compare(candidate, current) == Greater
+}
@@ -0,0 +1,50 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want Ordering
+	}{
+		{"2.19.1", "2.18.1", Greater},
+		{"2.18.1", "2.19.1", Less},
+		{"2.19.1", "2.19.1", Equal},
+		{"9.10.0", "9.2.0", Greater},
+		{"22.5.7", "22.31.6", Less},
+		{"7.00.00.00", "7.0", Equal},
+		{"7.00.00.00", "6.10.80.00", Greater},
+		{"7.10.30.00", "7.10.30.00", Equal},
+		{"A05", "A04", Greater},
+		{"A05", "A10", Less},
+		{"a05", "A05", Equal},
+		{"1.5.6.0-X01", "1.5.6.0-X02", Less},
+		{"1.5.6.1-X01", "1.5.6.0-X05", Greater},
+		{"1.5.6.0-X01", "1.5.6.0", Unknown},
+		{"A05", "2.19.1", Unknown},
+		{"A05", "B05", Unknown},
+		{"", "2.19.1", Unknown},
+		{"2.19.1", "", Unknown},
+		{"1.0.4 (build 4)", "1.0.4", Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewer(t *testing.T) {
+	if !Newer("2.19.1", "2.18.1") {
+		t.Error("expected 2.19.1 to be newer than 2.18.1")
+	}
+	if Newer("2.18.1", "2.19.1") {
+		t.Error("expected downgrade not to be newer")
+	}
+	if Newer("A05", "2.19.1") {
+		t.Error("expected incomparable versions not to be newer")
+	}
+}